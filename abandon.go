@@ -0,0 +1,195 @@
+package nls
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Class categorizes Reapers so that policy (e.g. what to do when the Exit
+// context expires before the Reaper has completed) can be applied uniformly.
+// Reapers are assigned a Class at spawn time via WithClass.
+type Class int
+
+const (
+	// ClassNormal is the Class of Reapers spawned without WithClass.
+	ClassNormal Class = iota
+
+	// ClassOptional is intended for Reapers whose cleanup is nice to have
+	// but which can safely be skipped.
+	ClassOptional
+
+	// ClassCritical is intended for Reapers that must not be skipped
+	// without some form of remedial action (e.g. a flush that prevents data
+	// loss).
+	ClassCritical
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassNormal:
+		return "normal"
+	case ClassOptional:
+		return "optional"
+	case ClassCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("class(%d)", int(c))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (c Class) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// AbandonPolicy determines what happens to a Reaper that is cut off by the
+// expiry of the context passed to Scope.Exit because it was still running
+// when the context expired or, given WithAbandonSkipped, because it never got
+// the chance to run.
+type AbandonPolicy int
+
+const (
+	// Abandon gives up on the Reaper and reports an error wrapping
+	// ErrAbandoned to the Exit error handler. This is the default policy for
+	// all Classes.
+	Abandon AbandonPolicy = iota
+
+	// Retry hands the Reaper to the Janitor supplied via WithJanitor to be
+	// retried in the background. If no Janitor was supplied or the Janitor
+	// will not accept the Reaper then the Reaper is abandoned.
+	Retry
+
+	// Escalate invokes the escalation func supplied via WithEscalation
+	// which, by default, terminates the process.
+	Escalate
+)
+
+// ErrAbandoned is matched (via errors.Is) by errors reported for Reapers that
+// were abandoned during Scope.Exit.
+var ErrAbandoned = errors.New("reaper abandoned")
+
+type abandonedError struct {
+	class Class
+	err   error
+}
+
+func (e abandonedError) Error() string {
+	return fmt.Sprintf("%s %s reaper: %v", ErrAbandoned, e.class, e.err)
+}
+
+func (e abandonedError) Is(target error) bool { return target == ErrAbandoned }
+
+func (e abandonedError) Unwrap() error { return e.err }
+
+// WithAbandonPolicy yields an ExitOpt that sets the AbandonPolicy applied to
+// Reapers of the supplied Class that are cut off by the Exit context.
+func WithAbandonPolicy(c Class, p AbandonPolicy) ExitOpt {
+	return func(cfg *exitCfg) {
+		if cfg.policies == nil {
+			cfg.policies = make(map[Class]AbandonPolicy)
+		}
+		cfg.policies[c] = p
+	}
+}
+
+// WithJanitor yields an ExitOpt that supplies the Janitor to which Reapers
+// subject to the Retry AbandonPolicy (or to WithRetryFailures) are submitted.
+func WithJanitor(j *Janitor) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.janitor = j
+	}
+}
+
+// WithRetryFailures yields an ExitOpt that causes Reapers of the supplied
+// Classes that return an error during Exit to be submitted to the Janitor
+// supplied via WithJanitor rather than having their error reported to the Exit
+// error handler. The Janitor reports the Reaper's final Disposition.
+func WithRetryFailures(classes ...Class) ExitOpt {
+	return func(cfg *exitCfg) {
+		if cfg.retry == nil {
+			cfg.retry = make(map[Class]bool)
+		}
+		for _, c := range classes {
+			cfg.retry[c] = true
+		}
+	}
+}
+
+// WithEscalation yields an ExitOpt that overrides the func invoked for
+// Reapers subject to the Escalate AbandonPolicy. The default escalation
+// reports the error on stderr and exits the process with status 2.
+func WithEscalation(fn func(err error)) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.escalate = fn
+	}
+}
+
+// WithAbandonSkipped yields an ExitOpt that applies the AbandonPolicy of
+// their Class to the Reapers that are skipped because the Exit context is
+// done before they can be invoked, so that each is reported (or retried, or
+// escalated) in the same way as a Reaper cut off while running. By default
+// skipped Reapers are dropped without being reported: once the context is
+// done the remainder of the tree is marked exited without invoking them.
+func WithAbandonSkipped() ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.skipped = true
+	}
+}
+
+func escalate(err error) {
+	fmt.Fprintf(os.Stderr, "nls: escalating abandoned reaper: %v\n", err)
+	os.Exit(2)
+}
+
+// skip disposes of the supplied reaper, which was not invoked because the
+// Exit context was done, returning the error with which it was abandoned if
+// WithAbandonSkipped applies and nil otherwise.
+func (ec *exitCfg) skip(r reaper, cause error) error {
+	if ec.skipped {
+		return ec.abandon(r, cause)
+	}
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.abandoned++
+	return nil
+}
+
+// abandon applies the AbandonPolicy of the supplied reaper, returning the
+// error with which it was abandoned or nil if it was handed to the Janitor.
+func (ec *exitCfg) abandon(r reaper, cause error) error {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.abandoned++
+	err := abandonedError{r.class, cause}
+	switch ec.policies[r.class] {
+	case Retry:
+		if ec.janitor != nil && ec.janitor.Submit(r.values.bind(r.fn)) == nil {
+			return nil
+		}
+	case Escalate:
+		ec.escalate(err)
+		return err
+	}
+	ec.notify(err)
+	return err
+}
+
+// fail reports the error returned by the supplied reaper, returning it or nil
+// if the reaper was handed to the Janitor to be retried.
+func (ec *exitCfg) fail(r reaper, err error) error {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	ec.failed++
+	if ec.retry[r.class] && ec.janitor != nil && ec.janitor.Submit(r.values.bind(r.fn)) == nil {
+		return nil
+	}
+	ec.notify(err)
+	return err
+}
+
+// notify records the supplied error for the postmortem report and passes it
+// to the error handler. The caller must hold ec.mu.
+func (ec *exitCfg) notify(err error) {
+	ec.errs = append(ec.errs, err)
+	ec.onError(err)
+}
@@ -0,0 +1,111 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func expiredContext() (context.Context, context.CancelFunc) {
+	return context.WithDeadline(context.Background(), time.Now())
+}
+
+func TestAbandonSkippedQuietly(t *testing.T) {
+	s := nls.NewScope()
+	child := s.NewChildScope()
+	ran := false
+	for _, sc := range []*nls.Scope{s, child} {
+		nls.MustSpawn(context.TODO(), sc, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error { ran = true; return nil }, nil
+		})
+	}
+
+	ctx, cancel := expiredContext()
+	defer cancel()
+	var got []error
+	err := s.Exit(ctx, nls.WithErrorHandler(func(err error) {
+		got = append(got, err)
+	}))
+	require(t, err == context.DeadlineExceeded, "expected context error")
+	require(t, !ran, "expected reapers to be skipped")
+	require(t, len(got) == 0, "expected skipped reapers not to be reported, got %v", got)
+	require(t, child.Spawn(context.TODO(), nopSpawner) != nil, "expected child to be exited")
+}
+
+func TestAbandonPolicyDefault(t *testing.T) {
+	s := nls.NewScope()
+	ran := false
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { ran = true; return nil }, nil
+	}, nls.WithClass(nls.ClassOptional))
+
+	ctx, cancel := expiredContext()
+	defer cancel()
+	var got []error
+	err := s.Exit(ctx, nls.WithAbandonSkipped(), nls.WithErrorHandler(func(err error) {
+		got = append(got, err)
+	}))
+	require(t, err == context.DeadlineExceeded, "expected context error")
+	require(t, !ran, "expected reaper to be skipped")
+	require(t, len(got) == 1 && errors.Is(got[0], nls.ErrAbandoned),
+		"expected single abandoned error, got %v", got)
+	require(t, errors.Is(got[0], context.DeadlineExceeded),
+		"expected abandoned error to wrap cause")
+}
+
+func TestAbandonPolicyEscalate(t *testing.T) {
+	s := nls.NewScope()
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return nilReaper, nil
+	}, nls.WithClass(nls.ClassCritical))
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return nilReaper, nil
+	})
+
+	ctx, cancel := expiredContext()
+	defer cancel()
+	var escalated, handled int
+	s.Exit(ctx, nls.WithAbandonSkipped(),
+		nls.WithAbandonPolicy(nls.ClassCritical, nls.Escalate),
+		nls.WithEscalation(func(error) { escalated++ }),
+		nls.WithErrorHandler(func(error) { handled++ }))
+	require(t, escalated == 1, "expected one escalation, got %d", escalated)
+	require(t, handled == 1, "expected one abandoned error, got %d", handled)
+}
+
+func TestAbandonPolicyRetry(t *testing.T) {
+	root := nls.NewScope()
+	j, err := nls.NewJanitor(root, nls.WithRetryInterval(time.Millisecond))
+	require(t, err == nil, "unexpected error: %q", err)
+
+	done := make(chan struct{})
+	s := root.NewChildScope()
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			close(done)
+			return nil
+		}, nil
+	})
+
+	ctx, cancel := expiredContext()
+	defer cancel()
+	var handled int
+	s.Exit(ctx, nls.WithAbandonSkipped(),
+		nls.WithAbandonPolicy(nls.ClassNormal, nls.Retry),
+		nls.WithJanitor(j),
+		nls.WithErrorHandler(func(error) { handled++ }))
+	require(t, handled == 0, "expected reaper to be handed to janitor")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected janitor to run reaper")
+	}
+	root.Exit(context.TODO())
+}
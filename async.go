@@ -0,0 +1,62 @@
+package nls
+
+import (
+	"context"
+	"sync"
+)
+
+// WithAsyncReap yields a SpawnOpt that marks the spawned object's Reaper as
+// asynchronous: when the Scope exits, it is started on its own goroutine in
+// its turn and the rest of the Scope's Reapers proceed without waiting for
+// it, e.g. so that a slow deregistration from a remote service does not hold
+// up the closing of local resources. The Exit waits for asynchronous Reapers
+// once the Scope's other Reapers have returned, unless WithoutAsyncWait is
+// supplied. Errors are handled as for any other Reaper.
+func WithAsyncReap() SpawnOpt {
+	return func(r *reaper) {
+		r.async = true
+	}
+}
+
+// WithoutAsyncWait yields an ExitOpt under which the Exit does not wait for
+// Reapers marked with WithAsyncReap, leaving them running in the background
+// once it returns. Such Reapers receive a context carrying the Exit's values
+// but not its cancellation or deadline, their goroutines are tracked as per
+// Scope.Go (see Scope.Wait) and their errors are passed to the error handler
+// (see WithErrorHandler) whenever they occur, possibly after the Exit has
+// returned, but are not reflected in Scope.ExitErr.
+func WithoutAsyncWait() ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.forget = true
+	}
+}
+
+// reapAsync starts the supplied asynchronous reaper, the i'th of those of
+// Scope s, on a new goroutine counted by wg, calling release once it is done
+// with the supplied context.
+func (ec *exitCfg) reapAsync(ctx context.Context, s *Scope, i int, r reaper, wg *sync.WaitGroup, release func()) {
+	rctx, rescued := ec.rescue(ctx, r)
+	if !ec.admit(rctx, s, r) {
+		rescued()
+		release()
+		return
+	}
+	if ec.forget {
+		rctx = detachedCtx{context.Background(), rctx}
+	}
+	wg.Add(1)
+	s.launch(func() {
+		defer wg.Done()
+		defer release()
+		defer rescued()
+		ec.reap(rctx, s, i, r)
+	})
+}
+
+// awaitAsync waits for the asynchronous reapers counted by wg, if any, unless
+// the Exit is not to wait for them.
+func (ec *exitCfg) awaitAsync(wg *sync.WaitGroup) {
+	if wg != nil && !ec.forget {
+		wg.Wait()
+	}
+}
@@ -0,0 +1,75 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestWithAsyncReap(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		s := nls.NewScope()
+		var mu sync.Mutex
+		var order []string
+		record := func(name string) {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+		release := make(chan struct{})
+		boom := errors.New("boom")
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				record("local")
+				close(release)
+				return nil
+			}, nil
+		})
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				<-release
+				record("remote")
+				return boom
+			}, nil
+		}, nls.WithAsyncReap())
+
+		var errs []error
+		opts := []nls.ExitOpt{nls.WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		})}
+		if parallel {
+			opts = append(opts, nls.WithParallelReaping(1<<10))
+		}
+		require(t, s.Exit(context.TODO(), opts...) == nil, "unexpected exit error")
+		require(t, len(order) == 2 && order[0] == "local" && order[1] == "remote",
+			"expected async reaper not to block later reapers, got %v (parallel=%v)", order, parallel)
+		require(t, len(errs) == 1 && errors.Is(errs[0], boom), "expected async error, got %v", errs)
+		require(t, errors.Is(s.ExitErr(), boom), "expected async error in ExitErr, got %v", s.ExitErr())
+	}
+}
+
+func TestWithoutAsyncWait(t *testing.T) {
+	s := nls.NewScope(nls.WithGoroutineTracking())
+	release := make(chan struct{})
+	reaped := make(chan error, 1)
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			<-release
+			reaped <- ctx.Err()
+			return nil
+		}, nil
+	}, nls.WithAsyncReap())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	require(t, s.Exit(ctx, nls.WithoutAsyncWait()) == nil, "unexpected exit error")
+	cancel()
+	require(t, len(s.LiveGoroutines()) == 1, "expected async reaper to be tracked")
+	close(release)
+	require(t, <-reaped == nil, "expected async reaper to be detached from the exit context")
+	require(t, s.Wait(context.TODO()) == nil, "unexpected wait error")
+}
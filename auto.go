@@ -0,0 +1,92 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// ErrNoAdapter is returned from Auto when no adapter is registered for the
+// type of the supplied resource.
+var ErrNoAdapter = errors.New("no adapter registered for resource")
+
+// AdoptFunc transfers ownership of a resource to a Scope, typically by
+// spawning it with a Reaper that releases it.
+type AdoptFunc func(s Lifetime, resource interface{}) error
+
+type adapter struct {
+	typ   reflect.Type
+	adopt AdoptFunc
+}
+
+var adapters = struct {
+	sync.RWMutex
+	exact      map[reflect.Type]AdoptFunc
+	interfaces []adapter
+}{exact: make(map[reflect.Type]AdoptFunc)}
+
+// RegisterAdapter registers the AdoptFunc that Auto uses for resources of the
+// supplied type. If the type is an interface type then the adapter applies to
+// every resource implementing it for which no more specific adapter is
+// registered; interface adapters are consulted most recently registered
+// first. Registering an adapter for a type that already has one replaces it.
+// Adapter packages typically call RegisterAdapter from an init func.
+func RegisterAdapter(typ reflect.Type, adopt AdoptFunc) {
+	adapters.Lock()
+	defer adapters.Unlock()
+	if typ.Kind() != reflect.Interface {
+		adapters.exact[typ] = adopt
+		return
+	}
+	for i, a := range adapters.interfaces {
+		if a.typ == typ {
+			adapters.interfaces = append(adapters.interfaces[:i], adapters.interfaces[i+1:]...)
+			break
+		}
+	}
+	adapters.interfaces = append(adapters.interfaces, adapter{typ: typ, adopt: adopt})
+}
+
+// Auto transfers ownership of the supplied resource to the supplied Lifetime
+// using the adapter registered for the resource's type (see
+// RegisterAdapter). An adapter for io.Closer is registered by default. An
+// error wrapping ErrNoAdapter is returned if no adapter applies; otherwise the
+// adapter's error, if any, is returned, in which case ownership remains with
+// the caller.
+func Auto(s Lifetime, resource interface{}) error {
+	adopt := lookupAdapter(reflect.TypeOf(resource))
+	if adopt == nil {
+		return fmt.Errorf("%w: %T", ErrNoAdapter, resource)
+	}
+	return adopt(s, resource)
+}
+
+func lookupAdapter(typ reflect.Type) AdoptFunc {
+	if typ == nil {
+		return nil
+	}
+	adapters.RLock()
+	defer adapters.RUnlock()
+	if adopt, ok := adapters.exact[typ]; ok {
+		return adopt
+	}
+	for i := len(adapters.interfaces) - 1; i >= 0; i-- {
+		if a := adapters.interfaces[i]; typ.Implements(a.typ) {
+			return a.adopt
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterAdapter(reflect.TypeOf((*io.Closer)(nil)).Elem(),
+		func(s Lifetime, resource interface{}) error {
+			c := resource.(io.Closer)
+			return s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+				return ReaperFromFunc(c.Close), nil
+			})
+		})
+}
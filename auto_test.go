@@ -0,0 +1,53 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+type closer struct{ closed bool }
+
+func (c *closer) Close() error { c.closed = true; return nil }
+
+type stopper struct{ stopped bool }
+
+func (s *stopper) Stop() { s.stopped = true }
+
+type stopCloser struct {
+	closer
+	stopper
+}
+
+func TestAuto(t *testing.T) {
+	nls.RegisterAdapter(reflect.TypeOf((*interface{ Stop() })(nil)).Elem(),
+		func(s nls.Lifetime, r interface{}) error {
+			return s.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+				return func(context.Context) error {
+					r.(interface{ Stop() }).Stop()
+					return nil
+				}, nil
+			})
+		})
+
+	s := nls.NewScope()
+	c := &closer{}
+	st := &stopper{}
+	both := &stopCloser{}
+	require(t, nls.Auto(s, c) == nil, "expected io.Closer adapter")
+	require(t, nls.Auto(s, st) == nil, "expected registered adapter")
+	require(t, nls.Auto(s, both) == nil, "expected most recent adapter")
+
+	err := nls.Auto(s, 42)
+	require(t, errors.Is(err, nls.ErrNoAdapter), "expected ErrNoAdapter, got %q", err)
+	err = nls.Auto(s, nil)
+	require(t, errors.Is(err, nls.ErrNoAdapter), "expected ErrNoAdapter, got %q", err)
+
+	s.Exit(context.TODO())
+	require(t, c.closed && st.stopped, "expected resources to be released")
+	require(t, both.stopped && !both.closed,
+		"expected most recently registered interface adapter to win")
+}
@@ -0,0 +1,135 @@
+package nls_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+// Allocation budgets for the core operations, measured in steady state with
+// no options supplied. A child Scope costs one allocation for the Scope
+// itself (together with its trackers) and one for the func that detaches it
+// from its parent. Spawn allocates nothing beyond the Reaper returned by the
+// Spawner and amortized growth of the Scope's reaper list. Exit allocates its
+// configuration and the context passed to Reapers once per call, regardless
+// of the size of the subtree.
+const (
+	childScopeAllocs = 2
+	spawnAllocs      = 0
+	exitAllocs       = 2
+)
+
+func nopSpawner(context.Context) (nls.Reaper, error) {
+	return nopReaper, nil
+}
+
+func nopReaper(context.Context) error {
+	return nil
+}
+
+func TestAllocationBudget(t *testing.T) {
+	root := nls.NewScope()
+	defer root.Exit(context.TODO())
+
+	// warm the scope pool and the reaper list so that only steady state
+	// allocations are counted
+	root.NewChildScope().Exit(context.TODO())
+	for i := 0; i < 1000; i++ {
+		nls.MustSpawn(context.TODO(), root, nopSpawner)
+	}
+
+	var child *nls.Scope
+	allocs := testing.AllocsPerRun(100, func() {
+		child = root.NewChildScope()
+		child.Exit(context.TODO())
+	})
+	require(t, allocs <= childScopeAllocs+exitAllocs,
+		"child scope and exit: %v allocs, budget %d", allocs, childScopeAllocs+exitAllocs)
+
+	s := nls.NewScope()
+	defer s.Exit(context.TODO())
+	for i := 0; i < 1000; i++ {
+		nls.MustSpawn(context.TODO(), s, nopSpawner)
+	}
+	allocs = testing.AllocsPerRun(100, func() {
+		nls.MustSpawn(context.TODO(), s, nopSpawner)
+	})
+	require(t, allocs <= spawnAllocs, "spawn: %v allocs, budget %d", allocs, spawnAllocs)
+
+	allocs = testing.AllocsPerRun(100, func() {
+		s := nls.NewScope()
+		for i := 0; i < 4; i++ {
+			nls.MustSpawn(context.TODO(), s, nopSpawner)
+		}
+		s.Exit(context.TODO())
+	})
+	// one scope, up to three growths of its reaper list, one exit
+	require(t, allocs <= 1+3+exitAllocs, "exit: %v allocs", allocs)
+}
+
+func BenchmarkNewScope(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		nls.NewScope().Exit(context.TODO())
+	}
+}
+
+func BenchmarkSpawn(b *testing.B) {
+	s := nls.NewScope()
+	defer s.Exit(context.TODO())
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		nls.MustSpawn(context.TODO(), s, nopSpawner)
+	}
+}
+
+func BenchmarkExit(b *testing.B) {
+	for _, n := range []int{1, 16, 256} {
+		b.Run(fmt.Sprintf("reapers=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				s := nls.NewScope()
+				for j := 0; j < n; j++ {
+					nls.MustSpawn(context.TODO(), s, nopSpawner)
+				}
+				b.StartTimer()
+				s.Exit(context.TODO())
+			}
+		})
+	}
+}
+
+func BenchmarkDeepTree(b *testing.B) {
+	for _, depth := range []int{16, 256} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				root := nls.NewScope()
+				s := root
+				for j := 0; j < depth; j++ {
+					s = s.NewChildScope()
+					nls.MustSpawn(context.TODO(), s, nopSpawner)
+				}
+				root.Exit(context.TODO())
+			}
+		})
+	}
+}
+
+func BenchmarkWideTree(b *testing.B) {
+	for _, width := range []int{16, 1024} {
+		b.Run(fmt.Sprintf("width=%d", width), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				root := nls.NewScope()
+				for j := 0; j < width; j++ {
+					nls.MustSpawn(context.TODO(), root.NewChildScope(), nopSpawner)
+				}
+				root.Exit(context.TODO())
+			}
+		})
+	}
+}
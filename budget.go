@@ -0,0 +1,108 @@
+package nls
+
+import (
+	"context"
+	"time"
+)
+
+// WithDeadlineBudget yields an ExitOpt that divides the time remaining before
+// the Exit context's deadline among each exiting Scope's children and Reapers
+// in proportion to their weight, so that an early Reaper cannot consume the
+// whole budget. A Reaper weighs 1 unless declared otherwise with
+// WithReaperWeight and a child Scope weighs the total of the Reapers in its
+// subtree. Time left unused by one Reaper or child rolls over to those that
+// follow it. Children exited concurrently (see
+// WithExitConcurrencyAcrossSubtrees) or Reapers invoked in parallel (see
+// WithParallelReaping) share a single allotment. A Reaper or child that runs
+// past its share is abandoned (see AbandonPolicy) but the Exit continues. The
+// option has no effect if the Exit context has no deadline.
+func WithDeadlineBudget() ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.budget = true
+	}
+}
+
+// WithReaperWeight yields a SpawnOpt that declares the relative share of an
+// Exit deadline that the spawned object's Reaper should receive under
+// WithDeadlineBudget. Weights less than 1 are treated as 1.
+func WithReaperWeight(w int) SpawnOpt {
+	return func(r *reaper) {
+		r.weight = w
+	}
+}
+
+// budget apportions the time remaining before a context's deadline among the
+// children and reapers of a single exiting Scope.
+type budget struct {
+	clock    Clock
+	left     int   // total weight of the units not yet started
+	children []int // weight of each child subtree
+}
+
+// newBudget returns the budget for exiting the supplied children and
+// reapers, or nil if WithDeadlineBudget was not supplied.
+func (ec *exitCfg) newBudget(children []*Scope, reapers []reaper) *budget {
+	if !ec.budget {
+		return nil
+	}
+	b := &budget{clock: ec.clock, children: make([]int, len(children))}
+	for i, c := range children {
+		b.children[i] = c.weight()
+		b.left += b.children[i]
+	}
+	for _, r := range reapers {
+		b.left += r.cost()
+	}
+	return b
+}
+
+// take returns a context for the next unit of work, of the supplied weight,
+// whose deadline is the unit's share of the time remaining. A nil budget
+// returns the supplied context unchanged.
+func (b *budget) take(ctx context.Context, w int) (context.Context, context.CancelFunc) {
+	if b == nil {
+		return ctx, func() {}
+	}
+	left := b.left
+	b.left -= w
+	deadline, ok := ctx.Deadline()
+	if !ok || left <= 0 || w >= left {
+		return ctx, func() {}
+	}
+	share := float64(deadline.Sub(b.clock.Now())) * float64(w) / float64(left)
+	return ClockTimeout(ctx, b.clock, time.Duration(share))
+}
+
+// child returns the weight of the i'th child, or 0 for a nil budget.
+func (b *budget) child(i int) int {
+	if b == nil {
+		return 0
+	}
+	return b.children[i]
+}
+
+func (r reaper) cost() int {
+	if r.weight < 1 {
+		return 1
+	}
+	return r.weight
+}
+
+// weight returns the total weight of the reapers in this Scope and its
+// descendants, or 1 if there are none.
+func (s *Scope) weight() int {
+	s.mu.Lock()
+	children := s.childScopes()
+	w := 0
+	for _, r := range s.reapers {
+		w += r.cost()
+	}
+	s.mu.Unlock()
+	for _, c := range children {
+		w += c.weight()
+	}
+	if w == 0 {
+		w = 1
+	}
+	return w
+}
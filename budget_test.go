@@ -0,0 +1,71 @@
+package nls_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestDeadlineBudget(t *testing.T) {
+	s := nls.NewScope()
+	var mu sync.Mutex
+	var ran []string
+	record := func(name string) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				mu.Lock()
+				ran = append(ran, name)
+				mu.Unlock()
+				return nil
+			}, nil
+		}
+	}
+	nls.MustSpawn(context.TODO(), s, record("first"))
+	nls.MustSpawn(context.TODO(), s.NewChildScope(), record("child"))
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	var errs []error
+	err := s.Exit(ctx, nls.WithDeadlineBudget(), nls.WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	require(t, err == nil, "unexpected exit error %v", err)
+	require(t, len(errs) == 1, "expected only the hung reaper to be abandoned, got %v", errs)
+	require(t, len(ran) == 2 && ran[0] == "child" && ran[1] == "first",
+		"expected remaining reapers to run in order, got %v", ran)
+}
+
+func TestReaperWeight(t *testing.T) {
+	s := nls.NewScope()
+	shares := make(map[string]time.Duration)
+	measure := func(name string) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(ctx context.Context) error {
+				deadline, _ := ctx.Deadline()
+				shares[name] = time.Until(deadline)
+				return nil
+			}, nil
+		}
+	}
+	nls.MustSpawn(context.TODO(), s, measure("light"))
+	nls.MustSpawn(context.TODO(), s, measure("heavy"), nls.WithReaperWeight(3))
+
+	const total = time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), total)
+	defer cancel()
+	require(t, s.Exit(ctx, nls.WithDeadlineBudget()) == nil, "unexpected exit error")
+	heavy := shares["heavy"]
+	require(t, heavy > total/2 && heavy <= 3*total/4,
+		"expected heavy reaper to get three quarters of the budget, got %v", heavy)
+	require(t, shares["light"] > heavy,
+		"expected light reaper to inherit the unused budget, got %v", shares["light"])
+}
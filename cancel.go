@@ -0,0 +1,73 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"math"
+)
+
+// ErrCanceled is the reason (see ReasonFromContext) given to Reapers invoked
+// by Scope.Cancel unless another is supplied via WithReason.
+var ErrCanceled = errors.New("scope canceled")
+
+// WithForcedReaper yields a SpawnOpt that registers a second Reaper for the
+// spawned object to be invoked in place of the one returned by the Spawner
+// when the owning Scope is torn down via Scope.Cancel, e.g. one that kills a
+// process rather than asking it to stop.
+func WithForcedReaper(fn Reaper) SpawnOpt {
+	return func(r *reaper) {
+		r.forced = fn
+	}
+}
+
+// Cancel is the abrupt counterpart to Scope.Exit, akin to following SIGTERM
+// with SIGKILL. It tears down this Scope and its descendants without waiting
+// for references (see Scope.Retain) or in-flight work, exiting the subtrees
+// of its children concurrently and invoking each Scope's Reapers concurrently,
+// and invokes the Reaper registered via WithForcedReaper, where there is one,
+// in place of the graceful Reaper. The supplied context, typically with a
+// tight deadline, and ExitOpts are otherwise interpreted as by Scope.Exit;
+// ExitOpts may override the concurrency Cancel applies. Reapers see
+// ErrCanceled as the reason for the Exit unless another is supplied via
+// WithReason.
+func (s *Scope) Cancel(ctx context.Context, opts ...ExitOpt) error {
+	if s.delegated() {
+		return ErrDelegated
+	}
+	if s.reentered(ctx) {
+		return s.misuse("Cancel", ErrExiting)
+	}
+	return s.exitScope(ctx, append([]ExitOpt{forced}, opts...)...)
+}
+
+func forced(cfg *exitCfg) {
+	cfg.forced = true
+	cfg.inflight = false
+	cfg.parallel = math.MaxInt32
+	cfg.subtrees = math.MaxInt32
+	if cfg.reason == nil {
+		cfg.reason = ErrCanceled
+	}
+}
+
+// reaperFor returns the func to invoke for the supplied reaper, which is its
+// forced Reaper, if any, when the Exit was begun by Scope.Cancel. A forced
+// Reaper releases any Resource reserved for the object once it has run.
+// Either is bound to the values captured by WithSpawnValues.
+func (ec *exitCfg) reaperFor(r reaper) Reaper {
+	return r.values.bind(ec.unbound(r))
+}
+
+func (ec *exitCfg) unbound(r reaper) Reaper {
+	if !ec.forced || r.forced == nil {
+		return r.fn
+	}
+	if r.res == nil {
+		return r.forced
+	}
+	res, forced := r.res, r.forced
+	return func(ctx context.Context) error {
+		defer res.done()
+		return forced(ctx)
+	}
+}
@@ -0,0 +1,50 @@
+package nls_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestCancel(t *testing.T) {
+	s := nls.NewScope()
+	var mu sync.Mutex
+	var ran []string
+	record := func(name string) nls.Reaper {
+		return func(ctx context.Context) error {
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			defer mu.Unlock()
+			if nls.ReasonFromContext(ctx) == nls.ErrCanceled {
+				ran = append(ran, name)
+			}
+			return nil
+		}
+	}
+	for i := 0; i < 5; i++ {
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return record("graceful"), nil
+		}, nls.WithForcedReaper(record("forced")))
+	}
+	nls.MustSpawn(context.TODO(), s.NewChildScope(), func(context.Context) (nls.Reaper, error) {
+		return record("graceful"), nil
+	})
+	require(t, s.Retain() == nil, "unexpected retain error")
+
+	start := time.Now()
+	err := s.Cancel(context.TODO())
+	elapsed := time.Since(start)
+	require(t, err == nil, "unexpected error %v", err)
+	require(t, elapsed < 80*time.Millisecond, "expected reapers to run concurrently, took %v", elapsed)
+	forced := 0
+	for _, name := range ran {
+		if name == "forced" {
+			forced++
+		}
+	}
+	require(t, len(ran) == 6 && forced == 5,
+		"expected forced reapers in place of graceful ones, got %v", ran)
+}
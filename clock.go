@@ -0,0 +1,139 @@
+package nls
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock abstracts the passage of time for the time-based facilities of this
+// package (e.g. TTLs, tickers, Janitor retries and Exit timeouts) so that
+// tests can control when they fire with a simulated clock (see the nlssim
+// package). Only time is simulated: goroutines launched by Scopes, and the
+// channel operations between them, are still scheduled by the Go runtime.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// NewTimer creates a Timer that delivers the current time on its
+	// channel after at least the supplied duration.
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker creates a Ticker that delivers the current time on its
+	// channel every period.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the Clock-agnostic equivalent of *time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Ticker is the Clock-agnostic equivalent of *time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock yields a ScopeOpt that sets the Clock used by the new Scope and,
+// unless they are given their own, by all of its descendants. By default the
+// real wall clock is used.
+func WithClock(c Clock) ScopeOpt {
+	return func(s *Scope) {
+		s.clock = c
+	}
+}
+
+// RealClock is the Clock implementation backed by the time package.
+type RealClock struct{}
+
+// Now returns time.Now().
+func (RealClock) Now() time.Time { return time.Now() }
+
+// NewTimer wraps time.NewTimer.
+func (RealClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+// NewTicker wraps time.NewTicker.
+func (RealClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+type realTimer struct{ *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.Timer.C }
+
+type realTicker struct{ *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// Clock returns the Clock used by this Scope.
+func (s *Scope) Clock() Clock {
+	return s.clock
+}
+
+// ClockOf returns the Clock used by the supplied Lifetime, so that helpers
+// accepting a Lifetime can honor a simulated clock, or a RealClock if the
+// Lifetime does not expose one.
+func ClockOf(l Lifetime) Clock {
+	if c, ok := l.(interface{ Clock() Clock }); ok {
+		return c.Clock()
+	}
+	return RealClock{}
+}
+
+// ClockTimeout is as per context.WithTimeout except that the timeout, and the
+// deadline reported by the returned context, are measured by the supplied
+// Clock. Exit contexts with deadlines should be created this way when Scopes
+// use a simulated clock.
+func ClockTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := c.(RealClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+	inner, cancel := context.WithCancel(ctx)
+	cc := &clockCtx{Context: inner, deadline: c.Now().Add(d)}
+	timer := c.NewTimer(d)
+	go func() {
+		select {
+		case <-timer.C():
+			cc.mu.Lock()
+			if cc.err == nil && inner.Err() == nil {
+				cc.err = context.DeadlineExceeded
+			}
+			cc.mu.Unlock()
+			cancel()
+		case <-inner.Done():
+		}
+	}()
+	return cc, func() {
+		timer.Stop()
+		cancel()
+	}
+}
+
+// clockCtx is a context whose deadline is measured by a Clock other than the
+// wall clock.
+type clockCtx struct {
+	context.Context
+	deadline time.Time
+	mu       sync.Mutex
+	err      error
+}
+
+func (c *clockCtx) Deadline() (time.Time, bool) {
+	if d, ok := c.Context.Deadline(); ok && d.Before(c.deadline) {
+		return d, true
+	}
+	return c.deadline, true
+}
+
+func (c *clockCtx) Err() error {
+	err := c.Context.Err()
+	if err == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+	return c.err
+}
@@ -0,0 +1,88 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Sequence yields a Spawner that invokes the supplied Spawners one after the
+// other. The Reaper it returns invokes their Reapers in reverse order,
+// continuing past failures and joining their errors. Should any Spawner fail
+// then the Reapers of those that already succeeded are invoked immediately, in
+// reverse order and with a background context, and the Spawner's error is
+// returned joined with any errors from that rollback; none of the resources
+// are left behind.
+func Sequence(sps ...Spawner) Spawner {
+	return func(ctx context.Context) (Reaper, error) {
+		reapers := make([]Reaper, 0, len(sps))
+		for _, sp := range sps {
+			fn, err := sp(ctx)
+			if err != nil {
+				return nil, errors.Join(err, Reapers(reapers...)(context.Background()))
+			}
+			reapers = append(reapers, fn)
+		}
+		return Reapers(reapers...), nil
+	}
+}
+
+// Parallel yields a Spawner that invokes the supplied Spawners concurrently
+// and waits for all of them to return. The Reaper it returns likewise invokes
+// their Reapers concurrently, joining their errors. Should any Spawner fail
+// then the Reapers of those that succeeded are invoked immediately with a
+// background context and the errors of all failed Spawners are returned
+// joined with any errors from that rollback.
+func Parallel(sps ...Spawner) Spawner {
+	return func(ctx context.Context) (Reaper, error) {
+		reapers := make([]Reaper, len(sps))
+		errs := make([]error, len(sps))
+		var wg sync.WaitGroup
+		wg.Add(len(sps))
+		for i, sp := range sps {
+			go func(i int, sp Spawner) {
+				defer wg.Done()
+				reapers[i], errs[i] = sp(ctx)
+			}(i, sp)
+		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			return nil, errors.Join(err, reapConcurrent(context.Background(), reapers))
+		}
+		return func(ctx context.Context) error {
+			return reapConcurrent(ctx, reapers)
+		}, nil
+	}
+}
+
+// Conditional yields a Spawner that invokes the supplied Spawner only if pred
+// returns true when called with the spawn context, e.g. to start an optional
+// subsystem behind a feature flag as part of a Sequence. Otherwise nothing is
+// spawned and the returned Reaper does nothing.
+func Conditional(pred func(context.Context) bool, sp Spawner) Spawner {
+	return func(ctx context.Context) (Reaper, error) {
+		if !pred(ctx) {
+			return NopReaper, nil
+		}
+		return sp(ctx)
+	}
+}
+
+// reapConcurrent invokes the non-nil Reapers among those supplied
+// concurrently, joining their errors in the order the Reapers were supplied.
+func reapConcurrent(ctx context.Context, reapers []Reaper) error {
+	errs := make([]error, len(reapers))
+	var wg sync.WaitGroup
+	for i, fn := range reapers {
+		if fn == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, fn Reaper) {
+			defer wg.Done()
+			errs[i] = fn(ctx)
+		}(i, fn)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
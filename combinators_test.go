@@ -0,0 +1,105 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+type spawnLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *spawnLog) add(ev string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, ev)
+}
+
+func (l *spawnLog) count(ev string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, e := range l.events {
+		if e == ev {
+			n++
+		}
+	}
+	return n
+}
+
+func (l *spawnLog) spawner(name string, err error) nls.Spawner {
+	return func(context.Context) (nls.Reaper, error) {
+		if err != nil {
+			return nil, err
+		}
+		l.add("spawn " + name)
+		return func(context.Context) error {
+			l.add("reap " + name)
+			return nil
+		}, nil
+	}
+}
+
+func TestSequence(t *testing.T) {
+	var log spawnLog
+	s := nls.NewScope()
+	err := s.Spawn(context.TODO(), nls.Sequence(log.spawner("a", nil), log.spawner("b", nil)))
+	require(t, err == nil, "unexpected spawn error %v", err)
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	want := []string{"spawn a", "spawn b", "reap b", "reap a"}
+	require(t, len(log.events) == len(want), "expected %v, got %v", want, log.events)
+	for i := range want {
+		require(t, log.events[i] == want[i], "expected %v, got %v", want, log.events)
+	}
+}
+
+func TestSequenceRollback(t *testing.T) {
+	var log spawnLog
+	boom := errors.New("boom")
+	sp := nls.Sequence(log.spawner("a", nil), log.spawner("b", nil), log.spawner("c", boom), log.spawner("d", nil))
+	_, err := sp(context.TODO())
+	require(t, errors.Is(err, boom), "expected spawn error, got %v", err)
+	want := []string{"spawn a", "spawn b", "reap b", "reap a"}
+	require(t, len(log.events) == len(want), "expected %v, got %v", want, log.events)
+	for i := range want {
+		require(t, log.events[i] == want[i], "expected %v, got %v", want, log.events)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	var log spawnLog
+	fn, err := nls.Parallel(log.spawner("a", nil), log.spawner("b", nil))(context.TODO())
+	require(t, err == nil, "unexpected spawn error %v", err)
+	require(t, fn(context.TODO()) == nil, "unexpected reap error")
+	for _, ev := range []string{"spawn a", "spawn b", "reap a", "reap b"} {
+		require(t, log.count(ev) == 1, "expected %q once, got %v", ev, log.events)
+	}
+}
+
+func TestParallelRollback(t *testing.T) {
+	var log spawnLog
+	boom, bang := errors.New("boom"), errors.New("bang")
+	_, err := nls.Parallel(log.spawner("a", nil), log.spawner("b", boom), log.spawner("c", bang))(context.TODO())
+	require(t, errors.Is(err, boom) && errors.Is(err, bang), "expected both spawn errors, got %v", err)
+	require(t, log.count("spawn a") == 1 && log.count("reap a") == 1,
+		"expected successful spawn to be rolled back, got %v", log.events)
+}
+
+func TestConditional(t *testing.T) {
+	var log spawnLog
+	on := func(context.Context) bool { return true }
+	off := func(context.Context) bool { return false }
+	fn, err := nls.Sequence(
+		nls.Conditional(on, log.spawner("a", nil)),
+		nls.Conditional(off, log.spawner("b", nil)),
+	)(context.TODO())
+	require(t, err == nil, "unexpected spawn error %v", err)
+	require(t, fn(context.TODO()) == nil, "unexpected reap error")
+	require(t, log.count("spawn a") == 1 && log.count("reap a") == 1, "expected a, got %v", log.events)
+	require(t, log.count("spawn b") == 0 && log.count("reap b") == 0, "expected no b, got %v", log.events)
+}
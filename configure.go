@@ -0,0 +1,49 @@
+package nls
+
+import "errors"
+
+// ErrNotReconfigurable is returned from Scope.Configure when one of the
+// supplied options may only be applied when a Scope is constructed.
+var ErrNotReconfigurable = errors.New("option cannot be applied to a running scope")
+
+// Configure applies the supplied options to this already running Scope. Only
+// options that are safe to change at runtime are permitted: WithExitTimeout,
+// WithErrorChan, WithErrorPropagation, WithScopeErrorHandler, WithChildLimit
+// and WithReaperLimit. If any other option is supplied then
+// ErrNotReconfigurable is returned and no option is applied. An error is also
+// returned if this Scope has already exited. Changes take effect for
+// subsequent operations; an Exit already in progress is unaffected.
+func (s *Scope) Configure(opts ...ScopeOpt) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != active {
+		return stateError{"configure", s.state}
+	}
+
+	s.conf.Lock()
+	defer s.conf.Unlock()
+	probe := &Scope{
+		errors:      s.errors,
+		ownErrs:     s.ownErrs,
+		propagate:   s.propagate,
+		timeout:     s.timeout,
+		onErr:       s.onErr,
+		childLimit:  s.childLimit,
+		reaperLimit: s.reaperLimit,
+	}
+	for _, opt := range opts {
+		probe.reconfig = false
+		opt(probe)
+		if !probe.reconfig {
+			return ErrNotReconfigurable
+		}
+	}
+	s.errors = probe.errors
+	s.ownErrs = probe.ownErrs
+	s.propagate = probe.propagate
+	s.timeout = probe.timeout
+	s.onErr = probe.onErr
+	s.childLimit = probe.childLimit
+	s.reaperLimit = probe.reaperLimit
+	return nil
+}
@@ -0,0 +1,47 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestConfigure(t *testing.T) {
+	s := nls.NewScope()
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil
+	})
+
+	errs := make(chan error)
+	err := s.Configure(nls.WithExitTimeout(time.Millisecond), nls.WithErrorChan(errs))
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, s.Err() == errs, "expected reconfigured error channel")
+
+	err = s.Configure(nls.WithName("renamed"))
+	require(t, err == nls.ErrNotReconfigurable,
+		"expected ErrNotReconfigurable, got %q", err)
+	require(t, s.Name() == "", "expected rejected option not to be applied")
+
+	other := make(chan error)
+	for _, opt := range []nls.ScopeOpt{
+		nls.WithInheritedOpts(nls.WithExitTimeout(time.Second)),
+		func(*nls.Scope) {},
+	} {
+		err = s.Configure(nls.WithErrorChan(other), opt)
+		require(t, err == nls.ErrNotReconfigurable,
+			"expected ErrNotReconfigurable, got %q", err)
+		require(t, s.Err() == errs, "expected no option to be applied")
+	}
+
+	err = s.Exit(context.Background())
+	require(t, err == context.DeadlineExceeded,
+		"expected reconfigured exit timeout to apply")
+
+	err = s.Configure(nls.WithExitTimeout(time.Second))
+	require(t, err != nil, "expected error configuring exited scope")
+}
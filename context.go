@@ -0,0 +1,49 @@
+package nls
+
+import "context"
+
+// NewScopeWithContext instantiates a new root Scope, as per NewScope, that
+// begins to exit when the supplied context is done, so that work started on
+// behalf of a library that only accepts a context is still cleaned up. That
+// Exit is bounded by the Scope's exit timeout (see WithExitTimeout) or 30
+// seconds if the Scope has none. Errors encountered during the Exit are
+// offered on the Scope's error channel but are dropped if nothing is
+// receiving; see also Scope.ExitErr. The Scope may still be exited directly,
+// in which case the context is no longer watched.
+func NewScopeWithContext(ctx context.Context, opts ...ScopeOpt) *Scope {
+	s := NewScope(opts...)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.Done():
+			return
+		}
+		budget := s.exitTimeout()
+		if budget <= 0 {
+			budget = defaultExitBudget
+		}
+		ectx, cancel := ClockTimeout(context.Background(), s.clock, budget)
+		defer cancel()
+		if err := s.Exit(ectx, WithErrorHandler(s.OfferErr)); err != nil {
+			s.OfferErr(err)
+		}
+	}()
+	return s
+}
+
+// scopeKey is the context key under which NewContext stores a Scope.
+type scopeKey struct{}
+
+// NewContext returns a copy of the supplied context that carries the supplied
+// Scope, e.g. so that HTTP middleware can make a request Scope available to
+// handlers and the libraries they call, which retrieve it with FromContext.
+func NewContext(ctx context.Context, s *Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, s)
+}
+
+// FromContext returns the Scope carried by the supplied context (see
+// NewContext), if any.
+func FromContext(ctx context.Context) (*Scope, bool) {
+	s, ok := ctx.Value(scopeKey{}).(*Scope)
+	return s, ok && s != nil
+}
@@ -0,0 +1,47 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestNewScopeWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := nls.NewScopeWithContext(ctx)
+	reaped := make(chan struct{})
+	nls.MustSpawn(context.TODO(), s.NewChildScope(), func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				t.Error("expected the exit context to outlive the canceled context")
+			}
+			close(reaped)
+			return nil
+		}, nil
+	})
+	cancel()
+	<-reaped
+	<-s.Done()
+	require(t, s.ExitErr() == nil, "unexpected exit error %v", s.ExitErr())
+}
+
+func TestNewScopeWithContextExitedDirectly(t *testing.T) {
+	s := nls.NewScopeWithContext(context.Background())
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	<-s.Done()
+}
+
+func TestContextCarrier(t *testing.T) {
+	_, ok := nls.FromContext(context.Background())
+	require(t, !ok, "expected no scope in a plain context")
+
+	s := nls.NewScope()
+	defer s.Exit(context.TODO())
+	ctx := nls.NewContext(context.Background(), s)
+	got, ok := nls.FromContext(ctx)
+	require(t, ok && got == s, "expected the carried scope")
+
+	_, ok = nls.FromContext(nls.NewContext(ctx, nil))
+	require(t, !ok, "expected a nil scope not to be reported")
+}
@@ -0,0 +1,46 @@
+package nls
+
+import (
+	"context"
+	"time"
+)
+
+// criticalGrace bounds the fresh context given to a critical Reaper whose
+// Exit context is done before it starts.
+const criticalGrace = 5 * time.Second
+
+// WithCritical yields a SpawnOpt that marks the spawned object's Reaper as
+// critical, e.g. one that flushes buffered data that would otherwise be lost.
+// Where other Reapers are abandoned once the Exit context is done, a critical
+// Reaper still runs: it is passed a fresh context, detached from the Exit's
+// cancellation but carrying its values (e.g. the reason; see
+// ReasonFromContext), that expires after five seconds as measured by the
+// Scope's Clock. A critical Reaper that starts before the Exit context is
+// done receives that context as usual.
+func WithCritical() SpawnOpt {
+	return func(r *reaper) {
+		r.critical = true
+	}
+}
+
+// rescue returns the context with which to invoke the supplied reaper: ctx
+// itself unless the reaper is critical and ctx is already done, in which case
+// a fresh context bounded by criticalGrace.
+func (ec *exitCfg) rescue(ctx context.Context, r reaper) (context.Context, context.CancelFunc) {
+	if !r.critical || ctx.Err() == nil {
+		return ctx, func() {}
+	}
+	fresh, cancel := ClockTimeout(context.Background(), ec.clock, criticalGrace)
+	return detachedCtx{fresh, ctx}, cancel
+}
+
+// detachedCtx takes its deadline and cancellation from the embedded Context
+// and its values from another.
+type detachedCtx struct {
+	context.Context
+	values context.Context
+}
+
+func (c detachedCtx) Value(key interface{}) interface{} {
+	return c.values.Value(key)
+}
@@ -0,0 +1,43 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestWithCritical(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		s := nls.NewScope()
+		var skipped bool
+		var flushed error
+		var reason error
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(ctx context.Context) error {
+				flushed = ctx.Err()
+				reason = nls.ReasonFromContext(ctx)
+				return nil
+			}, nil
+		}, nls.WithCritical())
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				skipped = true
+				return nil
+			}, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		stopping := errors.New("stopping")
+		opts := []nls.ExitOpt{nls.WithReason(stopping), nls.WithErrorHandler(func(error) {})}
+		if parallel {
+			opts = append(opts, nls.WithParallelReaping(2))
+		}
+		s.Exit(ctx, opts...)
+		require(t, !skipped, "expected non-critical reaper to be abandoned (parallel=%v)", parallel)
+		require(t, flushed == nil, "expected critical reaper to get a live context, got %v (parallel=%v)", flushed, parallel)
+		require(t, errors.Is(reason, stopping), "expected exit values to be kept, got %v (parallel=%v)", reason, parallel)
+	}
+}
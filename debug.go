@@ -0,0 +1,128 @@
+package nls
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// reportedErr is the error most recently passed to Scope.ReportErr.
+type reportedErr struct {
+	err error
+	at  time.Time
+}
+
+// DebugHandler returns an http.Handler, in the manner of net/http/pprof, that
+// renders the live Scope tree rooted at root: the name, age and state of each
+// Scope, the number of Reapers registered with it and of goroutines it has
+// launched (see Scope.Go) that are still running if it tracks them (see
+// WithGoroutineTracking), the error most recently
+// passed to its Scope.ReportErr and the outcomes of the child exits it has
+// retained (see WithExitedChildHistory). The tree is rendered as HTML unless
+// the request has a format=json query parameter or accepts application/json,
+// in which case it is rendered as JSON. The handler exposes internal program
+// state so it should only be served to trusted clients, e.g.
+//
+//	http.Handle("/debug/nls", nls.DebugHandler(root))
+func DebugHandler(root *Scope) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		goroutines := make(map[string]int)
+		for _, g := range root.LiveGoroutines() {
+			goroutines[g.Scope]++
+		}
+		tree := root.debug(root.clock.Now(), goroutines)
+		if r.URL.Query().Get("format") == "json" ||
+			strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(tree)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		debugPage.Execute(w, tree)
+	})
+}
+
+// debugScope is the DebugHandler view of a Scope.
+type debugScope struct {
+	Name       string       `json:"name"`
+	Path       string       `json:"path"`
+	Kind       Kind         `json:"kind,omitempty"`
+	State      string       `json:"state"`
+	Created    time.Time    `json:"created"`
+	Age        string       `json:"age"`
+	Reapers    int          `json:"reapers"`
+	Goroutines int          `json:"goroutines"`
+	LastErr    string       `json:"lastError,omitempty"`
+	LastErrAt  *time.Time   `json:"lastErrorTime,omitempty"`
+	Exited     []debugExit  `json:"exited,omitempty"`
+	Children   []debugScope `json:"children,omitempty"`
+}
+
+// debugExit is the DebugHandler view of an ExitedScope.
+type debugExit struct {
+	Path     string   `json:"path"`
+	Start    string   `json:"start"`
+	Duration string   `json:"duration"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+func (s *Scope) debug(now time.Time, goroutines map[string]int) debugScope {
+	s.mu.Lock()
+	d := debugScope{
+		Name:    s.name,
+		Kind:    s.kind,
+		State:   string(s.state),
+		Created: s.created,
+		Age:     now.Sub(s.created).Round(time.Millisecond).String(),
+		Reapers: len(s.reapers),
+	}
+	children := s.childScopes()
+	s.mu.Unlock()
+	if d.State == string(active) && s.exiting.Load() {
+		d.State = "exiting"
+	}
+	d.Path = s.Path()
+	d.Goroutines = goroutines[d.Path]
+	if last := s.lastErr.Load(); last != nil {
+		d.LastErr, d.LastErrAt = last.err.Error(), &last.at
+	}
+	for _, e := range s.ExitedChildren() {
+		x := debugExit{
+			Path:     e.Snapshot.Path,
+			Start:    e.Report.Start.Format(time.RFC3339Nano),
+			Duration: e.Report.Duration.String(),
+		}
+		for _, err := range e.Report.Errors {
+			x.Errors = append(x.Errors, err.Error())
+		}
+		if e.Report.Err != nil && len(e.Report.Errors) == 0 {
+			x.Errors = append(x.Errors, e.Report.Err.Error())
+		}
+		d.Exited = append(d.Exited, x)
+	}
+	for _, c := range children {
+		d.Children = append(d.Children, c.debug(now, goroutines))
+	}
+	return d
+}
+
+var debugPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><title>nls: {{.Path}}</title></head>
+<body>
+<h1>Scope tree {{.Path}}</h1>
+<ul>{{template "scope" .}}</ul>
+</body>
+</html>
+{{define "scope"}}<li><b>{{.Path}}</b>{{with .Kind}} [{{.}}]{{end}}
+state={{.State}} age={{.Age}} reapers={{.Reapers}} goroutines={{.Goroutines}}
+{{- with .LastErr}}<br>last error: <code>{{.}}</code>{{end}}
+{{- if .Exited}}<br>exited children:<ul>
+{{- range .Exited}}<li>{{.Path}} at {{.Start}} took {{.Duration}}
+{{- range .Errors}}<br><code>{{.}}</code>{{end}}</li>{{end}}</ul>{{end}}
+{{- if .Children}}<ul>{{range .Children}}{{template "scope" .}}{{end}}</ul>{{end}}</li>
+{{end}}`))
@@ -0,0 +1,70 @@
+package nls_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestDebugHandler(t *testing.T) {
+	root := nls.NewScope(nls.WithName("app"), nls.WithExitedChildHistory(1),
+		nls.WithGoroutineTracking())
+	defer root.Exit(context.TODO())
+	db := root.NewChildScope(nls.WithName("db"))
+	nls.MustSpawn(context.TODO(), db, nopSpawner)
+	release := make(chan struct{})
+	defer close(release)
+	err := db.Go(func(context.Context) { <-release })
+	require(t, err == nil, "unexpected error: %v", err)
+	go db.ReportErr(errors.New("connection reset"))
+	<-db.Err()
+
+	old := root.NewChildScope(nls.WithName("old"))
+	nls.MustSpawn(context.TODO(), old, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return errors.New("close failed") }, nil
+	})
+	old.Exit(context.TODO())
+
+	h := nls.DebugHandler(root)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/nls?format=json", nil))
+	var tree struct {
+		Path   string
+		Exited []struct {
+			Path   string
+			Errors []string
+		}
+		Children []struct {
+			Path       string
+			State      string
+			Reapers    int
+			Goroutines int
+			LastError  string
+		}
+	}
+	err = json.NewDecoder(rec.Body).Decode(&tree)
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, tree.Path == "app" && len(tree.Children) == 1, "unexpected tree %+v", tree)
+	c := tree.Children[0]
+	require(t, c.Path == "app/db" && c.State == "active", "unexpected child %+v", c)
+	// one reaper spawned directly and one for the goroutine
+	require(t, c.Reapers == 2 && c.Goroutines == 1, "unexpected counts %+v", c)
+	require(t, c.LastError == "connection reset", "unexpected last error %q", c.LastError)
+	require(t, len(tree.Exited) == 1 && tree.Exited[0].Path == "app/old" &&
+		len(tree.Exited[0].Errors) == 1, "unexpected exited children %+v", tree.Exited)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/nls", nil))
+	body, _ := io.ReadAll(rec.Body)
+	require(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html"),
+		"expected HTML by default")
+	require(t, strings.Contains(string(body), "app/db") &&
+		strings.Contains(string(body), "connection reset"),
+		"expected scope tree in HTML, got %s", body)
+}
@@ -0,0 +1,51 @@
+package nls
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDelegated is returned from Scope.Exit (and delivered by Scope.ExitAsync)
+// for a Scope whose Exit has been delegated to an ExitOwner, and from
+// Scope.DelegateExit if the Scope has already been delegated.
+var ErrDelegated = errors.New("scope exit delegated")
+
+// ExitFunc exits the Scope to which it is bound, interpreting its arguments
+// exactly as Scope.Exit does.
+type ExitFunc func(ctx context.Context, opts ...ExitOpt) error
+
+// ExitOwner is implemented by components, such as shutdown coordinators and
+// application frameworks, that take sole responsibility for exiting a Scope.
+type ExitOwner interface {
+	// OwnExit is called once by Scope.DelegateExit with the delegated Scope
+	// and an ExitFunc that is thereafter the only way to exit it directly.
+	OwnExit(s *Scope, exit ExitFunc)
+}
+
+// DelegateExit makes the supplied ExitOwner the only party permitted to exit
+// this Scope: subsequent calls to Scope.Exit return ErrDelegated and the
+// owner is handed an ExitFunc to use instead. Exiting an ancestor of this
+// Scope still exits it as usual. An error is returned if this Scope has
+// already exited or has already been delegated.
+func (s *Scope) DelegateExit(owner ExitOwner) error {
+	s.mu.Lock()
+	if s.state != active {
+		s.mu.Unlock()
+		return stateError{"delegate", s.state}
+	}
+	if s.owner != nil {
+		s.mu.Unlock()
+		return ErrDelegated
+	}
+	s.owner = owner
+	s.mu.Unlock()
+	owner.OwnExit(s, s.exitOwned)
+	return nil
+}
+
+// delegated reports whether this Scope's Exit has been delegated.
+func (s *Scope) delegated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.owner != nil
+}
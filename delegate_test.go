@@ -0,0 +1,91 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+type owner struct {
+	scope *nls.Scope
+	exit  nls.ExitFunc
+}
+
+func (o *owner) OwnExit(s *nls.Scope, exit nls.ExitFunc) {
+	o.scope, o.exit = s, exit
+}
+
+func TestDelegateExit(t *testing.T) {
+	s := nls.NewScope()
+	reaped := false
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			reaped = true
+			return nil
+		}, nil
+	})
+
+	o := &owner{}
+	err := s.DelegateExit(o)
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, o.scope == s && o.exit != nil, "expected owner to receive exit func")
+
+	err = s.DelegateExit(&owner{})
+	require(t, errors.Is(err, nls.ErrDelegated), "expected ErrDelegated, got %v", err)
+	err = s.Exit(context.TODO())
+	require(t, errors.Is(err, nls.ErrDelegated), "expected ErrDelegated, got %v", err)
+	err = <-s.ExitAsync(context.TODO())
+	require(t, errors.Is(err, nls.ErrDelegated), "expected ErrDelegated, got %v", err)
+	require(t, !reaped, "expected scope not to have exited")
+
+	err = o.exit(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, reaped, "expected owner's exit to reap")
+
+	err = s.DelegateExit(&owner{})
+	require(t, err != nil, "expected error delegating exited scope")
+}
+
+func TestDelegatedExitHonoursExitChecks(t *testing.T) {
+	s := nls.NewScope()
+	o := &owner{}
+	require(t, s.DelegateExit(o) == nil, "unexpected delegate error")
+
+	var reentered error
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			reentered = o.exit(ctx)
+			return nil
+		}, nil
+	})
+	require(t, s.Retain() == nil, "unexpected retain error")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := o.exit(ctx)
+	require(t, errors.Is(err, context.Canceled), "expected exit to wait for references, got %v", err)
+	require(t, s.Retain() == nil, "expected scope to be active after refused exit")
+	require(t, s.ReleaseRef(context.TODO()) == nil, "unexpected release error")
+
+	require(t, s.ReleaseRef(context.TODO()) == nil, "unexpected release error")
+	require(t, o.exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, errors.Is(reentered, nls.ErrExiting), "expected reentrant exit to be refused, got %v", reentered)
+}
+
+func TestDelegatedChildExitsWithParent(t *testing.T) {
+	parent := nls.NewScope()
+	child := parent.NewChildScope()
+	reaped := false
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			reaped = true
+			return nil
+		}, nil
+	})
+	err := child.DelegateExit(&owner{})
+	require(t, err == nil, "unexpected error: %v", err)
+	err = parent.Exit(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, reaped, "expected parent exit to exit delegated child")
+}
@@ -0,0 +1,98 @@
+package nls
+
+import "sort"
+
+// WithDependsOn yields a SpawnOpt declaring that the spawned object depends
+// on the objects identified by the supplied Handles (see WithHandle), so that
+// its Reaper is invoked before theirs regardless of the order in which they
+// were spawned. This matters when objects are spawned from several goroutines
+// and registration order does not reflect their real dependencies. A Handle
+// need not yet be bound when the dependency is declared. Dependencies are
+// resolved when the Scope exits and only between objects owned by the same
+// Scope; a Scope's children always exit before any of its own Reapers are
+// invoked. Dependency cycles are broken in favor of the reverse spawn order.
+// With WithParallelReaping, Reapers are started in dependency order but a
+// Reaper does not wait for its dependents to complete.
+func WithDependsOn(handles ...*Handle) SpawnOpt {
+	return func(r *reaper) {
+		r.deps = append(r.deps, handles...)
+	}
+}
+
+// ordered returns the supplied reapers rearranged such that invoking them in
+// reverse, as Scope.Exit does, reaps every object before those on which it
+// depends (see WithDependsOn), then in order of priority (see WithPriority)
+// and otherwise in reverse spawn order. The supplied slice is returned
+// unchanged if no dependencies or priorities are declared.
+func ordered(reapers []reaper) []reaper {
+	positions := order(reapers)
+	if positions == nil {
+		return reapers
+	}
+	teardown := make([]reaper, len(positions))
+	for k, i := range positions {
+		teardown[k] = reapers[i]
+	}
+	return teardown
+}
+
+// order returns the positions of the supplied reapers in the order produced
+// by ordered, or nil if that is the order in which they were supplied.
+func order(reapers []reaper) []int {
+	deps, prio := false, false
+	for _, r := range reapers {
+		deps = deps || len(r.deps) > 0
+		prio = prio || r.priority != 0
+	}
+	if !deps && !prio {
+		return nil
+	}
+	base := make([]int, len(reapers))
+	for i := range base {
+		base[i] = i
+	}
+	if prio {
+		sort.SliceStable(base, func(a, b int) bool {
+			return reapers[base[a]].priority < reapers[base[b]].priority
+		})
+	}
+	if !deps {
+		return base
+	}
+
+	index := make(map[*Handle]int)
+	for i, r := range reapers {
+		if r.handle != nil {
+			index[r.handle] = i
+		}
+	}
+	dependents := make([][]int, len(reapers))
+	for j, r := range reapers {
+		for _, h := range r.deps {
+			if i, ok := index[h]; ok && i != j {
+				dependents[i] = append(dependents[i], j)
+			}
+		}
+	}
+
+	teardown := make([]int, 0, len(reapers))
+	visited := make([]bool, len(reapers))
+	var visit func(i int)
+	visit = func(i int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		for k := len(dependents[i]) - 1; k >= 0; k-- {
+			visit(dependents[i][k])
+		}
+		teardown = append(teardown, i)
+	}
+	for k := len(base) - 1; k >= 0; k-- {
+		visit(base[k])
+	}
+	for l, r := 0, len(teardown)-1; l < r; l, r = l+1, r-1 {
+		teardown[l], teardown[r] = teardown[r], teardown[l]
+	}
+	return teardown
+}
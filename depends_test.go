@@ -0,0 +1,35 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestDependsOn(t *testing.T) {
+	s := nls.NewScope()
+	var order []string
+	record := func(name string) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				order = append(order, name)
+				return nil
+			}, nil
+		}
+	}
+	var conn, cache nls.Handle
+	// spawned before the objects they use, e.g. from another goroutine
+	nls.MustSpawn(context.TODO(), s, record("handler"), nls.WithDependsOn(&conn, &cache))
+	nls.MustSpawn(context.TODO(), s, record("cache"), nls.WithHandle(&cache),
+		nls.WithDependsOn(&conn))
+	nls.MustSpawn(context.TODO(), s, record("conn"), nls.WithHandle(&conn))
+	nls.MustSpawn(context.TODO(), s, record("metrics"))
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	want := []string{"metrics", "handler", "cache", "conn"}
+	require(t, len(order) == len(want), "unexpected order %v", order)
+	for i := range want {
+		require(t, order[i] == want[i], "expected %v, got %v", want, order)
+	}
+}
@@ -0,0 +1,161 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrDraining is matched (via errors.Is) by errors returned when work is
+// refused because its Scope is draining (see Scope.Drain).
+var ErrDraining = errors.New("scope is draining")
+
+// WithDrainListener yields a SpawnOpt that registers a func to be notified
+// when the owning Scope (or one of its ancestors) begins draining via
+// Scope.Drain. Listeners typically stop the spawned object from accepting new
+// work, e.g. by closing a listening socket.
+func WithDrainListener(fn func(context.Context)) SpawnOpt {
+	return func(r *reaper) {
+		r.onDrain = fn
+	}
+}
+
+// Acquire registers the start of a unit of in-flight work with this Scope.
+// Every successful call must be paired with a call to Scope.Release when the
+// work completes. An error is returned, and the work should be rejected, if
+// the Scope is draining (matching ErrDraining), exiting (matching ErrExiting)
+// or has exited (matching ErrScopeDone).
+func (s *Scope) Acquire() error {
+	if s.draining.Load() || s.exiting.Load() {
+		return s.refuseWork()
+	}
+	s.inflight.add(1)
+	if s.draining.Load() || s.exiting.Load() {
+		s.inflight.add(-1)
+		return s.refuseWork()
+	}
+	return nil
+}
+
+// refuseWork yields the error with which Acquire turns work away.
+func (s *Scope) refuseWork() error {
+	if !s.exiting.Load() {
+		return s.annotate(ErrDraining)
+	}
+	s.mu.Lock()
+	st := s.state
+	s.mu.Unlock()
+	if st == active {
+		st = closing // an ancestor's Exit has marked s but not yet reached it
+	}
+	return s.annotate(stateError{"acquire work in", st})
+}
+
+// Release marks the completion of a unit of work previously registered with
+// Scope.Acquire.
+func (s *Scope) Release() {
+	s.inflight.add(-1)
+}
+
+// Token represents a single unit of in-flight work registered with a Scope.
+// It is an alternative to pairing calls to Scope.Acquire and Scope.Release
+// that is safe against double release.
+type Token struct {
+	s    *Scope
+	once sync.Once
+}
+
+// AcquireToken registers the start of a unit of in-flight work with this Scope
+// as per Scope.Acquire, returning a Token through which the work is released.
+func (s *Scope) AcquireToken() (*Token, error) {
+	if err := s.Acquire(); err != nil {
+		return nil, err
+	}
+	return &Token{s: s}, nil
+}
+
+// Release marks the completion of the unit of work represented by this Token.
+// Calls after the first have no effect.
+func (t *Token) Release() {
+	t.once.Do(t.s.Release)
+}
+
+// DrainWaiter registers an external in-flight tracker, such as a
+// sync.WaitGroup or a custom counter, with the supplied Scope so that it
+// participates in two stage shutdown without being rewritten in terms of
+// Scope.Acquire. The supplied func must block until the tracked work has
+// completed or the context is done, returning the context's error in the
+// latter case. It is waited upon by Scope.Drain (after Acquire-based work),
+// by Scope.Exit when WithInflightWait is supplied and, as a Reaper, in its
+// turn during Exit so that resources spawned before it outlive the tracked
+// work. An error is returned if the Scope has already exited.
+func DrainWaiter(s Lifetime, wait func(context.Context) error) error {
+	return s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		return Reaper(wait), nil
+	}, func(r *reaper) {
+		r.onWait = wait
+	})
+}
+
+// WithInflightWait yields an ExitOpt that causes Scope.Exit to wait, subject
+// to its context, for all in-flight work registered with the exiting subtree
+// (via Scope.Acquire, Scope.AcquireToken or DrainWaiter) to complete before
+// invoking any Reapers. New work is rejected as soon as the Exit begins.
+func WithInflightWait() ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.inflight = true
+	}
+}
+
+// Drain is the first stage of a two stage shutdown. It marks this Scope and
+// its descendants as draining (causing subsequent calls to Scope.Acquire to
+// fail), notifies all registered drain listeners in the same order in which
+// Scope.Exit would invoke Reapers, and then waits for all in-flight work in
+// the subtree to be released and for every DrainWaiter in the subtree to
+// return. If the supplied context is done before the in-flight work
+// completes, the context's error is returned. Drain does not invoke any
+// Reapers; call Scope.Exit afterwards to complete the shutdown.
+func (s *Scope) Drain(ctx context.Context) error {
+	s.notifyDrain(ctx)
+	if err := s.inflight.wait(ctx); err != nil {
+		return err
+	}
+	return s.waitExternal(ctx)
+}
+
+// waitExternal invokes the DrainWaiters registered with this Scope and its
+// descendants in the same order in which Scope.Exit would invoke Reapers,
+// returning the first error.
+func (s *Scope) waitExternal(ctx context.Context) error {
+	s.mu.Lock()
+	children := s.childScopes()
+	waiters := append([](func(context.Context) error)(nil), s.waiters...)
+	s.mu.Unlock()
+	for i := len(children) - 1; i >= 0; i-- {
+		if err := children[i].waitExternal(ctx); err != nil {
+			return err
+		}
+	}
+	for i := len(waiters) - 1; i >= 0; i-- {
+		if err := waiters[i](ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scope) notifyDrain(ctx context.Context) {
+	if s.draining.Swap(true) {
+		return
+	}
+	s.mu.Lock()
+	children := s.childScopes()
+	drainers := append([](func(context.Context))(nil), s.drainers...)
+	s.mu.Unlock()
+	for i := len(children) - 1; i >= 0; i-- {
+		children[i].notifyDrain(ctx)
+	}
+	for i := len(drainers) - 1; i >= 0; i-- {
+		drainers[i](ctx)
+	}
+}
@@ -0,0 +1,107 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestDrain(t *testing.T) {
+	root := nls.NewScope()
+	req := root.NewChildScope()
+	var order []string
+	nls.MustSpawn(context.TODO(), root,
+		func(context.Context) (nls.Reaper, error) { return nilReaper, nil },
+		nls.WithDrainListener(func(context.Context) {
+			order = append(order, "root")
+		}))
+	nls.MustSpawn(context.TODO(), req,
+		func(context.Context) (nls.Reaper, error) { return nilReaper, nil },
+		nls.WithDrainListener(func(context.Context) {
+			order = append(order, "req")
+		}))
+
+	err := req.Acquire()
+	require(t, err == nil, "unexpected error: %q", err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = root.Drain(ctx)
+	require(t, err == context.DeadlineExceeded,
+		"expected Drain to wait for in-flight work, got %q", err)
+	require(t, len(order) == 2 && order[0] == "req" && order[1] == "root",
+		"unexpected drain listener order %v", order)
+	err = req.Acquire()
+	require(t, errors.Is(err, nls.ErrDraining), "expected Acquire to fail while draining, got %v", err)
+	require(t, root.Healthy(context.TODO()) != nil,
+		"expected draining scope to be unhealthy")
+
+	req.Release()
+	err = root.Drain(context.TODO())
+	require(t, err == nil, "unexpected error from Scope.Drain: %q", err)
+	require(t, len(order) == 2, "expected drain listeners to be notified once")
+
+	err = root.Exit(context.TODO())
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+}
+
+func TestExitWaitsForInflightWork(t *testing.T) {
+	s := nls.NewScope()
+	tok, err := s.AcquireToken()
+	require(t, err == nil, "unexpected error: %q", err)
+
+	released := false
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			require(t, released, "expected reaper to run after release")
+			return nil
+		}, nil
+	})
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		released = true
+		tok.Release()
+		tok.Release()
+	}()
+	err = s.Exit(context.TODO(), nls.WithInflightWait())
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	_, err = s.AcquireToken()
+	require(t, err != nil, "expected AcquireToken to fail on exited scope")
+}
+
+func TestDrainWaiter(t *testing.T) {
+	s := nls.NewScope()
+	var wg sync.WaitGroup
+	wait := func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	err := nls.DrainWaiter(s.NewChildScope(), wait)
+	require(t, err == nil, "unexpected error: %v", err)
+
+	wg.Add(1)
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	err = s.Drain(ctx)
+	require(t, err == context.DeadlineExceeded, "expected drain to wait for WaitGroup, got %v", err)
+
+	wg.Done()
+	err = s.Drain(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+	err = s.Exit(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+}
@@ -0,0 +1,83 @@
+package nls
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Encoder writes lifecycle events in a machine-parseable form. Encoders are
+// invoked synchronously, possibly concurrently and possibly while a Scope's
+// internal lock is held, so they must be safe for concurrent use and must not
+// call back into the Scope.
+type Encoder interface {
+	Encode(ev OwnershipEvent) error
+}
+
+// WithEventEncoder yields a ScopeOpt that causes every OwnershipEvent recorded
+// by the new Scope and, unless they are given their own, its descendants to
+// be written with the supplied Encoder. Errors returned by the Encoder are
+// discarded; wrap the underlying io.Writer to observe them.
+func WithEventEncoder(enc Encoder) ScopeOpt {
+	return func(s *Scope) {
+		s.encoder = enc
+	}
+}
+
+// JSONLines returns an Encoder that writes each event to w as a single line
+// of JSON with the fields time, op, scope and, when set, peer and resource.
+func JSONLines(w io.Writer) Encoder {
+	return &jsonLines{enc: json.NewEncoder(w)}
+}
+
+// Logfmt returns an Encoder that writes each event to w as a single line of
+// logfmt key=value pairs using the same keys as JSONLines.
+func Logfmt(w io.Writer) Encoder {
+	return &logfmt{w: w}
+}
+
+type jsonLines struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (e *jsonLines) Encode(ev OwnershipEvent) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(ev)
+}
+
+type logfmt struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (e *logfmt) Encode(ev OwnershipEvent) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%s op=%s scope=%s",
+		ev.Time.Format(time.RFC3339Nano), logfmtValue(string(ev.Op)), logfmtValue(ev.Scope))
+	if ev.Peer != "" {
+		fmt.Fprintf(&b, " peer=%s", logfmtValue(ev.Peer))
+	}
+	if ev.Resource != 0 {
+		fmt.Fprintf(&b, " resource=%d", ev.Resource)
+	}
+	b.WriteByte('\n')
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+// logfmtValue quotes v if it is empty or contains characters that would
+// otherwise make the record ambiguous.
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\\\t\n") {
+		return strconv.Quote(v)
+	}
+	return v
+}
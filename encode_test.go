@@ -0,0 +1,54 @@
+package nls_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestJSONLinesEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	s := nls.NewScope(nls.WithName("root"), nls.WithEventEncoder(nls.JSONLines(&buf)))
+	child := s.NewChildScope(nls.WithName("child"))
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	})
+	s.Exit(context.TODO())
+
+	var ops []nls.OwnershipOp
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var ev nls.OwnershipEvent
+		err := json.Unmarshal([]byte(line), &ev)
+		require(t, err == nil, "unexpected error decoding %q: %v", line, err)
+		ops = append(ops, ev.Op)
+	}
+	want := []nls.OwnershipOp{
+		nls.OwnershipAttach, nls.OwnershipAcquire, nls.OwnershipRelease,
+		nls.OwnershipRelease,
+	}
+	require(t, len(ops) == len(want), "expected %v, got %v", want, ops)
+	for i := range want {
+		require(t, ops[i] == want[i], "expected %v, got %v", want, ops)
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := nls.NewScope(nls.WithName("root"), nls.WithClock(clock),
+		nls.WithEventEncoder(nls.Logfmt(&buf)))
+	s.NewChildScope(nls.WithName("my child"))
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	})
+
+	want := "time=2020-01-01T00:00:00Z op=attach scope=root peer=\"root/my child\"\n" +
+		"time=2020-01-01T00:00:00Z op=acquire scope=root resource=1\n"
+	require(t, buf.String() == want, "expected\n%s\ngot\n%s", want, buf.String())
+}
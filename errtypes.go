@@ -0,0 +1,76 @@
+package nls
+
+import "errors"
+
+// ReapError wraps an error returned by a Reaper during Scope.Exit, recording
+// where the Reaper was registered. Its message is that of the wrapped error,
+// the Scope path being added when the error is returned from a child Scope's
+// Exit, so that errors.As is the way to recover these details.
+type ReapError struct {
+	ScopePath string // Path of the Scope that held the Reaper
+	Label     string // first label assigned by WithLabel, if any
+	Err       error
+}
+
+func (e *ReapError) Error() string { return e.Err.Error() }
+
+func (e *ReapError) Unwrap() error { return e.Err }
+
+// SpawnError wraps an error returned by a Spawner invoked by Scope.Spawn or
+// Transaction.Commit. As for ReapError its message is that of the wrapped
+// error.
+type SpawnError struct {
+	ScopePath string // Path of the Scope being spawned into
+	Err       error
+}
+
+func (e *SpawnError) Error() string { return e.Err.Error() }
+
+func (e *SpawnError) Unwrap() error { return e.Err }
+
+// reapError wraps a non-nil error returned by the supplied reaper of Scope s.
+func (s *Scope) reapError(r reaper, err error) error {
+	if err == nil {
+		return nil
+	}
+	re := &ReapError{ScopePath: s.Path(), Err: err}
+	if len(r.labels) > 0 {
+		re.Label = r.labels[0]
+	}
+	return re
+}
+
+// WithErrorFilter yields an ExitOpt that treats an error returned by a Reaper
+// as success unless keep returns true for it: such an error is not passed to
+// the error handler, is not returned from Scope.Exit and is not counted as a
+// failure, and the Reaper is neither retried nor abandoned on its account.
+// Several filters may be supplied, in which case an error is kept only if all
+// of them keep it.
+func WithErrorFilter(keep func(err error) bool) ExitOpt {
+	return func(cfg *exitCfg) {
+		if prev := cfg.keep; prev != nil {
+			cfg.keep = func(err error) bool { return prev(err) && keep(err) }
+			return
+		}
+		cfg.keep = keep
+	}
+}
+
+// WithIgnoredErrors yields an ExitOpt that filters out, as per
+// WithErrorFilter, Reaper errors that match any of the supplied targets via
+// errors.Is, e.g. net.ErrClosed from a listener that was already closed.
+func WithIgnoredErrors(targets ...error) ExitOpt {
+	return WithErrorFilter(func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// ignored reports whether the supplied Reaper error is filtered out.
+func (ec *exitCfg) ignored(err error) bool {
+	return ec.keep != nil && !ec.keep(err)
+}
@@ -0,0 +1,84 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestReapError(t *testing.T) {
+	boom := errors.New("boom")
+	root := nls.NewScope()
+	defer root.Exit(context.TODO())
+	s := root.NewChildScope(nls.WithName("svc"))
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return boom }, nil
+	}, nls.WithLabel("db"), nls.WithLabel("primary"))
+
+	var errs []error
+	s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require(t, len(errs) == 1, "expected one error, got %v", errs)
+	var re *nls.ReapError
+	require(t, errors.As(errs[0], &re), "expected a *ReapError, got %#v", errs[0])
+	require(t, re.ScopePath == s.Path() && re.Label == "db" && re.Err == boom,
+		"unexpected error details %+v", re)
+	require(t, errors.Is(errs[0], boom) && errs[0].Error() == "boom",
+		"expected the reaper's error to be preserved, got %v", errs[0])
+}
+
+func TestSpawnError(t *testing.T) {
+	boom := errors.New("boom")
+	s := nls.NewScope(nls.WithName("svc"))
+	defer s.Exit(context.TODO())
+	err := s.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+		return nil, boom
+	})
+	var se *nls.SpawnError
+	require(t, errors.As(err, &se) && se.ScopePath == "svc" && se.Err == boom,
+		"expected a *SpawnError, got %#v", err)
+
+	s.Exit(context.TODO())
+	err = s.Spawn(context.TODO(), nopSpawner)
+	require(t, err != nil && !errors.As(err, &se),
+		"expected an exited scope's refusal not to be a *SpawnError, got %#v", err)
+}
+
+func TestTimeoutError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	release := make(chan struct{})
+	defer close(release)
+	err := nls.WithTimeoutReaper(func(context.Context) error {
+		<-release
+		return nil
+	}, time.Hour)(ctx)
+	var te *nls.TimeoutError
+	require(t, errors.As(err, &te) && errors.Is(err, nls.ErrReaperTimeout) &&
+		te.Err == context.Canceled, "expected a *TimeoutError, got %#v", err)
+}
+
+func TestIgnoredErrors(t *testing.T) {
+	boom := errors.New("boom")
+	root := nls.NewScope(nls.WithExitedChildHistory(1))
+	defer root.Exit(context.TODO())
+	s := root.NewChildScope()
+	for _, err := range []error{net.ErrClosed, boom, context.Canceled} {
+		err := err
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error { return err }, nil
+		})
+	}
+
+	var errs []error
+	s.Exit(context.TODO(),
+		nls.WithIgnoredErrors(net.ErrClosed),
+		nls.WithErrorFilter(func(err error) bool { return !errors.Is(err, context.Canceled) }),
+		nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require(t, len(errs) == 1 && errors.Is(errs[0], boom), "expected only boom, got %v", errs)
+	rep := root.ExitedChildren()[0].Report
+	require(t, rep.Invoked == 3 && rep.Failed == 1, "unexpected report %+v", rep)
+}
@@ -74,33 +74,19 @@ func (svc *Service) ListenAndServe(ready chan<- struct{}) error {
 }
 
 func spawnRequestWatchdog(ctx context.Context, s *nls.Scope, d time.Duration) error {
-	t := time.NewTicker(time.Duration(d))
-	stop := make(chan struct{})
-	done := make(chan struct{})
-	return s.Spawn(ctx, func(context.Context) (nls.Reaper, error) {
+	err := s.Spawn(ctx, func(context.Context) (nls.Reaper, error) {
 		fmt.Println("launching request watchdog")
-		go func() {
-			defer close(done)
-			for {
-				select {
-				case <-stop:
-					fmt.Println("request interrupted, cleaning up watchdog")
-					return
-				case <-t.C:
-					fmt.Println("watchdog check")
-				}
-			}
-		}()
-
 		return func(ctx context.Context) error {
-			close(stop)
-			select {
-			case <-done:
-			case <-ctx.Done():
-			}
+			fmt.Println("request interrupted, cleaning up watchdog")
 			return nil
 		}, nil
 	})
+	if err != nil {
+		return err
+	}
+	return nls.Tick(s, d, func(context.Context, time.Time) {
+		fmt.Println("watchdog check")
+	})
 }
 
 func Example() {
@@ -115,7 +101,8 @@ func Example() {
 
 	mainwait(1*time.Second, mainscope.Err())
 
-	exitctx, _ := context.WithTimeout(context.Background(), time.Duration(3*time.Second))
+	exitctx, cancel := context.WithTimeout(context.Background(), time.Duration(3*time.Second))
+	defer cancel()
 	err := mainscope.Exit(exitctx)
 	if err != nil {
 		panic(err)
@@ -0,0 +1,198 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrResourceLimit is matched (via errors.Is) by errors returned when
+// acquiring an OS resource would exceed a limit set with WithResourceLimit.
+var ErrResourceLimit = errors.New("resource limit exceeded")
+
+// Resource identifies a kind of OS-level resource counted against a Scope's
+// limits. Callers may define their own Resources (e.g. "inotify") alongside
+// those used by this package and its subpackages.
+type Resource string
+
+const (
+	// ResourceFile counts open files, e.g. those created by TempFile.
+	ResourceFile Resource = "file"
+
+	// ResourceSocket counts listeners and connections, e.g. those adopted
+	// via package nlsnet.
+	ResourceSocket Resource = "socket"
+
+	// ResourceProcess counts child processes, e.g. those started via
+	// package nlsexec.
+	ResourceProcess Resource = "process"
+)
+
+// guard counts the Resources held by a Scope and, transitively, by all of its
+// ancestors, enforcing any limits configured at each level.
+type guard struct {
+	mu     sync.Mutex
+	parent *guard
+	scope  *Scope
+	counts map[Resource]int
+}
+
+// WithResourceLimit yields a ScopeOpt that caps the number of Resources of
+// the supplied kind that may be held at once by the new Scope and its
+// descendants. Spawns that would exceed the cap fail with an error matching
+// ErrResourceLimit.
+func WithResourceLimit(r Resource, max int) ScopeOpt {
+	return func(s *Scope) {
+		if s.limits == nil {
+			s.limits = make(map[Resource]int)
+		}
+		s.limits[r] = max
+	}
+}
+
+// WithResource yields a SpawnOpt declaring that the spawned object holds one
+// Resource of the supplied kind until its Reaper has run. The Resource is
+// reserved (see Scope.Reserve) before the Spawner is invoked so that a Spawn
+// which would exceed a limit fails without acquiring anything.
+func WithResource(r Resource) SpawnOpt {
+	return func(rp *reaper) {
+		rp.resource = r
+	}
+}
+
+// Reserve counts one Resource of the supplied kind against this Scope and its
+// ancestors and returns a func that releases it. An error matching
+// ErrResourceLimit is returned, and nothing is reserved, if doing so would
+// exceed the limit of any of those Scopes. The release func is idempotent.
+func (s *Scope) Reserve(r Resource) (release func(), err error) {
+	var held []*guard
+	defer func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].mu.Unlock()
+		}
+	}()
+	for g := s.guard; g != nil; g = g.parent {
+		g.mu.Lock()
+		held = append(held, g)
+		if max, ok := g.scope.limits[r]; ok && g.counts[r] >= max {
+			return nil, fmt.Errorf("%w: %d %s held by scope %s",
+				ErrResourceLimit, max, r, g.scope.Path())
+		}
+	}
+	for _, g := range held {
+		g.bump(r, 1)
+	}
+	var once sync.Once
+	return func() { once.Do(func() { s.guard.release(r) }) }, nil
+}
+
+// ResourceCount returns the number of Resources of the supplied kind
+// currently held by this Scope and its descendants.
+func (s *Scope) ResourceCount(r Resource) int {
+	s.guard.mu.Lock()
+	defer s.guard.mu.Unlock()
+	return s.guard.counts[r]
+}
+
+// bump adjusts this guard's count of the supplied Resource, allocating its
+// counts on first use. The caller must hold g.mu.
+func (g *guard) bump(r Resource, delta int) {
+	if g.counts == nil {
+		g.counts = make(map[Resource]int)
+	}
+	g.counts[r] += delta
+}
+
+func (g *guard) release(r Resource) {
+	g.add(r, -1)
+}
+
+func (g *guard) add(r Resource, delta int) {
+	for g != nil {
+		g.mu.Lock()
+		g.bump(r, delta)
+		next := g.parent
+		g.mu.Unlock()
+		g = next
+	}
+}
+
+// move reattaches this guard beneath the supplied parent, transferring its
+// counts from its former ancestors to its new ones. An error matching
+// ErrResourceLimit is returned, and nothing is moved, if the counts would
+// exceed a limit of any new ancestor.
+func (g *guard) move(parent *guard) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var held []*guard
+	defer func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].mu.Unlock()
+		}
+	}()
+	for p := parent; p != nil; p = p.parent {
+		p.mu.Lock()
+		held = append(held, p)
+		for r, n := range g.counts {
+			if max, ok := p.scope.limits[r]; ok && p.counts[r]+n > max {
+				return fmt.Errorf("%w: %d %s held by scope %s",
+					ErrResourceLimit, max, r, p.scope.Path())
+			}
+		}
+	}
+	for i := len(held) - 1; i >= 0; i-- {
+		for r, n := range g.counts {
+			held[i].bump(r, n)
+		}
+		held[i].mu.Unlock()
+	}
+	held = nil
+	for r, n := range g.counts {
+		g.parent.add(r, -n)
+	}
+	g.parent = parent
+	return nil
+}
+
+// reservation holds the release func for a Resource reserved on behalf of a
+// spawned object. The release func is replaced if the object is transferred
+// to another Scope (see Scope.Transfer).
+type reservation struct {
+	mu      sync.Mutex
+	release func()
+}
+
+func (r *reservation) swap(release func()) {
+	r.mu.Lock()
+	old := r.release
+	r.release = release
+	r.mu.Unlock()
+	old()
+}
+
+func (r *reservation) done() {
+	r.swap(func() {})
+}
+
+// reserve wraps the supplied Spawner so that the Resource declared via
+// WithResource is reserved before it runs and released once its Reaper has
+// run (or immediately, if it fails).
+func (s *Scope) reserve(r Resource, res *reservation, sp Spawner) Spawner {
+	return func(ctx context.Context) (Reaper, error) {
+		release, err := s.Reserve(r)
+		if err != nil {
+			return nil, err
+		}
+		fn, err := sp(ctx)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		res.release = release
+		return func(ctx context.Context) error {
+			defer res.done()
+			return fn(ctx)
+		}, nil
+	}
+}
@@ -0,0 +1,53 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestResourceLimit(t *testing.T) {
+	parent := nls.NewScope(nls.WithName("parent"),
+		nls.WithResourceLimit(nls.ResourceFile, 2))
+	child := parent.NewChildScope(nls.WithName("child"))
+
+	dir := t.TempDir()
+	_, err := nls.TempFile(child, dir, "a")
+	require(t, err == nil, "unexpected error: %v", err)
+	_, err = nls.TempFile(parent, dir, "b")
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, parent.ResourceCount(nls.ResourceFile) == 2, "expected 2 files in subtree")
+	require(t, child.ResourceCount(nls.ResourceFile) == 1, "expected 1 file in child")
+
+	invoked := false
+	err = child.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+		invoked = true
+		return func(context.Context) error { return nil }, nil
+	}, nls.WithResource(nls.ResourceFile))
+	require(t, errors.Is(err, nls.ErrResourceLimit), "expected ErrResourceLimit, got %v", err)
+	require(t, !invoked, "expected spawner not to run")
+
+	err = child.Exit(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, parent.ResourceCount(nls.ResourceFile) == 1, "expected child file released")
+
+	release, err := parent.Reserve("inotify")
+	require(t, err == nil, "unexpected error: %v", err)
+	release()
+	release()
+	require(t, parent.ResourceCount("inotify") == 0, "expected idempotent release")
+	parent.Exit(context.TODO())
+	require(t, parent.ResourceCount(nls.ResourceFile) == 0, "expected all files released")
+}
+
+func TestResourceReleasedOnSpawnFailure(t *testing.T) {
+	s := nls.NewScope(nls.WithResourceLimit(nls.ResourceSocket, 1))
+	boom := errors.New("boom")
+	err := s.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+		return nil, boom
+	}, nls.WithResource(nls.ResourceSocket))
+	require(t, errors.Is(err, boom), "expected spawner error, got %v", err)
+	require(t, s.ResourceCount(nls.ResourceSocket) == 0, "expected reservation released")
+}
@@ -0,0 +1,217 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrUnknownHandle is returned when a Handle is used with a Scope that does
+// not currently own the resource it identifies.
+var ErrUnknownHandle = errors.New("handle not owned by scope")
+
+var errHandleInUse = errors.New("handle already bound to a spawned resource")
+
+// Handle identifies a single resource spawned with WithHandle so that it can
+// be manipulated after the fact, e.g. transferred to another Scope with
+// Scope.Transfer. The zero value is ready for use; a Handle may be bound to
+// only one spawned resource.
+type Handle struct {
+	mu    sync.Mutex
+	scope *Scope
+}
+
+// WithHandle yields a SpawnOpt that binds the supplied Handle to the spawned
+// resource. Spawn returns an error if the Handle is already bound.
+func WithHandle(h *Handle) SpawnOpt {
+	return func(r *reaper) {
+		r.handle = h
+	}
+}
+
+// Scope returns the Scope that currently owns the resource identified by this
+// Handle, or nil if the Handle has not been bound.
+func (h *Handle) Scope() *Scope {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.scope
+}
+
+func (h *Handle) bind(s *Scope) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.scope = s
+}
+
+// Transfer moves the resource identified by the supplied Handle from this
+// Scope to dest, e.g. to promote a connection created in a request Scope to a
+// longer-lived pool Scope. Its Reaper, health check and drain listener all
+// move with it and, as the most recent acquisition of dest, it is reaped
+// before anything dest already owned. Any Resource reserved for it (see
+// WithResource) is counted against dest instead and the transfer fails with
+// an error matching ErrResourceLimit if dest has no room. ErrUnknownHandle is
+// returned if this Scope does not own the resource and an error is returned
+// if either Scope has exited.
+func (s *Scope) Transfer(h *Handle, dest *Scope) error {
+	if dest == s {
+		return nil
+	}
+	s.mu.Lock()
+	if s.state != active {
+		s.mu.Unlock()
+		return stateError{"transfer from", s.state}
+	}
+	i := s.handleIndex(h)
+	if i < 0 {
+		s.mu.Unlock()
+		return ErrUnknownHandle
+	}
+	r := s.reapers[i]
+	release := func() {}
+	if r.res != nil {
+		var err error
+		if release, err = dest.Reserve(r.resource); err != nil {
+			s.mu.Unlock()
+			return err
+		}
+	}
+	s.remove(i)
+	s.recordOwnership(OwnershipTransfer, dest, 0)
+	s.mu.Unlock()
+
+	dest.mu.Lock()
+	if dest.state != active {
+		err := stateError{"transfer to", dest.state}
+		dest.mu.Unlock()
+		release()
+		return errors.Join(err, s.restore(r, i))
+	}
+	dest.adopt(r)
+	dest.recordOwnership(OwnershipTransfer, s, len(dest.reapers))
+	dest.mu.Unlock()
+	if r.res != nil {
+		r.res.swap(release)
+	}
+	return nil
+}
+
+// Replace swaps the resource identified by the supplied Handle for a new one
+// created by the supplied Spawner, e.g. to reload a listener or certificate.
+// The replacement is spawned first and, once it has been registered in the
+// old resource's place (and so in its position in the teardown order), the
+// old resource is reaped with the supplied context and its Reaper's error
+// returned. The replacement inherits the old resource's Class, labels,
+// dependencies, priority, weight and Resource, but not the health check,
+// drain listener, DrainWaiter, subscriber or forced Reaper registered for it,
+// which are specific to the old object. Values selected by WithSpawnValues
+// are captured afresh from the supplied context. If the Spawner fails then
+// the old resource is left in place and the error returned. ErrUnknownHandle
+// is returned if this Scope does not own the resource and an error is
+// returned if this Scope has exited.
+func (s *Scope) Replace(ctx context.Context, h *Handle, sp Spawner) error {
+	s.mu.Lock()
+	if s.state != active {
+		s.mu.Unlock()
+		return stateError{"replace in", s.state}
+	}
+	i := s.handleIndex(h)
+	if i < 0 {
+		s.mu.Unlock()
+		return ErrUnknownHandle
+	}
+	next := s.reapers[i]
+	s.mu.Unlock()
+
+	next.check, next.onDrain, next.onWait, next.onEvent = nil, nil, nil, nil
+	next.forced = nil
+	if len(s.interceptors) > 0 {
+		sp = s.intercept(sp)
+	}
+	if next.resource != "" {
+		next.res = &reservation{}
+		sp = s.reserve(next.resource, next.res, sp)
+	}
+	if next.values != nil {
+		next.values = next.values.capture(ctx)
+	}
+	fn, err := sp(ctx)
+	if err != nil {
+		return err
+	}
+	next.fn = fn
+
+	s.mu.Lock()
+	if s.state != active {
+		err := stateError{"replace in", s.state}
+		s.mu.Unlock()
+		return errors.Join(err, fn(context.Background()))
+	}
+	if i = s.handleIndex(h); i < 0 {
+		// transferred away while the replacement was spawned
+		s.mu.Unlock()
+		return errors.Join(ErrUnknownHandle, fn(context.Background()))
+	}
+	old := s.reapers[i]
+	reapers := append([]reaper(nil), s.reapers...)
+	reapers[i] = next
+	s.readopt(reapers)
+	s.mu.Unlock()
+	return old.values.bind(old.fn)(ctx)
+}
+
+// handleIndex returns the position in s.reapers of the reaper bound to the
+// supplied Handle, or -1. The caller must hold s.mu.
+func (s *Scope) handleIndex(h *Handle) int {
+	if h == nil {
+		return -1
+	}
+	for i, r := range s.reapers {
+		if r.handle == h {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove deletes the reaper at the supplied position, along with the hooks
+// registered with it. The caller must hold s.mu.
+func (s *Scope) remove(i int) {
+	reapers := append([]reaper(nil), s.reapers[:i]...)
+	s.readopt(append(reapers, s.reapers[i+1:]...))
+}
+
+// readopt replaces this Scope's reapers, and the hooks registered with them,
+// with the supplied reapers. The caller must hold s.mu.
+func (s *Scope) readopt(reapers []reaper) {
+	if s.stats != nil {
+		s.stats.reapersPending.Add(-int64(len(s.reapers)))
+	}
+	s.reapers = make([]reaper, 0, len(reapers))
+	s.checks = nil
+	s.drainers = nil
+	s.waiters = nil
+	s.subscribers = nil
+	for _, r := range reapers {
+		s.adopt(r)
+	}
+}
+
+// restore returns a reaper whose transfer failed to its original position in
+// this Scope or, if this Scope has since exited, runs it immediately so that
+// the resource is not leaked, returning its error.
+func (s *Scope) restore(r reaper, i int) error {
+	s.mu.Lock()
+	if s.state == active {
+		if i > len(s.reapers) {
+			i = len(s.reapers)
+		}
+		reapers := append([]reaper(nil), s.reapers[:i]...)
+		reapers = append(reapers, r)
+		reapers = append(reapers, s.reapers[i:]...)
+		s.readopt(reapers)
+		s.mu.Unlock()
+		return nil
+	}
+	s.mu.Unlock()
+	return r.fn(context.Background())
+}
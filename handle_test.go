@@ -0,0 +1,124 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestTransfer(t *testing.T) {
+	pool := nls.NewScope(nls.WithName("pool"), nls.WithOwnershipHistory(10))
+	req := pool.NewChildScope(nls.WithName("request"))
+
+	var order []string
+	spawn := func(s *nls.Scope, name string, opts ...nls.SpawnOpt) {
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				order = append(order, name)
+				return nil
+			}, nil
+		}, opts...)
+	}
+	var h nls.Handle
+	spawn(pool, "pooled")
+	spawn(req, "conn", nls.WithHandle(&h), nls.WithResource(nls.ResourceSocket))
+	spawn(req, "buffer")
+	require(t, h.Scope() == req, "expected handle bound to request scope")
+
+	err := req.Transfer(&h, pool)
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, h.Scope() == pool, "expected handle bound to pool scope")
+	require(t, req.ResourceCount(nls.ResourceSocket) == 0, "expected socket moved out of request")
+	require(t, pool.ResourceCount(nls.ResourceSocket) == 1, "expected socket counted by pool")
+
+	err = req.Transfer(&h, pool)
+	require(t, errors.Is(err, nls.ErrUnknownHandle), "expected ErrUnknownHandle, got %v", err)
+
+	err = req.Exit(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, len(order) == 1 && order[0] == "buffer", "expected only buffer reaped, got %v", order)
+
+	err = pool.Exit(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, len(order) == 3 && order[1] == "conn" && order[2] == "pooled",
+		"expected transferred conn reaped before pooled, got %v", order)
+	require(t, pool.ResourceCount(nls.ResourceSocket) == 0, "expected socket released")
+
+	var transfers int
+	for _, ev := range pool.OwnershipHistory() {
+		if ev.Op == nls.OwnershipTransfer && ev.Peer == "pool/request" {
+			transfers++
+		}
+	}
+	require(t, transfers == 1, "expected transfer to be recorded")
+}
+
+func TestTransferFailures(t *testing.T) {
+	src := nls.NewScope()
+	full := nls.NewScope(nls.WithResourceLimit(nls.ResourceFile, 0))
+	var h nls.Handle
+	reaped := false
+	nls.MustSpawn(context.TODO(), src, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			reaped = true
+			return nil
+		}, nil
+	}, nls.WithHandle(&h), nls.WithResource(nls.ResourceFile))
+
+	err := src.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	}, nls.WithHandle(&h))
+	require(t, err != nil, "expected error reusing handle")
+
+	err = src.Transfer(&h, full)
+	require(t, errors.Is(err, nls.ErrResourceLimit), "expected ErrResourceLimit, got %v", err)
+	require(t, h.Scope() == src, "expected handle to remain with source")
+
+	done := nls.NewScope()
+	done.Exit(context.TODO())
+	err = src.Transfer(&h, done)
+	require(t, err != nil, "expected error transferring to exited scope")
+	require(t, h.Scope() == src && !reaped, "expected resource restored to source")
+	require(t, src.ResourceCount(nls.ResourceFile) == 1, "expected reservation retained")
+
+	src.Exit(context.TODO())
+	require(t, reaped, "expected resource reaped by source")
+}
+
+func TestReplace(t *testing.T) {
+	s := nls.NewScope(nls.WithResourceLimit(nls.ResourceSocket, 2))
+	var order []string
+	listener := func(name string) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			order = append(order, "open "+name)
+			return func(context.Context) error {
+				order = append(order, "close "+name)
+				return nil
+			}, nil
+		}
+	}
+	var h nls.Handle
+	nls.MustSpawn(context.TODO(), s, listener(":8080"), nls.WithHandle(&h),
+		nls.WithResource(nls.ResourceSocket))
+	nls.MustSpawn(context.TODO(), s, listener("admin"))
+
+	require(t, s.Replace(context.TODO(), &h, listener(":9090")) == nil, "unexpected replace error")
+	require(t, s.ResourceCount(nls.ResourceSocket) == 1, "expected old socket to be released")
+	failed := errors.New("bind failed")
+	err := s.Replace(context.TODO(), &h, func(context.Context) (nls.Reaper, error) {
+		return nil, failed
+	})
+	require(t, err == failed, "expected spawner error, got %v", err)
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	want := []string{"open :8080", "open admin", "open :9090", "close :8080",
+		"close admin", "close :9090"}
+	require(t, len(order) == len(want), "unexpected order %v", order)
+	for i := range want {
+		require(t, order[i] == want[i], "expected %v, got %v", want, order)
+	}
+	require(t, s.Replace(context.TODO(), &h, listener("late")) != nil,
+		"expected replace in exited scope to fail")
+}
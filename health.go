@@ -0,0 +1,69 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type healthCheck struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// WithHealthCheck yields a SpawnOpt that registers a health check func for the
+// spawned object under the supplied name. The check participates in
+// Scope.Healthy until the owning Scope exits.
+func WithHealthCheck(name string, check func(context.Context) error) SpawnOpt {
+	return func(r *reaper) {
+		r.check = &healthCheck{name: name, fn: check}
+	}
+}
+
+// Healthy runs the health checks registered with this Scope and all of its
+// descendants, returning an error that joins every failed check (each wrapped
+// with the name under which it was registered). A Scope that is draining,
+// exiting, or has exited is always unhealthy.
+func (s *Scope) Healthy(ctx context.Context) error {
+	if s.exiting.Load() {
+		return s.annotate(ErrExiting)
+	}
+	if s.draining.Load() {
+		return s.annotate(ErrDraining)
+	}
+	s.mu.Lock()
+	checks := append([]healthCheck(nil), s.checks...)
+	children := s.childScopes()
+	s.mu.Unlock()
+
+	var errs []error
+	for _, c := range checks {
+		if err := c.fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.name, err))
+		}
+	}
+	for _, child := range children {
+		if err := child.Healthy(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// markExiting flags this Scope and all of its descendants as unhealthy.
+func (s *Scope) markExiting() {
+	s.exiting.Store(true)
+	s.mu.Lock()
+	children := s.childScopes()
+	s.mu.Unlock()
+	for _, child := range children {
+		child.markExiting()
+	}
+}
+
+func (s *Scope) annotate(err error) error {
+	if s.name == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", s.name, err)
+}
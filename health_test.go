@@ -0,0 +1,42 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestHealthySubtree(t *testing.T) {
+	root := nls.NewScope()
+	child := root.NewChildScope(nls.WithName("child"))
+	var dbErr error
+	nls.MustSpawn(context.TODO(), child,
+		func(context.Context) (nls.Reaper, error) { return nilReaper, nil },
+		nls.WithHealthCheck("db", func(context.Context) error { return dbErr }))
+
+	err := root.Healthy(context.TODO())
+	require(t, err == nil, "unexpected health error: %q", err)
+
+	dbErr = errors.New(t.Name())
+	err = root.Healthy(context.TODO())
+	require(t, errors.Is(err, dbErr), "expected check error, got %q", err)
+	require(t, err.Error() == "db: "+dbErr.Error(), "unexpected error %q", err)
+}
+
+func TestUnhealthyDuringExit(t *testing.T) {
+	root := nls.NewScope()
+	child := root.NewChildScope()
+	var during error
+	nls.MustSpawn(context.TODO(), root, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			during = child.Healthy(ctx)
+			return nil
+		}, nil
+	})
+	root.Exit(context.TODO())
+	require(t, during != nil, "expected subtree unhealthy during Exit")
+	require(t, root.Healthy(context.TODO()) != nil,
+		"expected exited scope to be unhealthy")
+}
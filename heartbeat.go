@@ -0,0 +1,87 @@
+package nls
+
+import (
+	"context"
+	"time"
+)
+
+// Beat is a single heartbeat emitted by a Scope-managed heartbeat emitter.
+type Beat struct {
+	// Seq numbers the heartbeats emitted by an emitter, starting at 1.
+	Seq uint64
+
+	// Time is the time at which the heartbeat was emitted.
+	Time time.Time
+
+	// Stopped is set only on the final heartbeat, emitted when the owning
+	// Scope exits.
+	Stopped bool
+}
+
+// Heartbeat spawns an emitter into the supplied Lifetime that invokes emit
+// with a Beat every interval, as measured by its Clock, for as long as it
+// remains active. When the Scope
+// exits, the emitter is stopped and emit is invoked one final time with a Beat
+// whose Stopped field is set. Should the Exit context be done before a call to
+// emit in progress returns, the Exit proceeds without waiting and the final
+// Beat follows once that call returns. Calls to emit are never concurrent. An
+// error is returned if the Scope has already exited.
+func Heartbeat(s Lifetime, interval time.Duration, emit func(Beat)) error {
+	clock := ClockOf(s)
+	return s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			var seq uint64
+			t := clock.NewTicker(interval)
+			defer t.Stop()
+			for {
+				select {
+				case <-stop:
+					emit(Beat{Seq: seq + 1, Time: clock.Now(), Stopped: true})
+					return
+				case now := <-t.C():
+					seq++
+					emit(Beat{Seq: seq, Time: now})
+				}
+			}
+		}()
+		return func(ctx context.Context) error {
+			close(stop)
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, nil
+	})
+}
+
+// HeartbeatChan is like Heartbeat but delivers heartbeats on the returned
+// channel, which is closed after the final Beat. Heartbeats are dropped
+// rather than delayed if the receiver is not keeping up, however the final
+// Beat is always delivered.
+func HeartbeatChan(s Lifetime, interval time.Duration) (<-chan Beat, error) {
+	beats := make(chan Beat, 1)
+	err := Heartbeat(s, interval, func(b Beat) {
+		if b.Stopped {
+			select {
+			case <-beats:
+			default:
+			}
+			beats <- b
+			close(beats)
+			return
+		}
+		select {
+		case beats <- b:
+		default:
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return beats, nil
+}
@@ -0,0 +1,84 @@
+package nls_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestHeartbeat(t *testing.T) {
+	s := nls.NewScope()
+	var mu sync.Mutex
+	var beats []nls.Beat
+	first := make(chan struct{})
+	err := nls.Heartbeat(s, time.Millisecond, func(b nls.Beat) {
+		mu.Lock()
+		defer mu.Unlock()
+		beats = append(beats, b)
+		if len(beats) == 1 {
+			close(first)
+		}
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+	<-first
+	s.Exit(context.TODO())
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, b := range beats {
+		require(t, b.Seq == uint64(i+1), "unexpected sequence in %+v", b)
+		require(t, b.Stopped == (i == len(beats)-1),
+			"expected only final beat to be stopped: %+v", b)
+	}
+}
+
+func TestHeartbeatExitTimeout(t *testing.T) {
+	s := nls.NewScope()
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	final := make(chan nls.Beat, 1)
+	err := nls.Heartbeat(s, time.Millisecond, func(b nls.Beat) {
+		if b.Stopped {
+			final <- b
+			return
+		}
+		if b.Seq == 1 {
+			close(blocked)
+			<-release
+		}
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+	<-blocked
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = s.Exit(ctx)
+	require(t, err == context.DeadlineExceeded, "expected exit to time out, got %v", err)
+	close(release)
+	select {
+	case b := <-final:
+		require(t, b.Stopped, "expected final beat to be stopped: %+v", b)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected final beat after the exit timed out")
+	}
+}
+
+func TestHeartbeatChan(t *testing.T) {
+	s := nls.NewScope()
+	beats, err := nls.HeartbeatChan(s, time.Millisecond)
+	require(t, err == nil, "unexpected error: %q", err)
+	<-beats
+	s.Exit(context.TODO())
+
+	var last nls.Beat
+	for b := range beats {
+		last = b
+	}
+	require(t, last.Stopped, "expected final beat to be stopped: %+v", last)
+
+	_, err = nls.HeartbeatChan(s, time.Millisecond)
+	require(t, err != nil, "expected error on exited scope")
+}
@@ -0,0 +1,56 @@
+package nls
+
+import "context"
+
+// interceptor holds the wrappers registered with WithInterceptor.
+type interceptor struct {
+	spawn func(next Spawner) Spawner
+	reap  func(next Reaper) Reaper
+}
+
+// WithInterceptor yields a ScopeOpt that wraps every Spawner passed to
+// Scope.Spawn or Scope.Replace on the new Scope and its descendants with the
+// supplied spawn func, and every Reaper so spawned (including any forced
+// Reaper, see WithForcedReaper) with the supplied reap func, e.g. to add
+// timing, logging or fault injection uniformly. Either func may be nil.
+// Interceptors accumulate with those inherited from ancestors; the first
+// registered, starting with the root's, is outermost. Reapers are wrapped
+// when they are spawned so a Reaper transferred to another Scope keeps the
+// interceptors of the Scope that spawned it.
+func WithInterceptor(spawn func(next Spawner) Spawner, reap func(next Reaper) Reaper) ScopeOpt {
+	return func(s *Scope) {
+		ics := s.interceptors[:len(s.interceptors):len(s.interceptors)]
+		s.interceptors = append(ics, interceptor{spawn, reap})
+	}
+}
+
+// intercept returns the supplied Spawner wrapped by this Scope's
+// interceptors, the Reaper it returns being wrapped likewise.
+func (s *Scope) intercept(sp Spawner) Spawner {
+	ics := s.interceptors
+	for i := len(ics) - 1; i >= 0; i-- {
+		if ics[i].spawn != nil {
+			sp = ics[i].spawn(sp)
+		}
+	}
+	inner := sp
+	return func(ctx context.Context) (Reaper, error) {
+		fn, err := inner(ctx)
+		if err != nil || fn == nil {
+			return fn, err
+		}
+		return s.interceptReaper(fn), nil
+	}
+}
+
+// interceptReaper returns the supplied Reaper wrapped by this Scope's
+// interceptors.
+func (s *Scope) interceptReaper(fn Reaper) Reaper {
+	ics := s.interceptors
+	for i := len(ics) - 1; i >= 0; i-- {
+		if ics[i].reap != nil {
+			fn = ics[i].reap(fn)
+		}
+	}
+	return fn
+}
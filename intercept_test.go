@@ -0,0 +1,54 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestInterceptor(t *testing.T) {
+	var calls []string
+	tag := func(name string) nls.ScopeOpt {
+		return nls.WithInterceptor(
+			func(next nls.Spawner) nls.Spawner {
+				return func(ctx context.Context) (nls.Reaper, error) {
+					calls = append(calls, "spawn "+name)
+					return next(ctx)
+				}
+			},
+			func(next nls.Reaper) nls.Reaper {
+				return func(ctx context.Context) error {
+					calls = append(calls, "reap "+name)
+					return next(ctx)
+				}
+			})
+	}
+	root := nls.NewScope(tag("outer"))
+	child := root.NewChildScope(tag("inner"))
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		calls = append(calls, "spawner")
+		return func(context.Context) error {
+			calls = append(calls, "reaper")
+			return nil
+		}, nil
+	})
+	root.Exit(context.TODO())
+
+	want := []string{"spawn outer", "spawn inner", "spawner", "reap outer", "reap inner", "reaper"}
+	require(t, fmt.Sprint(calls) == fmt.Sprint(want),
+		"unexpected calls\n got: %q\nwant: %q", calls, want)
+}
+
+func TestInterceptorInjectsFailure(t *testing.T) {
+	chaos := errors.New("chaos")
+	s := nls.NewScope(nls.WithInterceptor(func(nls.Spawner) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) { return nil, chaos }
+	}, nil))
+	defer s.Exit(context.TODO())
+	err := s.Spawn(context.TODO(), nopSpawner)
+	require(t, errors.Is(err, chaos), "expected injected spawn failure, got %v", err)
+	require(t, s.Snapshot().Reapers == 0, "expected no reaper registered")
+}
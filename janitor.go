@@ -0,0 +1,313 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrJanitorStopped is returned from Janitor.Submit once the Scope that owns
+// the Janitor has exited.
+var ErrJanitorStopped = errors.New("janitor stopped")
+
+type janitorTask struct {
+	r       Reaper
+	attempt int
+	due     time.Time
+	err     error
+}
+
+// Outcome describes how a Janitor finally disposed of a submitted Reaper.
+type Outcome int
+
+const (
+	// Reaped indicates that the Reaper eventually completed without error.
+	Reaped Outcome = iota
+
+	// GaveUp indicates that every permitted attempt to run the Reaper failed.
+	GaveUp
+
+	// Dropped indicates that the Janitor stopped before the Reaper could be
+	// run to completion.
+	Dropped
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Reaped:
+		return "reaped"
+	case GaveUp:
+		return "gave up"
+	case Dropped:
+		return "dropped"
+	}
+	return fmt.Sprintf("outcome(%d)", int(o))
+}
+
+// Disposition records the final fate of a Reaper submitted to a Janitor.
+type Disposition struct {
+	Outcome  Outcome
+	Attempts int
+	Err      error
+}
+
+// JanitorStats holds cumulative counters describing a Janitor's activity.
+type JanitorStats struct {
+	Submitted int
+	Reaped    int
+	GaveUp    int
+	Dropped   int
+	Pending   int
+}
+
+// Janitor is a long-lived worker, owned by a Scope, that retries Reapers in the
+// background. Reapers are typically handed to a Janitor by Scope.Exit when
+// they could not be run to completion within the Exit context's deadline (see
+// the Retry AbandonPolicy) or when they failed (see WithRetryFailures).
+type Janitor struct {
+	mu       sync.Mutex
+	clock    Clock
+	tasks    []janitorTask
+	stopped  bool
+	wake     chan struct{}
+	attempts int
+	interval time.Duration
+	maxDelay time.Duration
+	timeout  time.Duration
+	onError  func(err error)
+	onFinal  func(d Disposition)
+	stats    JanitorStats
+}
+
+// JanitorOpt is a type for optional parameters to NewJanitor.
+type JanitorOpt func(*Janitor)
+
+// WithRetryAttempts yields a JanitorOpt that bounds the number of times a
+// submitted Reaper is invoked before the Janitor gives up on it. The default
+// is 3.
+func WithRetryAttempts(n int) JanitorOpt {
+	return func(j *Janitor) {
+		j.attempts = n
+	}
+}
+
+// WithRetryInterval yields a JanitorOpt that sets the delay between the first
+// and second attempts to run a submitted Reaper. The delay doubles after each
+// subsequent failure (see WithMaxRetryInterval). The default is one second.
+func WithRetryInterval(d time.Duration) JanitorOpt {
+	return func(j *Janitor) {
+		j.interval = d
+	}
+}
+
+// WithMaxRetryInterval yields a JanitorOpt that caps the exponential backoff
+// between attempts to run a submitted Reaper. The default is one minute.
+func WithMaxRetryInterval(d time.Duration) JanitorOpt {
+	return func(j *Janitor) {
+		j.maxDelay = d
+	}
+}
+
+// WithAttemptTimeout yields a JanitorOpt that bounds the duration of each
+// individual attempt to run a submitted Reaper. The default is ten seconds.
+func WithAttemptTimeout(d time.Duration) JanitorOpt {
+	return func(j *Janitor) {
+		j.timeout = d
+	}
+}
+
+// WithJanitorErrorHandler yields a JanitorOpt that supplies a func to be
+// notified with the last error returned by a Reaper that the Janitor has
+// given up on.
+func WithJanitorErrorHandler(eh func(err error)) JanitorOpt {
+	return func(j *Janitor) {
+		j.onError = eh
+	}
+}
+
+// WithDispositionHandler yields a JanitorOpt that supplies a func to be
+// notified of the final Disposition of every Reaper submitted to the Janitor.
+func WithDispositionHandler(fn func(d Disposition)) JanitorOpt {
+	return func(j *Janitor) {
+		j.onFinal = fn
+	}
+}
+
+// NewJanitor launches a Janitor whose worker goroutine is managed by the
+// supplied Lifetime and which measures retry delays with its Clock. The
+// Janitor stops accepting and retrying Reapers when the Lifetime exits; any
+// Reapers still pending at that point are dropped.
+func NewJanitor(s Lifetime, opts ...JanitorOpt) (*Janitor, error) {
+	j := &Janitor{
+		clock:    ClockOf(s),
+		wake:     make(chan struct{}, 1),
+		attempts: 3,
+		interval: time.Second,
+		maxDelay: time.Minute,
+		timeout:  10 * time.Second,
+		onError:  func(err error) {},
+		onFinal:  func(d Disposition) {},
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if err := goIn(s, j.run); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Submit queues the supplied Reaper for immediate execution by the Janitor's
+// worker goroutine. ErrJanitorStopped is returned if the Janitor is no longer
+// running.
+func (j *Janitor) Submit(r Reaper) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.stopped {
+		return ErrJanitorStopped
+	}
+	j.tasks = append(j.tasks, janitorTask{r: r, due: j.clock.Now()})
+	j.stats.Submitted++
+	select {
+	case j.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Pending returns the number of Reapers awaiting execution by the Janitor.
+func (j *Janitor) Pending() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.tasks)
+}
+
+// Stats returns a snapshot of this Janitor's counters.
+func (j *Janitor) Stats() JanitorStats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	stats := j.stats
+	stats.Pending = len(j.tasks)
+	return stats
+}
+
+func (j *Janitor) run(ctx context.Context) {
+	defer func() {
+		j.mu.Lock()
+		j.stopped = true
+		dropped := j.tasks
+		j.tasks = nil
+		j.mu.Unlock()
+		for _, t := range dropped {
+			j.finish(Dropped, t)
+		}
+	}()
+	for {
+		due, next := j.takeDue(j.clock.Now())
+		for _, t := range due {
+			j.attempt(ctx, t)
+		}
+		if len(due) > 0 {
+			continue
+		}
+		if !j.sleep(ctx, next) {
+			return
+		}
+	}
+}
+
+// sleep blocks until the supplied time (if non-zero), a new submission, or
+// the context being done. It returns false in the latter case.
+func (j *Janitor) sleep(ctx context.Context, until time.Time) bool {
+	var timeout <-chan time.Time
+	if !until.IsZero() {
+		timer := j.clock.NewTimer(until.Sub(j.clock.Now()))
+		defer timer.Stop()
+		timeout = timer.C()
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-j.wake:
+	case <-timeout:
+	}
+	return true
+}
+
+// takeDue removes and returns the tasks that are due at the supplied time
+// along with the time at which the next remaining task becomes due (or the
+// zero time if there are none).
+func (j *Janitor) takeDue(now time.Time) ([]janitorTask, time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var due []janitorTask
+	var next time.Time
+	remaining := j.tasks[:0]
+	for _, t := range j.tasks {
+		switch {
+		case !t.due.After(now):
+			due = append(due, t)
+		default:
+			if next.IsZero() || t.due.Before(next) {
+				next = t.due
+			}
+			remaining = append(remaining, t)
+		}
+	}
+	j.tasks = remaining
+	return due, next
+}
+
+func (j *Janitor) attempt(ctx context.Context, t janitorTask) {
+	if ctx.Err() != nil {
+		j.finish(Dropped, t)
+		return
+	}
+	actx, cancel := ClockTimeout(ctx, j.clock, j.timeout)
+	t.err = t.r(actx)
+	cancel()
+	t.attempt++
+	switch {
+	case t.err == nil:
+		j.finish(Reaped, t)
+	case ctx.Err() != nil:
+		j.finish(Dropped, t)
+	case t.attempt >= j.attempts:
+		j.onError(t.err)
+		j.finish(GaveUp, t)
+	default:
+		t.due = j.clock.Now().Add(j.backoff(t.attempt))
+		j.mu.Lock()
+		j.tasks = append(j.tasks, t)
+		j.mu.Unlock()
+	}
+}
+
+// backoff computes the delay before the next attempt to run a Reaper that has
+// already failed the supplied number of times.
+func (j *Janitor) backoff(failures int) time.Duration {
+	d := j.interval
+	for i := 1; i < failures && d < j.maxDelay; i++ {
+		d *= 2
+	}
+	if d > j.maxDelay {
+		d = j.maxDelay
+	}
+	return d
+}
+
+func (j *Janitor) finish(o Outcome, t janitorTask) {
+	j.mu.Lock()
+	switch o {
+	case Reaped:
+		j.stats.Reaped++
+	case GaveUp:
+		j.stats.GaveUp++
+	case Dropped:
+		j.stats.Dropped++
+	}
+	j.mu.Unlock()
+	j.onFinal(Disposition{Outcome: o, Attempts: t.attempt, Err: t.err})
+}
@@ -0,0 +1,82 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestJanitorGivesUp(t *testing.T) {
+	s := nls.NewScope()
+	want := errors.New(t.Name())
+	gaveup := make(chan error, 1)
+	j, err := nls.NewJanitor(s,
+		nls.WithRetryAttempts(3),
+		nls.WithRetryInterval(time.Millisecond),
+		nls.WithJanitorErrorHandler(func(err error) { gaveup <- err }))
+	require(t, err == nil, "unexpected error: %q", err)
+
+	attempts := 0
+	err = j.Submit(func(context.Context) error {
+		attempts++
+		return want
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+
+	select {
+	case got := <-gaveup:
+		require(t, got == want, "expected %q, got %q", want, got)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected janitor to give up")
+	}
+	require(t, attempts == 3, "expected 3 attempts, got %d", attempts)
+	require(t, j.Pending() == 0, "expected no pending reapers")
+
+	s.Exit(context.TODO())
+	err = j.Submit(nilReaper)
+	require(t, err == nls.ErrJanitorStopped, "expected ErrJanitorStopped")
+}
+
+func TestJanitorRetriesFailures(t *testing.T) {
+	root := nls.NewScope()
+	final := make(chan nls.Disposition, 1)
+	j, err := nls.NewJanitor(root,
+		nls.WithRetryInterval(time.Millisecond),
+		nls.WithDispositionHandler(func(d nls.Disposition) { final <- d }))
+	require(t, err == nil, "unexpected error: %q", err)
+
+	failures := 2
+	s := root.NewChildScope()
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			if failures > 0 {
+				failures--
+				return errors.New("transient")
+			}
+			return nil
+		}, nil
+	})
+
+	handled := 0
+	err = s.Exit(context.TODO(),
+		nls.WithJanitor(j),
+		nls.WithRetryFailures(nls.ClassNormal),
+		nls.WithErrorHandler(func(error) { handled++ }))
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, handled == 0, "expected failure to be handed to janitor")
+
+	select {
+	case d := <-final:
+		require(t, d.Outcome == nls.Reaped, "expected reaped, got %v", d.Outcome)
+		require(t, d.Attempts == 2, "expected 2 attempts, got %d", d.Attempts)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected janitor to report disposition")
+	}
+	stats := j.Stats()
+	require(t, stats.Submitted == 1 && stats.Reaped == 1,
+		"unexpected janitor stats %+v", stats)
+	root.Exit(context.TODO())
+}
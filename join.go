@@ -0,0 +1,102 @@
+package nls
+
+import (
+	"context"
+	"errors"
+)
+
+// Join blocks until this Scope has exited, however that Exit was initiated,
+// or until the supplied context is done, in which case the context's error is
+// returned. It allows a component that observes a Scope (e.g. a metrics
+// flusher) to wait for the end of its lifetime without owning its Exit. A
+// Reaper that joins a Scope in the tree it is tearing down, passing on the
+// context it was given, gets a "scope is exiting" error naming the Scope
+// rather than deadlocking.
+func (s *Scope) Join(ctx context.Context) error {
+	if s.reentered(ctx) {
+		return s.misuse("Join", ErrExiting)
+	}
+	select {
+	case <-s.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// closed is the channel returned by Scope.Done for an exited Scope.
+var closed = make(chan struct{})
+
+func init() {
+	close(closed)
+}
+
+// Done returns a channel that is closed once this Scope has exited, for use
+// in select statements alongside ctx.Done(), e.g. to stop a goroutine that is
+// not managed by the Scope when the Scope stops. See also Join.
+func (s *Scope) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.doneLocked()
+}
+
+// doneLocked is Done for callers that hold s.mu.
+func (s *Scope) doneLocked() <-chan struct{} {
+	if s.state == done {
+		return closed
+	}
+	if s.joined == nil {
+		s.joined = make(chan struct{})
+	}
+	return s.joined
+}
+
+// ExitErr returns the outcome of this Scope's Exit once it has completed (see
+// Done), however that Exit was initiated, so that observers other than the
+// caller of Exit can learn whether teardown succeeded. The error joins, in
+// teardown order, those of the Scope's children with the errors reported for
+// its own Reapers (see WithErrorHandler) and the error of the Exit context if
+// it was done before teardown completed. It is nil if teardown succeeded or
+// the Scope has not yet exited.
+func (s *Scope) ExitErr() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exitErr
+}
+
+// exitFailed records an error reported for one of this Scope's Reapers during
+// the Exit configured by ec.
+func (s *Scope) exitFailed(ec *exitCfg, err error) {
+	if err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closer == ec {
+		s.exitErrs = append(s.exitErrs, err)
+	}
+}
+
+// exitResult returns the error to be recorded for ExitErr once the supplied
+// children and this Scope's Reapers have been exited with ctx.
+func (s *Scope) exitResult(ctx context.Context, children []*Scope) error {
+	var errs []error
+	for _, c := range children {
+		if err := c.ExitErr(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.mu.Lock()
+	errs = append(errs, s.exitErrs...)
+	s.exitErrs = nil
+	s.mu.Unlock()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		for _, err := range errs {
+			if errors.Is(err, ctxErr) {
+				return errors.Join(errs...)
+			}
+		}
+		errs = append(errs, ctxErr)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestJoin(t *testing.T) {
+	root := nls.NewScope()
+	s := root.NewChildScope()
+	joined := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { joined <- s.Join(context.TODO()) }()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	err := s.Join(ctx)
+	require(t, err == context.DeadlineExceeded, "expected Join to wait, got %v", err)
+
+	root.Exit(context.TODO())
+	for i := 0; i < 2; i++ {
+		require(t, <-joined == nil, "expected Join to return once the scope exited")
+	}
+	require(t, s.Join(context.TODO()) == nil, "expected Join on an exited scope to return")
+}
+
+func TestJoinFromOwnReaper(t *testing.T) {
+	s := nls.NewScope(nls.WithName("svc"))
+	var err error
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			err = s.Join(ctx)
+			return nil
+		}, nil
+	})
+	s.Exit(context.TODO())
+	require(t, err != nil && err.Error() == "Join on scope svc: scope is exiting",
+		"unexpected error %v", err)
+}
+
+func TestDone(t *testing.T) {
+	s := nls.NewScope()
+	done := s.Done()
+	require(t, s.Done() == done, "expected the same channel from each call")
+	select {
+	case <-done:
+		t.Fatal("expected Done to block while the scope is active")
+	default:
+	}
+	s.Exit(context.TODO())
+	<-done
+	<-s.Done()
+}
+
+func TestExitErr(t *testing.T) {
+	root := nls.NewScope()
+	child := root.NewChildScope()
+	clean := root.NewChildScope()
+	nls.MustSpawn(context.TODO(), clean, nopSpawner)
+	closeErr := errors.New("close failed")
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return closeErr }, nil
+	})
+	labelErr := errors.New("label reap failed")
+	nls.MustSpawn(context.TODO(), root, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return labelErr }, nil
+	}, nls.WithLabel("early"))
+	root.ReapLabel(context.TODO(), "early")
+
+	observed := make(chan error, 1)
+	go func() {
+		child.Join(context.TODO())
+		observed <- child.ExitErr()
+	}()
+	require(t, root.ExitErr() == nil, "expected no outcome before exit")
+
+	err := root.Exit(context.TODO())
+	require(t, err == nil, "unexpected exit error %v", err)
+	require(t, errors.Is(<-observed, closeErr), "expected observer to see child outcome")
+	require(t, errors.Is(root.ExitErr(), closeErr), "expected child outcome in parent's")
+	require(t, !errors.Is(root.ExitErr(), labelErr), "unexpected label reap error in outcome")
+	require(t, clean.ExitErr() == nil, "expected clean exit, got %v", clean.ExitErr())
+}
@@ -0,0 +1,200 @@
+package nls
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// JournalEntry records an externally visible resource, such as a temporary
+// directory or a cloud resource ID, so that it can be reclaimed by a later
+// process should this one crash before reaping it. Data holds whatever the
+// Recoverer registered for Kind needs to reclaim the resource.
+type JournalEntry struct {
+	ID      string    `json:"id"`
+	Kind    string    `json:"kind"`
+	Data    []byte    `json:"data"`
+	PID     int       `json:"pid"`
+	Created time.Time `json:"created"`
+}
+
+// Journal is a durable store of JournalEntries. Implementations must be safe
+// for concurrent use. FileJournal is provided as a reference implementation.
+type Journal interface {
+	// Record durably stores the supplied entry.
+	Record(e JournalEntry) error
+
+	// Remove deletes the entry with the supplied ID. Removing an entry that
+	// does not exist is not an error.
+	Remove(id string) error
+
+	// Entries returns every stored entry.
+	Entries() ([]JournalEntry, error)
+}
+
+// Recoverer reclaims a resource described by the Data of a JournalEntry left
+// behind by a previous process.
+type Recoverer func(ctx context.Context, data []byte) error
+
+// Journaled yields a Spawner that invokes the supplied Spawner and then
+// records the resource it created in the supplied Journal under the supplied
+// kind and data, e.g. the path of a directory or the ID of a cloud resource.
+// The returned Reaper removes the entry once the Spawner's Reaper has
+// succeeded, so an entry outlives its resource only if the process crashes
+// (or the Reaper fails) and can then be reclaimed with Recover. Should the
+// entry not be recorded then the resource is reaped immediately and the error
+// returned, so that nothing is created without a record of it.
+func Journaled(j Journal, kind string, data []byte, sp Spawner) Spawner {
+	return func(ctx context.Context) (Reaper, error) {
+		fn, err := sp(ctx)
+		if err != nil {
+			return nil, err
+		}
+		id, err := journalID()
+		if err == nil {
+			err = j.Record(JournalEntry{
+				ID:      id,
+				Kind:    kind,
+				Data:    data,
+				PID:     os.Getpid(),
+				Created: time.Now(),
+			})
+		}
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("journal: %w", err), fn(context.Background()))
+		}
+		return func(ctx context.Context) error {
+			if err := fn(ctx); err != nil {
+				return err
+			}
+			return j.Remove(id)
+		}, nil
+	}
+}
+
+// Recover reclaims the resources recorded in the supplied Journal by
+// processes that are no longer running, typically at startup after a crash,
+// invoking the Recoverer registered for each entry's Kind and removing the
+// entry if it succeeds. Entries recorded by a running process, including
+// this one, are left alone, as are entries of a Kind with no Recoverer. The
+// returned error joins the errors of every failed Recoverer, annotated with
+// the entry's Kind and ID.
+func Recover(ctx context.Context, j Journal, recoverers map[string]Recoverer) error {
+	entries, err := j.Entries()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, e := range entries {
+		fn, ok := recoverers[e.Kind]
+		if !ok || e.PID == os.Getpid() || (e.PID > 0 && alive(e.PID)) {
+			continue
+		}
+		if err := fn(ctx, e.Data); err != nil {
+			errs = append(errs, fmt.Errorf("recover %s %s: %w", e.Kind, e.ID, err))
+			continue
+		}
+		errs = append(errs, j.Remove(e.ID))
+	}
+	return errors.Join(errs...)
+}
+
+func journalID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// FileJournal is a Journal that stores each entry as a JSON file in a
+// directory. Entries are written to a temporary file and renamed into place
+// so that a crash never leaves a partial entry behind.
+type FileJournal struct {
+	dir string
+}
+
+var _ Journal = (*FileJournal)(nil)
+
+const journalExt = ".json"
+
+// NewFileJournal returns a FileJournal storing entries in the supplied
+// directory, which is created if it does not exist.
+func NewFileJournal(dir string) (*FileJournal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileJournal{dir: dir}, nil
+}
+
+// Record implements Journal.
+func (j *FileJournal) Record(e JournalEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(j.dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), j.path(e.ID))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Remove implements Journal.
+func (j *FileJournal) Remove(id string) error {
+	if err := os.Remove(j.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Entries implements Journal. Files that cannot be parsed are skipped.
+func (j *FileJournal) Entries() ([]JournalEntry, error) {
+	files, err := os.ReadDir(j.dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []JournalEntry
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || strings.HasPrefix(name, ".") || !strings.HasSuffix(name, journalExt) {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(j.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue // removed concurrently
+		}
+		if err != nil {
+			return nil, err
+		}
+		var e JournalEntry
+		if json.Unmarshal(b, &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func (j *FileJournal) path(id string) string {
+	return filepath.Join(j.dir, id+journalExt)
+}
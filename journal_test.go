@@ -0,0 +1,84 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestJournaled(t *testing.T) {
+	j, err := nls.NewFileJournal(filepath.Join(t.TempDir(), "journal"))
+	require(t, err == nil, "unexpected journal error %v", err)
+	s := nls.NewScope()
+	dir := t.TempDir()
+	sp := nls.Journaled(j, "dir", []byte(dir), func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return os.RemoveAll(dir) }, nil
+	})
+	require(t, s.Spawn(context.TODO(), sp) == nil, "unexpected spawn error")
+
+	entries, err := j.Entries()
+	require(t, err == nil && len(entries) == 1, "expected one entry, got %v (%v)", entries, err)
+	e := entries[0]
+	require(t, e.Kind == "dir" && string(e.Data) == dir && e.PID == os.Getpid(), "unexpected entry %+v", e)
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	entries, err = j.Entries()
+	require(t, err == nil && len(entries) == 0, "expected entry removed on exit, got %v (%v)", entries, err)
+}
+
+type failingJournal struct{ nls.Journal }
+
+func (failingJournal) Record(nls.JournalEntry) error { return errors.New("disk full") }
+
+func TestJournaledRecordFailure(t *testing.T) {
+	reaped := false
+	sp := nls.Journaled(failingJournal{}, "x", nil, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			reaped = true
+			return nil
+		}, nil
+	})
+	_, err := sp(context.TODO())
+	require(t, err != nil, "expected journal error")
+	require(t, reaped, "expected unrecorded resource to be reaped")
+}
+
+func TestRecover(t *testing.T) {
+	j, err := nls.NewFileJournal(t.TempDir())
+	require(t, err == nil, "unexpected journal error %v", err)
+	for _, e := range []nls.JournalEntry{
+		{ID: "crashed", Kind: "dir", Data: []byte("a"), PID: 2147483646},
+		{ID: "running", Kind: "dir", Data: []byte("b"), PID: os.Getpid()},
+		{ID: "unknown", Kind: "bucket", Data: []byte("c"), PID: 2147483646},
+		{ID: "failing", Kind: "dir", Data: []byte("d"), PID: 2147483646},
+	} {
+		require(t, j.Record(e) == nil, "unexpected record error")
+	}
+
+	var recovered []string
+	boom := errors.New("boom")
+	err = nls.Recover(context.TODO(), j, map[string]nls.Recoverer{
+		"dir": func(_ context.Context, data []byte) error {
+			recovered = append(recovered, string(data))
+			if string(data) == "d" {
+				return boom
+			}
+			return nil
+		},
+	})
+	require(t, errors.Is(err, boom), "expected recoverer error, got %v", err)
+	require(t, len(recovered) == 2, "expected orphaned entries to be recovered, got %v", recovered)
+
+	entries, err := j.Entries()
+	require(t, err == nil, "unexpected entries error %v", err)
+	left := map[string]bool{}
+	for _, e := range entries {
+		left[e.ID] = true
+	}
+	require(t, len(left) == 3 && left["running"] && left["unknown"] && left["failing"],
+		"unexpected entries left %v", left)
+}
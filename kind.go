@@ -0,0 +1,89 @@
+package nls
+
+// Kind labels a Scope with the category of lifetime that it represents so that
+// policy can be applied uniformly to, and telemetry grouped by, all Scopes of
+// the same Kind.
+type Kind string
+
+const (
+	// KindRequest is the Kind of Scopes created via Scope.NewRequestScope.
+	KindRequest Kind = "request"
+
+	// KindSession is the Kind of Scopes created via Scope.NewSessionScope.
+	KindSession Kind = "session"
+
+	// KindJob is the Kind of Scopes created via Scope.NewJobScope.
+	KindJob Kind = "job"
+)
+
+// WithKind yields a ScopeOpt that labels the new Scope with the supplied Kind.
+// Note that this does not apply the defaults registered for the Kind; use one
+// of the Kind-specific constructors (e.g. Scope.NewRequestScope) for that.
+func WithKind(k Kind) ScopeOpt {
+	return func(s *Scope) {
+		s.kind = k
+	}
+}
+
+// WithKindDefaults yields a ScopeOpt that registers options to be applied to
+// every descendant Scope of the supplied Kind created via one of the
+// Kind-specific constructors. Defaults registered on the nearest ancestor
+// replace those registered further up the tree. Options supplied directly to
+// the constructor are applied after (and so take precedence over) the
+// defaults.
+func WithKindDefaults(k Kind, opts ...ScopeOpt) ScopeOpt {
+	return func(s *Scope) {
+		if s.kinds == nil {
+			s.kinds = make(map[Kind][]ScopeOpt)
+		}
+		s.kinds[k] = opts
+	}
+}
+
+// Kind returns the Kind with which this Scope was labeled.
+func (s *Scope) Kind() Kind {
+	return s.kind
+}
+
+// NewRequestScope creates a child Scope of Kind KindRequest. Unless overridden
+// by registered defaults, request Scopes propagate their errors to their
+// parent (see WithErrorPropagation).
+func (s *Scope) NewRequestScope(opts ...ScopeOpt) *Scope {
+	return s.newKindScope(KindRequest, opts)
+}
+
+// NewSessionScope creates a child Scope of Kind KindSession. Unless overridden
+// by registered defaults, session Scopes propagate their errors to their
+// parent (see WithErrorPropagation).
+func (s *Scope) NewSessionScope(opts ...ScopeOpt) *Scope {
+	return s.newKindScope(KindSession, opts)
+}
+
+// NewJobScope creates a child Scope of Kind KindJob. Unless overridden by
+// registered defaults, job Scopes propagate their errors to their parent (see
+// WithErrorPropagation).
+func (s *Scope) NewJobScope(opts ...ScopeOpt) *Scope {
+	return s.newKindScope(KindJob, opts)
+}
+
+func (s *Scope) newKindScope(k Kind, opts []ScopeOpt) *Scope {
+	return s.NewChildScope(s.kindOpts(k, opts)...)
+}
+
+// kindOpts returns the ScopeOpts with which a child of Kind k is created
+// given the supplied options.
+func (s *Scope) kindOpts(k Kind, opts []ScopeOpt) []ScopeOpt {
+	all := []ScopeOpt{WithErrorPropagation()}
+	all = append(all, s.kindDefaults(k)...)
+	all = append(all, WithKind(k))
+	return append(all, opts...)
+}
+
+func (s *Scope) kindDefaults(k Kind) []ScopeOpt {
+	for sc := s; sc != nil; sc = sc.up() {
+		if opts, ok := sc.kinds[k]; ok {
+			return opts
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestKindScopes(t *testing.T) {
+	out := make(chan error)
+	jobErrs := make(chan error)
+	root := nls.NewScope(
+		nls.WithErrorChan(out),
+		nls.WithKindDefaults(nls.KindJob, nls.WithErrorChan(jobErrs)))
+	defer root.Exit(context.TODO())
+
+	req := root.NewRequestScope(nls.WithName("req"))
+	require(t, req.Kind() == nls.KindRequest, "unexpected kind %q", req.Kind())
+	require(t, req.Err() == out,
+		"expected request scope to propagate errors by default")
+
+	session := root.NewChildScope().NewSessionScope()
+	require(t, session.Kind() == nls.KindSession,
+		"unexpected kind %q", session.Kind())
+
+	job := root.NewChildScope().NewJobScope()
+	require(t, job.Kind() == nls.KindJob, "unexpected kind %q", job.Kind())
+	require(t, job.Err() == jobErrs,
+		"expected job scope to use inherited kind defaults")
+
+	var sc nls.Scoper = root.NewRequestScope
+	require(t, sc().Kind() == nls.KindRequest,
+		"expected kind constructors to be usable as Scopers")
+}
@@ -0,0 +1,63 @@
+package nls
+
+import (
+	"context"
+	"errors"
+)
+
+// WithLabel yields a SpawnOpt that attaches the supplied label to the spawned
+// object so that it can be reaped ahead of the rest of its Scope with
+// Scope.ReapLabel. An object may carry several labels.
+func WithLabel(label string) SpawnOpt {
+	return func(r *reaper) {
+		r.labels = append(r.labels, label)
+	}
+}
+
+// ReapLabel invokes, and removes from this Scope, the Reapers of every object
+// spawned into it with the supplied label (see WithLabel), leaving the Scope
+// and its other objects untouched. This allows part of a Scope to be torn
+// down early, e.g. to roll back a feature flag. Reapers are invoked in the
+// same order, and the supplied context and ExitOpts interpreted in the same
+// way, as by Scope.Exit. Objects owned by descendant Scopes are not affected.
+// The returned error joins every error passed to the error handler (see
+// WithErrorHandler) with the context's error, if any. An error is also
+// returned if this Scope has exited.
+func (s *Scope) ReapLabel(ctx context.Context, label string, opts ...ExitOpt) error {
+	s.mu.Lock()
+	if s.state != active {
+		s.mu.Unlock()
+		return stateError{"reap in", s.state}
+	}
+	var matched, kept []reaper
+	for _, r := range s.reapers {
+		if r.labeled(label) {
+			matched = append(matched, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	if len(matched) > 0 {
+		s.readopt(kept)
+		s.recordOwnership(OwnershipRelease, nil, len(matched))
+	}
+	s.mu.Unlock()
+	for _, r := range matched {
+		if r.handle != nil {
+			r.handle.bind(nil)
+		}
+	}
+
+	ec := s.newExitCfg(opts)
+	ec.reapAll(context.WithValue(ctx, teardownKey{}, ec), s, nil, ordered(matched))
+	return errors.Join(append(ec.errs, ctx.Err())...)
+}
+
+func (r reaper) labeled(label string) bool {
+	for _, l := range r.labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestReapLabel(t *testing.T) {
+	s := nls.NewScope()
+	var order []string
+	record := func(name string, err error) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				order = append(order, name)
+				return err
+			}, nil
+		}
+	}
+	boom := errors.New("boom")
+	var h nls.Handle
+	nls.MustSpawn(context.TODO(), s, record("db", nil))
+	nls.MustSpawn(context.TODO(), s, record("cache-a", nil), nls.WithLabel("cache"), nls.WithHandle(&h))
+	nls.MustSpawn(context.TODO(), s, record("cache-b", boom), nls.WithLabel("cache"))
+
+	err := s.ReapLabel(context.TODO(), "cache")
+	require(t, errors.Is(err, boom), "expected reaper error to be returned, got %v", err)
+	require(t, len(order) == 2 && order[0] == "cache-b" && order[1] == "cache-a",
+		"expected labeled reapers in reverse order, got %v", order)
+	require(t, h.Scope() == nil, "expected handle to be unbound")
+	require(t, s.ReapLabel(context.TODO(), "cache") == nil, "expected nothing left to reap")
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, len(order) == 3 && order[2] == "db", "expected remaining reaper on exit, got %v", order)
+	require(t, s.ReapLabel(context.TODO(), "cache") != nil, "expected error from exited scope")
+}
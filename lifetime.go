@@ -0,0 +1,59 @@
+package nls
+
+import "context"
+
+// Lifetime is the minimal set of Scope operations upon which resource
+// helpers, such as TempFile and those of the nls subpackages, depend. It is
+// implemented by *Scope and may be implemented by wrappers (e.g. to add
+// instrumentation) or by substitutes (e.g. remote or simulated lifetimes) so
+// that they can be used with those helpers. SpawnOpts, ExitOpts and ScopeOpts
+// can only be interpreted by a *Scope so implementations that do not wrap one
+// may ignore them. Helpers that need more than a Lifetime provides use the
+// Clock of a Lifetime that has a Clock method and the Go method of one that
+// has a Go method, as a wrapper embedding a *Scope does, and otherwise the
+// real clock and plain goroutines. Helpers that hand each unit of work a
+// child Scope of its own, such as NewScheduler and nlsnet.Serve, require a
+// *Scope, since only a Scope can create the Scopes they pass on.
+type Lifetime interface {
+	// Spawn is as per Scope.Spawn.
+	Spawn(ctx context.Context, sp Spawner, opts ...SpawnOpt) error
+
+	// Exit is as per Scope.Exit.
+	Exit(ctx context.Context, opts ...ExitOpt) error
+
+	// NewChild is as per Scope.NewChildScope.
+	NewChild(opts ...ScopeOpt) Lifetime
+
+	// Err is as per Scope.Err.
+	Err() chan error
+
+	// ReportErr is as per Scope.ReportErr.
+	ReportErr(err error)
+
+	// OfferErr is as per Scope.OfferErr.
+	OfferErr(err error)
+}
+
+var _ Lifetime = (*Scope)(nil)
+
+// NewChild implements Lifetime via Scope.NewChildScope.
+func (s *Scope) NewChild(opts ...ScopeOpt) Lifetime {
+	return s.NewChildScope(opts...)
+}
+
+// goer is implemented by *Scope and by wrappers embedding one.
+type goer interface {
+	Go(fn func(context.Context)) error
+}
+
+// goIn launches fn as per Scope.Go using the Go method of the supplied
+// Lifetime, if it has one, or else on a plain goroutine stopped and awaited
+// by a Reaper spawned into the Lifetime.
+func goIn(l Lifetime, fn func(context.Context)) error {
+	if g, ok := l.(goer); ok {
+		return g.Go(fn)
+	}
+	return l.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		return goReaper(context.Background(), func(_ context.Context, f func()) { go f() }, fn), nil
+	})
+}
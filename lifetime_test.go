@@ -0,0 +1,75 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+// counting is a Lifetime that instruments a wrapped Scope.
+type counting struct {
+	*nls.Scope
+	spawns int
+}
+
+func (c *counting) Spawn(ctx context.Context, sp nls.Spawner, opts ...nls.SpawnOpt) error {
+	c.spawns++
+	return c.Scope.Spawn(ctx, sp, opts...)
+}
+
+func (c *counting) NewChild(opts ...nls.ScopeOpt) nls.Lifetime {
+	return &counting{Scope: c.Scope.NewChildScope(opts...)}
+}
+
+func TestLifetime(t *testing.T) {
+	root := &counting{Scope: nls.NewScope()}
+	var lt nls.Lifetime = root
+	child := lt.NewChild()
+	f, err := nls.TempFile(child, t.TempDir(), "lifetime")
+	require(t, err == nil, "unexpected error: %v", err)
+	_, err = nls.TempDir(lt, "lifetime")
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, root.spawns == 1 && child.(*counting).spawns == 1,
+		"expected spawns to go through the wrapper")
+
+	err = lt.Exit(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+	_, err = os.Stat(f.Name())
+	require(t, os.IsNotExist(err), "expected file to be removed with its lifetime")
+}
+
+func TestLifetimeHelpers(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	lt := &counting{Scope: nls.NewScope(nls.WithClock(clock))}
+	beats, err := nls.HeartbeatChan(lt, time.Second)
+	require(t, err == nil, "unexpected error: %v", err)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	b := <-beats
+	require(t, b.Time.Equal(clock.Now()), "expected the wrapped scope's clock, got %v", b.Time)
+
+	boom := errors.New("boom")
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	err = nls.ReapOnDone(ctx, lt, func(context.Context) error {
+		calls.Add(1)
+		return boom
+	})
+	require(t, err == nil, "unexpected error: %v", err)
+	cancel()
+	select {
+	case err := <-lt.Err():
+		require(t, errors.Is(err, boom), "expected reaper error to be reported, got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("reaper not run on context cancellation")
+	}
+	require(t, lt.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, calls.Load() == 1, "expected one call, got %d", calls.Load())
+	require(t, lt.spawns == 2, "expected spawns to go through the wrapper, got %d", lt.spawns)
+}
@@ -0,0 +1,87 @@
+package nls
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrScopeLimit is matched (via errors.Is) by errors returned when a Spawn or
+// NewChildScope would exceed a limit set with WithReaperLimit or
+// WithChildLimit.
+var ErrScopeLimit = errors.New("scope limit exceeded")
+
+// WithChildLimit yields a ScopeOpt that caps the number of live child Scopes
+// of the new Scope. Beyond the cap NewChildScope returns a Scope that has
+// already exited, so that Spawns into it fail, and the limit handler (see
+// WithLimitHandler), if any, is notified. This acts as a circuit breaker
+// against runaway Scope creation. It may also be applied via Scope.Configure,
+// in which case a lowered cap leaves existing children in place but refuses
+// new ones until enough of them have exited.
+func WithChildLimit(n int) ScopeOpt {
+	return func(s *Scope) {
+		s.childLimit = n
+		s.reconfig = true
+	}
+}
+
+// WithReaperLimit yields a ScopeOpt that caps the number of Reapers that may
+// be held at once by the new Scope (not counting those of its children).
+// Spawns beyond the cap fail with an error matching ErrScopeLimit, without
+// invoking their Spawner, and the limit handler (see WithLimitHandler), if
+// any, is notified. It may also be applied via Scope.Configure, in which case
+// a lowered cap leaves the Reapers already held in place.
+func WithReaperLimit(n int) ScopeOpt {
+	return func(s *Scope) {
+		s.reaperLimit = n
+		s.reconfig = true
+	}
+}
+
+// WithLimitHandler yields a ScopeOpt that registers a func to be notified,
+// with an error matching ErrScopeLimit, each time a limit set by
+// WithChildLimit or WithReaperLimit on the new Scope is hit. The func is
+// called synchronously by the offending Spawn or NewChildScope.
+func WithLimitHandler(fn func(error)) ScopeOpt {
+	return func(s *Scope) {
+		s.onLimit = fn
+	}
+}
+
+// childLimited returns an error if this Scope may not have another child.
+// The caller must hold s.mu.
+func (s *Scope) childLimited() error {
+	if s.childLimit > 0 && len(s.children)-s.holes >= s.childLimit {
+		return s.misuse("NewChildScope",
+			fmt.Errorf("%w: %d children", ErrScopeLimit, s.childLimit))
+	}
+	return nil
+}
+
+// reaperLimited returns an error if this Scope may not hold another reaper.
+// The caller must hold s.mu.
+func (s *Scope) reaperLimited() error {
+	if s.reaperLimit > 0 && len(s.reapers) >= s.reaperLimit {
+		return s.misuse("Spawn",
+			fmt.Errorf("%w: %d reapers", ErrScopeLimit, s.reaperLimit))
+	}
+	return nil
+}
+
+// breached notifies the limit handler, if any, should the supplied error
+// match ErrScopeLimit and returns the error unchanged. The caller must not
+// hold s.mu.
+func (s *Scope) breached(err error) error {
+	if s.onLimit != nil && errors.Is(err, ErrScopeLimit) {
+		s.onLimit(err)
+	}
+	return err
+}
+
+// refused returns a Scope, created with the supplied options, that has
+// already exited. It stands in for a child that could not be created.
+func (s *Scope) refused(opts []ScopeOpt) *Scope {
+	child := newScope(s, opts)
+	child.parent = s
+	child.state = done
+	return child
+}
@@ -0,0 +1,69 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestReaperLimit(t *testing.T) {
+	var breaches []error
+	s := nls.NewScope(nls.WithName("handler"), nls.WithReaperLimit(2),
+		nls.WithLimitHandler(func(err error) { breaches = append(breaches, err) }))
+	defer s.Exit(context.TODO())
+	for i := 0; i < 2; i++ {
+		nls.MustSpawn(context.TODO(), s, nopSpawner)
+	}
+	invoked := false
+	err := s.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+		invoked = true
+		return nopReaper, nil
+	})
+	require(t, errors.Is(err, nls.ErrScopeLimit), "expected limit error, got %v", err)
+	require(t, !invoked, "expected spawner not to be invoked beyond the limit")
+	require(t, len(breaches) == 1 && breaches[0] == err, "expected handler to be notified")
+}
+
+func TestChildLimit(t *testing.T) {
+	var breaches []error
+	s := nls.NewScope(nls.WithChildLimit(1),
+		nls.WithLimitHandler(func(err error) { breaches = append(breaches, err) }))
+	defer s.Exit(context.TODO())
+
+	first := s.NewChildScope()
+	refused := s.NewChildScope(nls.WithName("second"))
+	require(t, refused != s && refused != first, "expected a distinct refused scope")
+	require(t, s.Spawn(context.TODO(), nopSpawner) == nil, "expected parent to remain usable")
+	require(t, refused.Spawn(context.TODO(), nopSpawner) != nil,
+		"expected spawn into refused scope to fail")
+	require(t, len(breaches) == 1 && errors.Is(breaches[0], nls.ErrScopeLimit),
+		"expected handler to be notified, got %v", breaches)
+
+	require(t, first.Exit(context.TODO()) == nil, "unexpected exit error")
+	replacement := s.NewChildScope()
+	require(t, replacement.Spawn(context.TODO(), nopSpawner) == nil,
+		"expected room for a child once one has exited")
+}
+
+func TestConfigureLimits(t *testing.T) {
+	s := nls.NewScope()
+	defer s.Exit(context.TODO())
+	_, err := s.NewChildScopeErr()
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, s.Spawn(context.TODO(), nopSpawner) == nil, "unexpected spawn error")
+
+	err = s.Configure(nls.WithChildLimit(1), nls.WithReaperLimit(1))
+	require(t, err == nil, "unexpected configure error: %v", err)
+	_, err = s.NewChildScopeErr()
+	require(t, errors.Is(err, nls.ErrScopeLimit), "expected child limit error, got %v", err)
+	err = s.Spawn(context.TODO(), nopSpawner)
+	require(t, errors.Is(err, nls.ErrScopeLimit), "expected reaper limit error, got %v", err)
+
+	err = s.Configure(nls.WithChildLimit(0), nls.WithReaperLimit(0))
+	require(t, err == nil, "unexpected configure error: %v", err)
+	_, err = s.NewChildScopeErr()
+	require(t, err == nil, "expected lifted child limit, got %v", err)
+	require(t, s.Spawn(context.TODO(), nopSpawner) == nil, "expected lifted reaper limit")
+}
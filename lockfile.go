@@ -0,0 +1,99 @@
+package nls
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ErrLocked is matched (via errors.Is) by the error returned by LockFile and
+// PIDFile when the lock is held by another live process.
+var ErrLocked = errors.New("lock held by another process")
+
+// LockFile opens, creating it if necessary, the file at the supplied path and
+// takes an exclusive advisory lock on it (flock(2)) without blocking, then
+// registers a Reaper with the supplied Scope that releases the lock and closes
+// the file on Exit. This is typically used to ensure that only one instance
+// of a daemon runs at a time. An error matching ErrLocked is returned if
+// another process holds the lock. The operating system releases the lock
+// should the process die without exiting the Scope, so a lock file can never
+// be stale; the file itself is left in place as removing it would race with
+// another process taking the lock. LockFile is not supported on platforms
+// without flock(2), such as Windows.
+func LockFile(s Lifetime, path string) error {
+	return s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		if err := flock(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("lock %s: %w", path, err)
+		}
+		return func(context.Context) error {
+			return errors.Join(funlock(f), f.Close())
+		}, nil
+	}, WithResource(ResourceFile))
+}
+
+// PIDFile writes the current process ID to a new file at the supplied path and
+// registers a Reaper with the supplied Scope that removes the file on Exit,
+// unless it has since been replaced by another process. Should the file
+// already exist, an error matching ErrLocked is returned if the process whose
+// ID it records is still running; otherwise the file is stale, e.g. left by a
+// process that crashed without exiting its Scope, and is replaced. Stale
+// detection cannot tell a crashed process from an unrelated one that has
+// since been assigned the same ID, so a LockFile should be preferred where
+// only mutual exclusion is needed.
+func PIDFile(s Lifetime, path string) error {
+	return s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		pid := []byte(strconv.Itoa(os.Getpid()) + "\n")
+		if err := writePIDFile(path, pid); err != nil {
+			return nil, err
+		}
+		return func(context.Context) error {
+			if b, err := os.ReadFile(path); err != nil || !bytes.Equal(b, pid) {
+				return nil // removed or taken over by another process
+			}
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+			return nil
+		}, nil
+	})
+}
+
+// writePIDFile creates the file at path containing pid, replacing it once if
+// it is stale.
+func writePIDFile(path string, pid []byte) error {
+	for attempt := 0; ; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err == nil {
+			_, err = f.Write(pid)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				os.Remove(path)
+			}
+			return err
+		}
+		if !errors.Is(err, os.ErrExist) || attempt > 0 {
+			return err
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		other, err := strconv.Atoi(string(bytes.TrimSpace(b)))
+		if err == nil && other > 0 && alive(other) {
+			return fmt.Errorf("pidfile %s: %w (pid %d)", path, ErrLocked, other)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+}
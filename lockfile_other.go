@@ -0,0 +1,24 @@
+//go:build !unix
+
+package nls
+
+import (
+	"errors"
+	"os"
+)
+
+var errLockUnsupported = errors.New("file locking is not supported on this platform")
+
+func flock(*os.File) error { return errLockUnsupported }
+
+func funlock(*os.File) error { return errLockUnsupported }
+
+// alive reports whether a process with the supplied ID is running.
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
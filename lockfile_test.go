@@ -0,0 +1,56 @@
+//go:build unix
+
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.lock")
+	s := nls.NewScope()
+	require(t, nls.LockFile(s, path) == nil, "unexpected lock error")
+
+	other := nls.NewScope()
+	err := nls.LockFile(other, path)
+	require(t, errors.Is(err, nls.ErrLocked), "expected lock to be held, got %v", err)
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, nls.LockFile(other, path) == nil, "expected lock to be released on exit")
+	require(t, other.Exit(context.TODO()) == nil, "unexpected exit error")
+}
+
+func TestPIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.pid")
+	s := nls.NewScope()
+	require(t, nls.PIDFile(s, path) == nil, "unexpected pidfile error")
+	b, err := os.ReadFile(path)
+	require(t, err == nil && string(b) == strconv.Itoa(os.Getpid())+"\n", "unexpected pidfile contents %q", b)
+
+	err = nls.PIDFile(nls.NewScope(), path)
+	require(t, errors.Is(err, nls.ErrLocked), "expected running process to hold pidfile, got %v", err)
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	_, err = os.Stat(path)
+	require(t, errors.Is(err, os.ErrNotExist), "expected pidfile to be removed, got %v", err)
+}
+
+func TestPIDFileStale(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daemon.pid")
+	require(t, os.WriteFile(path, []byte("2147483646\n"), 0o644) == nil, "unexpected write error")
+	s := nls.NewScope()
+	require(t, nls.PIDFile(s, path) == nil, "expected stale pidfile to be replaced")
+
+	// a pidfile taken over by another process is left in place
+	require(t, os.WriteFile(path, []byte("1\n"), 0o644) == nil, "unexpected write error")
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	_, err := os.Stat(path)
+	require(t, err == nil, "expected replaced pidfile to be kept, got %v", err)
+}
@@ -0,0 +1,153 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+type mainCfg struct {
+	scopeOpts []ScopeOpt
+	shutdown  []os.Signal
+	reload    func(ctx context.Context, s *Scope) error
+	reloadOn  []os.Signal
+	force     func(s *Scope)
+}
+
+// MainOpt is a type for optional parameters to Main.
+type MainOpt func(*mainCfg)
+
+// WithMainScopeOpts yields a MainOpt that supplies the ScopeOpts with which
+// Main creates the root Scope.
+func WithMainScopeOpts(opts ...ScopeOpt) MainOpt {
+	return func(cfg *mainCfg) {
+		cfg.scopeOpts = append(cfg.scopeOpts, opts...)
+	}
+}
+
+// WithShutdownSignals yields a MainOpt that replaces the signals upon which
+// Main exits the root Scope. The default is os.Interrupt and syscall.SIGTERM
+// which, on Windows, Go also delivers for the console's close, logoff and
+// shutdown events, so the same default serves every platform.
+func WithShutdownSignals(sigs ...os.Signal) MainOpt {
+	return func(cfg *mainCfg) {
+		cfg.shutdown = sigs
+	}
+}
+
+// WithReload yields a MainOpt that calls the supplied func, with a context
+// carrying the root Scope (see FromContext), each time the process receives
+// one of the supplied signals, or syscall.SIGHUP if none are supplied, e.g.
+// to reread configuration. Errors are offered on the root Scope's error
+// channel but are dropped if nothing is receiving. Reloads are not run
+// concurrently with one another. Without this option a reload signal is left
+// to its default disposition, which for SIGHUP terminates the process. Note
+// that Windows never delivers SIGHUP.
+func WithReload(fn func(ctx context.Context, s *Scope) error, sigs ...os.Signal) MainOpt {
+	return func(cfg *mainCfg) {
+		cfg.reload = fn
+		cfg.reloadOn = sigs
+		if len(sigs) == 0 {
+			cfg.reloadOn = []os.Signal{syscall.SIGHUP}
+		}
+	}
+}
+
+// WithForceQuit yields a MainOpt that escalates a second shutdown signal,
+// received while the graceful Exit begun by the first is in progress, to
+// terminating the process with the supplied exit code. Before exiting, a
+// diagnostic dump is written to the supplied io.Writer, or os.Stderr if nil:
+// what remains of the Scope tree (see Scope.Plan) followed by the stack of
+// every goroutine, showing what the Exit was stuck on. Without this option a
+// second signal cancels the Exit's context as per BindSignals.
+func WithForceQuit(code int, w io.Writer) MainOpt {
+	if w == nil {
+		w = os.Stderr
+	}
+	return func(cfg *mainCfg) {
+		cfg.force = func(s *Scope) {
+			fmt.Fprintf(w, "forced quit: second signal received while exiting %s\n", s.Path())
+			RenderPlan(w, s.Plan(), FormatTree)
+			fmt.Fprintf(w, "\n%s", allStacks())
+			os.Exit(code)
+		}
+	}
+}
+
+// Main runs a program whose lifetime is that of a new root Scope, replacing
+// the signal handling boilerplate otherwise repeated in each command's main
+// func. It creates the Scope, binds it to the shutdown signals as per
+// BindSignals (see WithShutdownSignals and WithForceQuit), and calls run
+// with the Scope and a context that carries it (see FromContext) and is
+// canceled once the Scope has exited. Should run fail, the Scope is exited
+// immediately, bounded by its exit timeout or 30 seconds if it has none;
+// otherwise Main waits for a shutdown signal, or for the Scope to be exited
+// by other means. The returned error joins that of run with the outcome of
+// the Scope's Exit (see Scope.ExitErr).
+func Main(run func(ctx context.Context, s *Scope) error, opts ...MainOpt) error {
+	cfg := mainCfg{shutdown: shutdownSignals}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := NewScope(cfg.scopeOpts...)
+	var force func()
+	if cfg.force != nil {
+		force = func() { cfg.force(s) }
+	}
+	restore := s.bindSignals(cfg.shutdown, force)
+	defer restore()
+	ctx, cancel := context.WithCancel(NewContext(context.Background(), s))
+	defer cancel()
+	go func() {
+		select {
+		case <-s.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	if cfg.reload != nil {
+		stop := s.reloadOn(ctx, cfg.reload, cfg.reloadOn)
+		defer stop()
+	}
+
+	err := run(ctx, s)
+	if err != nil {
+		budget := s.exitTimeout()
+		if budget <= 0 {
+			budget = defaultExitBudget
+		}
+		ectx, cancel := ClockTimeout(context.Background(), s.clock, budget)
+		defer cancel()
+		s.Exit(ectx)
+	}
+	s.Join(context.Background())
+	return errors.Join(err, s.ExitErr())
+}
+
+// reloadOn calls fn each time one of the supplied signals is received until
+// the returned func is called.
+func (s *Scope) reloadOn(ctx context.Context, fn func(ctx context.Context, s *Scope) error, sigs []os.Signal) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := fn(ctx, s); err != nil {
+					s.OfferErr(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
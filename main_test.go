@@ -0,0 +1,150 @@
+//go:build !windows
+
+package nls_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestMainSignals(t *testing.T) {
+	boom := errors.New("boom")
+	reloads := make(chan struct{}, 1)
+	ready := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- nls.Main(func(ctx context.Context, s *nls.Scope) error {
+			got, ok := nls.FromContext(ctx)
+			require(t, ok && got == s, "expected context to carry the root scope")
+			nls.MustSpawn(ctx, s, func(context.Context) (nls.Reaper, error) {
+				return func(context.Context) error { return boom }, nil
+			})
+			close(ready)
+			return nil
+		},
+			nls.WithMainScopeOpts(nls.WithName("main")),
+			nls.WithShutdownSignals(syscall.SIGUSR1),
+			nls.WithReload(func(ctx context.Context, s *nls.Scope) error {
+				reloads <- struct{}{}
+				return nil
+			}, syscall.SIGUSR2))
+	}()
+	<-ready
+
+	raise(t, syscall.SIGUSR2)
+	select {
+	case <-reloads:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected reload signal to call reload func")
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("expected Main to wait for a shutdown signal, got %v", err)
+	default:
+	}
+
+	raise(t, syscall.SIGUSR1)
+	select {
+	case err := <-done:
+		require(t, errors.Is(err, boom), "expected exit error to be returned, got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected shutdown signal to end Main")
+	}
+}
+
+func TestMainRunError(t *testing.T) {
+	boom := errors.New("boom")
+	reaped := false
+	err := nls.Main(func(ctx context.Context, s *nls.Scope) error {
+		nls.MustSpawn(ctx, s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				reaped = true
+				return nil
+			}, nil
+		})
+		return boom
+	}, nls.WithShutdownSignals(syscall.SIGUSR1))
+	require(t, errors.Is(err, boom), "expected run error, got %v", err)
+	require(t, reaped, "expected scope to be exited when run fails")
+}
+
+func TestMainRunWaitsForExit(t *testing.T) {
+	done := make(chan error, 1)
+	go func() {
+		done <- nls.Main(func(ctx context.Context, s *nls.Scope) error {
+			go s.Exit(context.Background())
+			<-ctx.Done()
+			return nil
+		}, nls.WithShutdownSignals(syscall.SIGUSR1))
+	}()
+	select {
+	case err := <-done:
+		require(t, err == nil, "unexpected error %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected run's context to be canceled once the scope exited")
+	}
+}
+
+const forceQuitHelperEnv = "NLS_FORCE_QUIT_HELPER"
+
+// TestForceQuitHelper is not a real test: it is the process run by
+// TestMainForceQuit.
+func TestForceQuitHelper(t *testing.T) {
+	if os.Getenv(forceQuitHelperEnv) == "" {
+		t.Skip("helper process for TestMainForceQuit")
+	}
+	nls.Main(func(ctx context.Context, s *nls.Scope) error {
+		nls.MustSpawn(ctx, s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				fmt.Println("exiting")
+				select {} // stuck, ignoring its context
+			}, nil
+		})
+		fmt.Println("ready")
+		return nil
+	}, nls.WithShutdownSignals(syscall.SIGUSR1), nls.WithForceQuit(3, nil))
+	os.Exit(0)
+}
+
+func TestMainForceQuit(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestForceQuitHelper$")
+	cmd.Env = append(os.Environ(), forceQuitHelperEnv+"=1")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	require(t, err == nil, "unexpected pipe error %v", err)
+	require(t, cmd.Start() == nil, "unexpected start error")
+	lines := bufio.NewScanner(stdout)
+	await := func(want string) {
+		t.Helper()
+		for lines.Scan() {
+			if lines.Text() == want {
+				return
+			}
+		}
+		t.Fatalf("helper exited before printing %q", want)
+	}
+
+	await("ready")
+	require(t, cmd.Process.Signal(syscall.SIGUSR1) == nil, "unexpected signal error")
+	await("exiting")
+	require(t, cmd.Process.Signal(syscall.SIGUSR1) == nil, "unexpected signal error")
+	for lines.Scan() {
+	}
+	err = cmd.Wait()
+	require(t, cmd.ProcessState.ExitCode() == 3, "expected forced exit code, got %v", err)
+	dump := stderr.String()
+	require(t, strings.Contains(dump, "forced quit") && strings.Contains(dump, "goroutine "),
+		"expected diagnostic dump, got %q", dump)
+}
@@ -0,0 +1,157 @@
+// Package nlsbroker manages the lifetime of message broker consumers with an
+// nls.Scope: a consumer is spawned into a Scope and, when the Scope exits,
+// stops fetching, drains the message being handled, commits the offsets of
+// handled messages and is closed.
+//
+// To avoid imposing a dependency on any particular client library, the
+// functions in this package accept a small generic interface modelled on
+// Kafka-style readers, which e.g. *kafka.Reader from
+// github.com/segmentio/kafka-go satisfies as a Reader[kafka.Message]. Push
+// based clients, such as NATS subscriptions, can be adapted by delivering
+// messages to a channel that FetchMessage receives from.
+package nlsbroker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+// Reader is the subset of a consumer client API used by Consume.
+type Reader[M any] interface {
+	// FetchMessage blocks until the next message is available or the
+	// context is done.
+	FetchMessage(ctx context.Context) (M, error)
+
+	// CommitMessages records that the supplied messages have been handled.
+	CommitMessages(ctx context.Context, msgs ...M) error
+
+	// Close releases the consumer, e.g. leaving its consumer group.
+	Close() error
+}
+
+// ErrDrainTimeout is matched (via errors.Is) by the error returned on Exit
+// when the message being handled did not complete within the drain period.
+var ErrDrainTimeout = errors.New("consumer drain timed out")
+
+type config struct {
+	drain time.Duration
+	batch int
+}
+
+// Opt is a type for optional parameters to Consume.
+type Opt func(*config)
+
+// WithDrainTimeout yields an Opt that bounds how long the message being
+// handled when the Scope exits is given to complete before its context is
+// canceled. The Exit context's deadline, if sooner, also applies. The default
+// is ten seconds.
+func WithDrainTimeout(d time.Duration) Opt {
+	return func(cfg *config) {
+		cfg.drain = d
+	}
+}
+
+// WithCommitBatch yields an Opt that commits handled messages in batches of
+// up to n rather than one at a time. Messages handled since the last commit
+// are committed on Exit. The default is one.
+func WithCommitBatch(n int) Opt {
+	return func(cfg *config) {
+		cfg.batch = n
+	}
+}
+
+// Consume fetches messages from the supplied Reader on a new goroutine,
+// passing each in turn to the supplied handler func and committing it once
+// handled, and registers a Reaper with the supplied Scope that stops
+// consumption. The Reaper stops fetching, waits for the message being handled
+// to complete (see WithDrainTimeout), commits any handled messages not yet
+// committed and closes the Reader, returning the errors of each step joined.
+// Should the handler not return once its context is canceled, the Reaper
+// gives up waiting when the Exit context is done, committing nothing. Errors
+// from the handler or from committing are reported via Scope.OfferErr, so
+// they are dropped if nothing is ready to receive them, and consumption
+// continues; a message whose handler fails is not itself committed, though
+// committing a later message may cover it. Should fetching fail before the
+// Scope exits, the error is reported via Scope.OfferErr and consumption
+// stops.
+func Consume[M any, R Reader[M]](ctx context.Context, s nls.Lifetime, r R, handle func(context.Context, M) error, opts ...Opt) error {
+	cfg := config{drain: 10 * time.Second, batch: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return s.Spawn(ctx, func(context.Context) (nls.Reaper, error) {
+		fetchCtx, stop := context.WithCancel(context.Background())
+		handleCtx, abort := context.WithCancel(context.Background())
+		stopped := make(chan []M, 1)
+		go func() {
+			stopped <- consume[M](fetchCtx, handleCtx, s, r, handle, cfg.batch)
+		}()
+		return func(ctx context.Context) error {
+			defer abort()
+			stop()
+			var errs []error
+			timer := nls.ClockOf(s).NewTimer(cfg.drain)
+			defer timer.Stop()
+			var pending []M
+			select {
+			case pending = <-stopped:
+			case <-timer.C():
+				errs = append(errs, ErrDrainTimeout)
+				abort()
+				select {
+				case pending = <-stopped:
+				case <-ctx.Done():
+					errs = append(errs, ctx.Err())
+				}
+			case <-ctx.Done():
+				errs = append(errs, fmt.Errorf("%w: %w", ErrDrainTimeout, ctx.Err()))
+				abort()
+				select {
+				case pending = <-stopped:
+				case <-ctx.Done():
+				}
+			}
+			if len(pending) > 0 {
+				if err := r.CommitMessages(ctx, pending...); err != nil {
+					errs = append(errs, fmt.Errorf("commit: %w", err))
+				}
+			}
+			if err := r.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("close: %w", err))
+			}
+			return errors.Join(errs...)
+		}, nil
+	})
+}
+
+// consume runs the fetch loop until fetchCtx is done or fetching fails,
+// returning the handled messages that have yet to be committed.
+func consume[M any](fetchCtx, handleCtx context.Context, s nls.Lifetime, r Reader[M], handle func(context.Context, M) error, batch int) []M {
+	var pending []M
+	for {
+		msg, err := r.FetchMessage(fetchCtx)
+		if err != nil {
+			if fetchCtx.Err() == nil {
+				s.OfferErr(fmt.Errorf("fetch: %w", err))
+			}
+			return pending
+		}
+		if err := handle(handleCtx, msg); err != nil {
+			s.OfferErr(fmt.Errorf("handle: %w", err))
+			continue
+		}
+		pending = append(pending, msg)
+		if len(pending) < batch || fetchCtx.Err() != nil {
+			continue
+		}
+		if err := r.CommitMessages(handleCtx, pending...); err != nil {
+			s.OfferErr(fmt.Errorf("commit: %w", err))
+			continue
+		}
+		pending = nil
+	}
+}
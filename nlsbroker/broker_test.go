@@ -0,0 +1,158 @@
+package nlsbroker_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlsbroker"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+// fakeReader delivers the messages sent on its channel and records commits.
+type fakeReader struct {
+	msgs      chan int
+	mu        sync.Mutex
+	committed []int
+	closed    bool
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (int, error) {
+	select {
+	case m := <-r.msgs:
+		return m, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, msgs ...int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closed = true
+	return nil
+}
+
+func (r *fakeReader) state() ([]int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.committed...), r.closed
+}
+
+func TestConsumeDrainsAndCommits(t *testing.T) {
+	s := nls.NewScope()
+	r := &fakeReader{msgs: make(chan int)}
+	handling := make(chan int)
+	release := make(chan struct{})
+	err := nlsbroker.Consume(context.TODO(), s, r, func(ctx context.Context, m int) error {
+		handling <- m
+		<-release
+		return nil
+	}, nlsbroker.WithCommitBatch(10))
+	require(t, err == nil, "unexpected spawn error %v", err)
+
+	r.msgs <- 1
+	<-handling
+	close(release)
+	r.msgs <- 2
+	<-handling
+
+	committed, closed := r.state()
+	require(t, len(committed) == 0 && !closed, "expected batch to be pending, got %v", committed)
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	committed, closed = r.state()
+	require(t, len(committed) == 2 && committed[0] == 1 && committed[1] == 2,
+		"expected handled messages to be committed on exit, got %v", committed)
+	require(t, closed, "expected reader to be closed")
+}
+
+func TestConsumeDrainTimeout(t *testing.T) {
+	s := nls.NewScope(nls.WithErrorChan(make(chan error, 1)))
+	r := &fakeReader{msgs: make(chan int)}
+	handling := make(chan struct{})
+	err := nlsbroker.Consume(context.TODO(), s, r, func(ctx context.Context, m int) error {
+		close(handling)
+		<-ctx.Done()
+		return ctx.Err()
+	}, nlsbroker.WithDrainTimeout(10*time.Millisecond))
+	require(t, err == nil, "unexpected spawn error %v", err)
+
+	r.msgs <- 1
+	<-handling
+	var exitErr error
+	done := s.ExitAsync(context.TODO(), nls.WithErrorHandler(func(err error) { exitErr = err }))
+	require(t, errors.Is(<-s.Err(), context.Canceled), "expected handler error to be reported")
+	require(t, <-done == nil, "unexpected exit error")
+	require(t, errors.Is(exitErr, nlsbroker.ErrDrainTimeout), "expected drain timeout, got %v", exitErr)
+	committed, closed := r.state()
+	require(t, len(committed) == 0, "expected failed message not to be committed, got %v", committed)
+	require(t, closed, "expected reader to be closed")
+}
+
+func TestConsumeCommitsEachMessage(t *testing.T) {
+	s := nls.NewScope()
+	r := &fakeReader{msgs: make(chan int)}
+	handled := make(chan struct{})
+	err := nlsbroker.Consume(context.TODO(), s, r, func(ctx context.Context, m int) error {
+		handled <- struct{}{}
+		return nil
+	})
+	require(t, err == nil, "unexpected spawn error %v", err)
+	r.msgs <- 1
+	<-handled
+	r.msgs <- 2
+	<-handled
+	r.msgs <- 3 // fetched only once the second message is committed
+	<-handled
+	committed, _ := r.state()
+	require(t, len(committed) >= 2 && committed[0] == 1 && committed[1] == 2,
+		"expected messages committed as handled, got %v", committed)
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	committed, _ = r.state()
+	require(t, len(committed) == 3, "expected all messages committed, got %v", committed)
+}
+
+func TestConsumeExitBounded(t *testing.T) {
+	s := nls.NewScope()
+	r := &fakeReader{msgs: make(chan int)}
+	handling, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	err := nlsbroker.Consume(context.TODO(), s, r, func(ctx context.Context, m int) error {
+		if m == 1 {
+			return errors.New("unread")
+		}
+		close(handling)
+		<-release // ignores its context
+		return nil
+	}, nlsbroker.WithDrainTimeout(time.Hour))
+	require(t, err == nil, "unexpected spawn error %v", err)
+
+	r.msgs <- 1 // its error is dropped as nothing reads s.Err()
+	r.msgs <- 2
+	<-handling
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var exitErr error
+	start := time.Now()
+	s.Exit(ctx, nls.WithErrorHandler(func(err error) { exitErr = err }))
+	require(t, time.Since(start) < 5*time.Second, "expected exit to be bounded by its context")
+	require(t, errors.Is(exitErr, nlsbroker.ErrDrainTimeout), "expected drain timeout, got %v", exitErr)
+	_, closed := r.state()
+	require(t, closed, "expected reader to be closed")
+}
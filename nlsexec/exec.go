@@ -0,0 +1,141 @@
+// Package nlsexec manages the lifetime of operating system processes with an
+// nls.Scope.
+package nlsexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+// ErrUnexpectedExit is matched (via errors.Is) by the errors reported to a
+// Scope when a process that it manages exits before the Scope does.
+var ErrUnexpectedExit = errors.New("process exited unexpectedly")
+
+type config struct {
+	signal    os.Signal
+	grace     time.Duration
+	heartbeat time.Duration
+}
+
+// Opt is a type for optional parameters to Spawn.
+type Opt func(*config)
+
+// WithStopSignal yields an Opt that overrides the signal used to request that
+// the process terminate gracefully. The default is SIGTERM.
+func WithStopSignal(sig os.Signal) Opt {
+	return func(cfg *config) {
+		cfg.signal = sig
+	}
+}
+
+// WithGracePeriod yields an Opt that overrides the duration for which the
+// process is given to terminate after being signaled before it is killed. The
+// default is ten seconds.
+func WithGracePeriod(d time.Duration) Opt {
+	return func(cfg *config) {
+		cfg.grace = d
+	}
+}
+
+// Spawn starts the supplied command and registers a Reaper with the supplied
+// Scope that terminates it gracefully: the process is sent the stop signal (or,
+// if supervised, asked to exit; see WithSupervision) and given the grace
+// period (or until the Exit context is done, if sooner) to exit, after which
+// it is killed and the Reaper returns an error saying why. If the process
+// exits of its own accord before the Scope exits, an error matching
+// ErrUnexpectedExit is reported via Scope.OfferErr, so it is dropped if the
+// Scope's error channel is not ready to receive it. An error is returned if
+// the command fails to start.
+func Spawn(ctx context.Context, s nls.Lifetime, cmd *exec.Cmd, opts ...Opt) error {
+	cfg := config{signal: syscall.SIGTERM, grace: 10 * time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return s.Spawn(ctx, func(context.Context) (nls.Reaper, error) {
+		sv, err := supervise(cmd, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			if sv != nil {
+				sv.started()
+				sv.close()
+			}
+			return nil, err
+		}
+		var stopping atomic.Bool
+		monitored := make(chan struct{})
+		if sv != nil {
+			sv.started()
+			go func() {
+				defer close(monitored)
+				sv.monitor(s, cmd.Process.Pid, cfg.heartbeat, stopping.Load)
+			}()
+		} else {
+			close(monitored)
+		}
+		exited := make(chan struct{})
+		go func() {
+			err := cmd.Wait()
+			if sv != nil {
+				<-monitored
+				sv.close()
+			}
+			close(exited)
+			if !stopping.Load() {
+				s.OfferErr(unexpectedExit(cmd, err))
+			}
+		}()
+		return func(ctx context.Context) error {
+			stopping.Store(true)
+			select {
+			case <-exited:
+				return sv.exitErr(cmd.Process.Pid)
+			default:
+			}
+			reason := errors.New("did not stop within grace period")
+			if err := stop(cmd, sv, cfg); err != nil {
+				reason = fmt.Errorf("could not be asked to stop: %w", err)
+			} else {
+				timer := nls.ClockOf(s).NewTimer(cfg.grace)
+				defer timer.Stop()
+				select {
+				case <-exited:
+					return sv.exitErr(cmd.Process.Pid)
+				case <-timer.C():
+				case <-ctx.Done():
+					reason = fmt.Errorf("exit canceled before it stopped: %w", ctx.Err())
+				}
+			}
+			if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				return err
+			}
+			<-exited
+			return fmt.Errorf("process %d killed: %w", cmd.Process.Pid, reason)
+		}, nil
+	}, nls.WithResource(nls.ResourceProcess))
+}
+
+// stop asks the process to stop gracefully: via the supervision protocol if it
+// is supervised, otherwise with the stop signal.
+func stop(cmd *exec.Cmd, sv *supervisor, cfg config) error {
+	if sv != nil {
+		return sv.requestExit()
+	}
+	return cmd.Process.Signal(cfg.signal)
+}
+
+func unexpectedExit(cmd *exec.Cmd, err error) error {
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrUnexpectedExit, cmd.Path)
+	}
+	return fmt.Errorf("%w: %s: %w", ErrUnexpectedExit, cmd.Path, err)
+}
@@ -0,0 +1,84 @@
+package nlsexec_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlsexec"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+func lookPath(t *testing.T, name string) string {
+	t.Helper()
+	path, err := exec.LookPath(name)
+	if err != nil {
+		t.Skipf("%s not available: %v", name, err)
+	}
+	return path
+}
+
+func TestSpawnTerminatesOnExit(t *testing.T) {
+	sleep := lookPath(t, "sleep")
+	s := nls.NewScope()
+	cmd := exec.Command(sleep, "60")
+	err := nlsexec.Spawn(context.TODO(), s, cmd)
+	require(t, err == nil, "unexpected error: %q", err)
+
+	err = s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) {
+		t.Errorf("unexpected reap error: %q", err)
+	}))
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	require(t, cmd.ProcessState != nil && !cmd.ProcessState.Exited(),
+		"expected process to have been terminated by a signal")
+}
+
+func TestSpawnKilledOnCanceledExit(t *testing.T) {
+	sleep := lookPath(t, "sleep")
+	s := nls.NewScope()
+	cmd := exec.Command(sleep, "60")
+	err := nlsexec.Spawn(context.TODO(), s, cmd,
+		nlsexec.WithStopSignal(syscall.Signal(0)), nlsexec.WithGracePeriod(time.Minute))
+	require(t, err == nil, "unexpected error: %q", err)
+
+	var errs []error
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s.Exit(ctx, nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require(t, len(errs) == 1 && errors.Is(errs[0], context.DeadlineExceeded) &&
+		!strings.Contains(errs[0].Error(), "grace period"),
+		"expected the kill to be blamed on the exit context, got %v", errs)
+}
+
+func TestSpawnReportsUnexpectedExit(t *testing.T) {
+	truecmd := lookPath(t, "true")
+	s := nls.NewScope(nls.WithErrorChan(make(chan error, 1)))
+	defer s.Exit(context.TODO())
+	err := nlsexec.Spawn(context.TODO(), s, exec.Command(truecmd))
+	require(t, err == nil, "unexpected error: %q", err)
+
+	select {
+	case err := <-s.Err():
+		require(t, errors.Is(err, nlsexec.ErrUnexpectedExit),
+			"expected ErrUnexpectedExit, got %q", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected unexpected exit to be reported")
+	}
+}
+
+func TestSpawnStartError(t *testing.T) {
+	s := nls.NewScope()
+	err := nlsexec.Spawn(context.TODO(), s, exec.Command("/nonexistent/nls/binary"))
+	require(t, err != nil, "expected error starting missing binary")
+}
@@ -0,0 +1,233 @@
+package nlsexec
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+// SupervisorEnv is the environment variable through which a supervised process
+// learns of the pipes connecting it to its supervisor (see WithSupervision and
+// Supervise). Its value is "<read fd>,<write fd>,<heartbeat interval>".
+const SupervisorEnv = "NLS_SUPERVISOR"
+
+// Messages of the supervision protocol, each sent as a single line. The
+// supervisor sends msgExit to request that the process exit its root Scope;
+// the process sends msgAlive every heartbeat interval and, once its root
+// Scope has exited, msgExited followed by the Exit's error, if any.
+const (
+	msgAlive  = "alive"
+	msgExit   = "exit"
+	msgExited = "exited"
+)
+
+// ErrUnresponsive is matched (via errors.Is) by the error reported to a Scope
+// when a supervised process (see WithSupervision) misses three consecutive
+// heartbeats.
+var ErrUnresponsive = errors.New("supervised process unresponsive")
+
+// WithSupervision yields an Opt that connects the process to the supervising
+// Scope with a pair of pipes, which the process must accept by calling
+// Supervise with its root Scope. Rather than being signaled, the process is
+// then asked to exit its root Scope when the supervising Scope exits, so that
+// its teardown proceeds gracefully and any error from it is returned by the
+// Reaper; it is still killed should it not exit within the grace period. The
+// process sends a heartbeat at the supplied interval; should three in a row
+// go missing, an error matching ErrUnresponsive is reported via
+// Scope.OfferErr, which drops it if nothing is ready to receive it.
+// Supervision relies on exec.Cmd.ExtraFiles and so is not supported on
+// Windows.
+func WithSupervision(heartbeat time.Duration) Opt {
+	return func(cfg *config) {
+		cfg.heartbeat = heartbeat
+	}
+}
+
+// supervisor is the parent's end of the supervision protocol.
+type supervisor struct {
+	toChild   *os.File
+	fromChild *os.File
+	childEnds []*os.File
+	mu        sync.Mutex
+	result    string // the error sent with msgExited, if any
+}
+
+// supervise prepares cmd for supervision, returning nil if it has not been
+// requested.
+func supervise(cmd *exec.Cmd, cfg config) (*supervisor, error) {
+	if cfg.heartbeat <= 0 {
+		return nil, nil
+	}
+	childR, toChild, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	fromChild, childW, err := os.Pipe()
+	if err != nil {
+		childR.Close()
+		toChild.Close()
+		return nil, err
+	}
+	fd := 3 + len(cmd.ExtraFiles)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, childR, childW)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d,%d,%v", SupervisorEnv, fd, fd+1, cfg.heartbeat))
+	return &supervisor{
+		toChild:   toChild,
+		fromChild: fromChild,
+		childEnds: []*os.File{childR, childW},
+	}, nil
+}
+
+// started closes the child's ends of the pipes once it has inherited them.
+func (sv *supervisor) started() {
+	for _, f := range sv.childEnds {
+		f.Close()
+	}
+}
+
+// close releases the parent's ends of the pipes.
+func (sv *supervisor) close() {
+	sv.toChild.Close()
+	sv.fromChild.Close()
+}
+
+// monitor reads messages from the child until it closes its end of the pipe,
+// reporting ErrUnresponsive via s.OfferErr each time three heartbeats go
+// missing while stopping returns false.
+func (sv *supervisor) monitor(s nls.Lifetime, pid int, heartbeat time.Duration, stopping func() bool) {
+	beats := make(chan struct{}, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		clock := nls.ClockOf(s)
+		for {
+			timer := clock.NewTimer(3 * heartbeat)
+			select {
+			case <-beats:
+			case <-timer.C():
+				if !stopping() {
+					s.OfferErr(fmt.Errorf("%w: process %d", ErrUnresponsive, pid))
+				}
+			case <-done:
+				timer.Stop()
+				return
+			}
+			timer.Stop()
+		}
+	}()
+	sc := bufio.NewScanner(sv.fromChild)
+	for sc.Scan() {
+		msg, arg, _ := strings.Cut(sc.Text(), " ")
+		switch msg {
+		case msgAlive:
+			select {
+			case beats <- struct{}{}:
+			default:
+			}
+		case msgExited:
+			sv.mu.Lock()
+			sv.result = arg
+			sv.mu.Unlock()
+		}
+	}
+}
+
+// requestExit asks the child to exit its root Scope.
+func (sv *supervisor) requestExit() error {
+	_, err := io.WriteString(sv.toChild, msgExit+"\n")
+	return err
+}
+
+// exitErr returns the error the child reported for its root Scope's Exit.
+func (sv *supervisor) exitErr(pid int) error {
+	if sv == nil {
+		return nil
+	}
+	sv.mu.Lock()
+	defer sv.mu.Unlock()
+	if sv.result == "" {
+		return nil
+	}
+	return fmt.Errorf("process %d exit: %s", pid, sv.result)
+}
+
+// Supervise connects the supplied root Scope of this process to the
+// supervising Scope of its parent if the process was started by Spawn with
+// WithSupervision. The Scope is then exited when the supervisor asks, or
+// should the supervisor's process die, and its Exit's error is relayed to the
+// supervisor. Heartbeats are sent until the Scope exits. The returned func
+// blocks until the Scope has exited and, if supervised, its outcome has been
+// relayed, so that the process may then terminate; main typically calls it
+// in place of Scope.Join.
+func Supervise(s *nls.Scope) (wait func(), err error) {
+	relayed := make(chan struct{})
+	wait = func() {
+		<-s.Done()
+		<-relayed
+	}
+	env, ok := os.LookupEnv(SupervisorEnv)
+	if !ok {
+		close(relayed)
+		return wait, nil
+	}
+	parts := strings.Split(env, ",")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed %s %q", SupervisorEnv, env)
+	}
+	rfd, rerr := strconv.Atoi(parts[0])
+	wfd, werr := strconv.Atoi(parts[1])
+	heartbeat, herr := time.ParseDuration(parts[2])
+	if err := errors.Join(rerr, werr, herr); err != nil {
+		return nil, fmt.Errorf("malformed %s %q: %w", SupervisorEnv, env, err)
+	}
+	closeOnExec(rfd)
+	closeOnExec(wfd)
+	in := os.NewFile(uintptr(rfd), "supervisor-in")
+	out := os.NewFile(uintptr(wfd), "supervisor-out")
+	if in == nil || out == nil {
+		return nil, fmt.Errorf("invalid %s %q", SupervisorEnv, env)
+	}
+
+	go func() {
+		defer in.Close()
+		sc := bufio.NewScanner(in)
+		for sc.Scan() && sc.Text() != msgExit {
+		}
+		// asked to exit, or the supervisor has gone away
+		s.Exit(context.Background())
+	}()
+	go func() {
+		defer close(relayed)
+		defer out.Close()
+		ticker := nls.ClockOf(s).NewTicker(heartbeat)
+		defer ticker.Stop()
+		io.WriteString(out, msgAlive+"\n")
+		for {
+			select {
+			case <-ticker.C():
+				io.WriteString(out, msgAlive+"\n")
+			case <-s.Done():
+				msg := msgExited
+				if err := s.ExitErr(); err != nil {
+					msg += " " + strings.ReplaceAll(err.Error(), "\n", "; ")
+				}
+				io.WriteString(out, msg+"\n")
+				return
+			}
+		}
+	}()
+	return wait, nil
+}
@@ -0,0 +1,5 @@
+//go:build !unix
+
+package nlsexec
+
+func closeOnExec(int) {}
@@ -0,0 +1,85 @@
+package nlsexec_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlsexec"
+)
+
+const helperEnv = "NLSEXEC_SUPERVISED_HELPER"
+
+// TestSupervisedHelper is not a real test: it is the supervised process run
+// by TestSupervision.
+func TestSupervisedHelper(t *testing.T) {
+	if os.Getenv(helperEnv) == "" {
+		t.Skip("helper process for TestSupervision")
+	}
+	s := nls.NewScope()
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return errors.New("helper teardown failed") }, nil
+	})
+	wait, err := nlsexec.Supervise(s)
+	if err != nil {
+		os.Exit(2)
+	}
+	wait()
+	os.Exit(0)
+}
+
+func TestSupervision(t *testing.T) {
+	s := nls.NewScope()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSupervisedHelper$")
+	cmd.Env = append(os.Environ(), helperEnv+"=1")
+	err := nlsexec.Spawn(context.TODO(), s, cmd, nlsexec.WithSupervision(20*time.Millisecond))
+	require(t, err == nil, "unexpected spawn error %v", err)
+	time.Sleep(100 * time.Millisecond) // a few heartbeats
+	select {
+	case err := <-s.Err():
+		t.Fatalf("unexpected error reported %v", err)
+	default:
+	}
+
+	var errs []error
+	err = s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require(t, err == nil, "unexpected exit error %v", err)
+	require(t, cmd.ProcessState != nil && cmd.ProcessState.ExitCode() == 0,
+		"expected process to exit of its own accord, got %v", cmd.ProcessState)
+	require(t, len(errs) == 1 && strings.Contains(errs[0].Error(), "helper teardown failed"),
+		"expected child exit error to be relayed, got %v", errs)
+}
+
+func TestSupervisionUnresponsive(t *testing.T) {
+	sleep := lookPath(t, "sleep")
+	s := nls.NewScope(nls.WithErrorChan(make(chan error, 1)))
+	cmd := exec.Command(sleep, "60")
+	err := nlsexec.Spawn(context.TODO(), s, cmd,
+		nlsexec.WithSupervision(10*time.Millisecond), nlsexec.WithGracePeriod(10*time.Millisecond))
+	require(t, err == nil, "unexpected spawn error %v", err)
+	select {
+	case err := <-s.Err():
+		require(t, errors.Is(err, nlsexec.ErrUnresponsive), "expected ErrUnresponsive, got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected missed heartbeats to be reported")
+	}
+	s.Exit(context.TODO(), nls.WithErrorHandler(func(error) {}))
+	require(t, cmd.ProcessState != nil && !cmd.ProcessState.Exited(),
+		"expected unsupervised process to be killed")
+}
+
+func TestSuperviseUnsupervised(t *testing.T) {
+	if os.Getenv(nlsexec.SupervisorEnv) != "" {
+		t.Skip("running supervised")
+	}
+	s := nls.NewScope()
+	wait, err := nlsexec.Supervise(s)
+	require(t, err == nil, "unexpected error %v", err)
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	wait()
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package nlsexec
+
+import "syscall"
+
+// closeOnExec stops the supplied inherited descriptor leaking into processes
+// started by the supervised process, which would keep its pipe open.
+func closeOnExec(fd int) {
+	syscall.CloseOnExec(fd)
+}
@@ -0,0 +1,35 @@
+// Package nlsexpvar publishes nls.Stats via the expvar package so that they
+// appear under /debug/vars alongside the process's other expvars.
+package nlsexpvar
+
+import (
+	"expvar"
+	"sync"
+
+	"github.com/mmcshane/nls"
+)
+
+var (
+	mu        sync.Mutex
+	published = make(map[string]*nls.Stats)
+)
+
+// Publish returns the nls.Stats published as an expvar under the supplied
+// name, publishing a new one on first use, e.g.
+//
+//	root := nls.NewScope(nls.WithStats(nlsexpvar.Publish("nls")))
+//
+// The name must not be used by expvars published by other means.
+func Publish(name string) *nls.Stats {
+	mu.Lock()
+	defer mu.Unlock()
+	st, ok := published[name]
+	if !ok {
+		st = &nls.Stats{}
+		expvar.Publish(name, expvar.Func(func() interface{} {
+			return st.Values()
+		}))
+		published[name] = st
+	}
+	return st
+}
@@ -0,0 +1,46 @@
+package nlsexpvar_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"testing"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlsexpvar"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+func TestPublish(t *testing.T) {
+	st := nlsexpvar.Publish("nlstest")
+	require(t, nlsexpvar.Publish("nlstest") == st, "expected stats to be shared by name")
+
+	root := nls.NewScope(nls.WithStats(st))
+	child := root.NewChildScope()
+	for i := 0; i < 2; i++ {
+		nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error { return errors.New("failed") }, nil
+		})
+	}
+	nls.MustSpawn(context.TODO(), root, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	})
+
+	var vals map[string]int64
+	err := json.Unmarshal([]byte(expvar.Get("nlstest").String()), &vals)
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, vals["scopesCreated"] == 2 && vals["reapersPending"] == 3,
+		"unexpected stats %v", vals)
+
+	root.Exit(context.TODO())
+	vals = st.Values()
+	require(t, vals["scopesExited"] == 2 && vals["reapersPending"] == 0 &&
+		vals["reapErrors"] == 2, "unexpected stats %v", vals)
+}
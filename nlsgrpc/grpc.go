@@ -0,0 +1,120 @@
+// Package nlsgrpc manages the lifetime of gRPC servers and client connections
+// with an nls.Scope.
+//
+// To avoid imposing a dependency on google.golang.org/grpc, the functions in
+// this package accept small interfaces that *grpc.Server and
+// *grpc.ClientConn satisfy.
+package nlsgrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+// Server is the subset of the *grpc.Server API used by Serve.
+type Server interface {
+	Serve(net.Listener) error
+	GracefulStop()
+	Stop()
+}
+
+// ClientConn is the subset of the *grpc.ClientConn API used by AdoptClientConn.
+type ClientConn interface {
+	Close() error
+}
+
+// ErrServerStopped is matched (via errors.Is) by the error reported to a Scope
+// when a Server that it manages stops serving before the Scope exits.
+var ErrServerStopped = errors.New("grpc server stopped unexpectedly")
+
+// ErrForcedStop is matched (via errors.Is) by the error returned from the
+// Reaper registered by Serve when the Server had to be stopped forcibly.
+var ErrForcedStop = errors.New("grpc server stopped forcibly")
+
+type config struct {
+	grace time.Duration
+}
+
+// Opt is a type for optional parameters to Serve.
+type Opt func(*config)
+
+// WithGracePeriod yields an Opt that bounds how long the Server is given to
+// finish in-flight RPCs via GracefulStop before it is forcibly stopped. The
+// Exit context's deadline, if sooner, also applies. The default is ten
+// seconds.
+func WithGracePeriod(d time.Duration) Opt {
+	return func(cfg *config) {
+		cfg.grace = d
+	}
+}
+
+// Serve runs the supplied Server on the supplied net.Listener on a new
+// goroutine and registers a Reaper with the supplied Scope that stops it: the
+// Server is first stopped gracefully and then, if in-flight RPCs have not
+// completed within the grace period or the Exit context is done, forcibly, in
+// which case the Reaper returns an error matching ErrForcedStop that, if the
+// context was done, also wraps the context's error. If the Server stops
+// serving before the Scope exits, an error matching ErrServerStopped is
+// reported via Scope.OfferErr, so it is dropped if nothing is ready to
+// receive it.
+func Serve(ctx context.Context, s nls.Lifetime, srv Server, l net.Listener, opts ...Opt) error {
+	cfg := config{grace: 10 * time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return s.Spawn(ctx, func(context.Context) (nls.Reaper, error) {
+		var stopping atomic.Bool
+		served := make(chan struct{})
+		go func() {
+			err := srv.Serve(l)
+			close(served)
+			if !stopping.Load() {
+				s.OfferErr(serverStopped(err))
+			}
+		}()
+		return func(ctx context.Context) error {
+			stopping.Store(true)
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+			timer := nls.ClockOf(s).NewTimer(cfg.grace)
+			defer timer.Stop()
+			var err error
+			select {
+			case <-stopped:
+			case <-timer.C():
+				srv.Stop()
+				err = fmt.Errorf("%w: grace period of %v elapsed", ErrForcedStop, cfg.grace)
+			case <-ctx.Done():
+				srv.Stop()
+				err = fmt.Errorf("%w: %w", ErrForcedStop, ctx.Err())
+			}
+			<-stopped
+			<-served
+			return err
+		}, nil
+	})
+}
+
+func serverStopped(err error) error {
+	if err == nil {
+		return ErrServerStopped
+	}
+	return fmt.Errorf("%w: %w", ErrServerStopped, err)
+}
+
+// AdoptClientConn transfers ownership of the supplied ClientConn to the
+// supplied Scope, which will close it on Exit.
+func AdoptClientConn(s nls.Lifetime, cc ClientConn) error {
+	return s.Spawn(context.Background(), func(context.Context) (nls.Reaper, error) {
+		return nls.ReaperFromFunc(cc.Close), nil
+	})
+}
@@ -0,0 +1,130 @@
+package nlsgrpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlsgrpc"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+// fakeServer mimics the stop semantics of *grpc.Server: GracefulStop blocks
+// until in-flight RPCs finish unless Stop is called.
+type fakeServer struct {
+	mu       sync.Mutex
+	inflight chan struct{}
+	stop     chan struct{}
+	once     sync.Once
+	forced   bool
+	serveErr error
+}
+
+func newFakeServer(stuck bool) *fakeServer {
+	srv := &fakeServer{inflight: make(chan struct{}), stop: make(chan struct{})}
+	if !stuck {
+		close(srv.inflight)
+	}
+	return srv
+}
+
+func (f *fakeServer) Serve(net.Listener) error {
+	<-f.stop
+	return f.serveErr
+}
+
+func (f *fakeServer) GracefulStop() {
+	select {
+	case <-f.inflight:
+	case <-f.stop:
+	}
+	f.once.Do(func() { close(f.stop) })
+}
+
+func (f *fakeServer) Stop() {
+	f.mu.Lock()
+	f.forced = true
+	f.mu.Unlock()
+	f.once.Do(func() { close(f.stop) })
+}
+
+func (f *fakeServer) wasForced() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.forced
+}
+
+func TestServeGracefulStop(t *testing.T) {
+	s := nls.NewScope()
+	srv := newFakeServer(false)
+	err := nlsgrpc.Serve(context.TODO(), s, srv, nil)
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, !srv.wasForced(), "expected graceful stop")
+}
+
+func TestServeForcedStop(t *testing.T) {
+	s := nls.NewScope()
+	srv := newFakeServer(true)
+	err := nlsgrpc.Serve(context.TODO(), s, srv, nil,
+		nlsgrpc.WithGracePeriod(time.Millisecond))
+	require(t, err == nil, "unexpected error: %q", err)
+	var errs []error
+	err = s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require(t, err == nil, "unexpected exit error")
+	require(t, srv.wasForced(), "expected forced stop after grace period")
+	require(t, len(errs) == 1 && errors.Is(errs[0], nlsgrpc.ErrForcedStop),
+		"expected ErrForcedStop, got %v", errs)
+
+	s = nls.NewScope()
+	srv = newFakeServer(true)
+	err = nlsgrpc.Serve(context.TODO(), s, srv, nil)
+	require(t, err == nil, "unexpected error: %q", err)
+	errs = nil
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	s.Exit(ctx, nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require(t, srv.wasForced(), "expected forced stop once the exit context is done")
+	require(t, len(errs) == 1 && errors.Is(errs[0], nlsgrpc.ErrForcedStop) &&
+		errors.Is(errs[0], context.DeadlineExceeded),
+		"expected ErrForcedStop wrapping the context error, got %v", errs)
+}
+
+func TestServeUnexpectedStop(t *testing.T) {
+	s := nls.NewScope(nls.WithErrorChan(make(chan error, 1)))
+	defer s.Exit(context.TODO())
+	srv := newFakeServer(false)
+	srv.serveErr = errors.New("listener failed")
+	err := nlsgrpc.Serve(context.TODO(), s, srv, nil)
+	require(t, err == nil, "unexpected error: %q", err)
+	srv.Stop()
+	select {
+	case err := <-s.Err():
+		require(t, errors.Is(err, nlsgrpc.ErrServerStopped),
+			"expected ErrServerStopped, got %q", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected unexpected stop to be reported")
+	}
+}
+
+type fakeConn struct{ closed bool }
+
+func (c *fakeConn) Close() error { c.closed = true; return nil }
+
+func TestAdoptClientConn(t *testing.T) {
+	s := nls.NewScope()
+	cc := &fakeConn{}
+	require(t, nlsgrpc.AdoptClientConn(s, cc) == nil, "unexpected adopt error")
+	s.Exit(context.TODO())
+	require(t, cc.closed, "expected client conn to be closed")
+}
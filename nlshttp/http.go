@@ -0,0 +1,83 @@
+// Package nlshttp manages the lifetime of HTTP request resources with
+// nls.Scopes.
+package nlshttp
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+type config struct {
+	drain   time.Duration
+	onError func(*http.Request, error)
+}
+
+// Opt is a type for optional parameters to Middleware.
+type Opt func(*config)
+
+// WithDrainTimeout yields an Opt that bounds the Exit of each request Scope.
+// The default is five seconds.
+func WithDrainTimeout(d time.Duration) Opt {
+	return func(cfg *config) {
+		cfg.drain = d
+	}
+}
+
+// WithErrorHandler yields an Opt that supplies a func to receive the errors
+// encountered creating or exiting a request Scope, together with the request.
+// By default they are dropped.
+func WithErrorHandler(fn func(*http.Request, error)) Opt {
+	return func(cfg *config) {
+		cfg.onError = fn
+	}
+}
+
+// Middleware returns HTTP middleware that creates a child of the supplied
+// Scope for each request, makes it available to the handler via the request
+// context (see nls.FromContext) and exits it once the handler returns, or
+// panics, so that resources spawned into it are released with the request.
+// The Exit context carries the values, but not the cancelation, of the request
+// context. If the child cannot be created, e.g. because the parent is exiting,
+// the request is answered with 503 Service Unavailable and the error is
+// passed to the error handler (see WithErrorHandler), e.g.
+//
+//	http.Handle("/", nlshttp.Middleware(root)(handler))
+func Middleware(parent *nls.Scope, opts ...Opt) func(http.Handler) http.Handler {
+	cfg := config{drain: 5 * time.Second, onError: func(*http.Request, error) {}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s, err := parent.NewChildScopeErr(nls.WithName("request"))
+			if err != nil {
+				cfg.onError(r, err)
+				http.Error(w, http.StatusText(http.StatusServiceUnavailable),
+					http.StatusServiceUnavailable)
+				return
+			}
+			defer func() {
+				ctx, cancel := nls.ClockTimeout(detached{r.Context()}, s.Clock(), cfg.drain)
+				defer cancel()
+				onError := nls.WithErrorHandler(func(err error) { cfg.onError(r, err) })
+				if err := s.Exit(ctx, onError); err != nil {
+					cfg.onError(r, err)
+				}
+			}()
+			next.ServeHTTP(w, r.WithContext(nls.NewContext(r.Context(), s)))
+		})
+	}
+}
+
+// detached carries the values, but not the deadline or cancelation, of the
+// context it wraps.
+type detached struct{ context.Context }
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+func (detached) Done() <-chan struct{} { return nil }
+
+func (detached) Err() error { return nil }
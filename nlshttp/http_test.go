@@ -0,0 +1,79 @@
+package nlshttp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlshttp"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	root := nls.NewScope(nls.WithName("server"))
+	defer root.Exit(context.TODO())
+	var errs []error
+	mw := nlshttp.Middleware(root, nlshttp.WithErrorHandler(func(_ *http.Request, err error) {
+		errs = append(errs, err)
+	}))
+
+	var reaped, canceled bool
+	var path string
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := nls.FromContext(r.Context())
+		require(t, ok, "expected request scope in context")
+		path = s.Path()
+		nls.MustSpawn(r.Context(), s, func(context.Context) (nls.Reaper, error) {
+			return func(ctx context.Context) error {
+				reaped, canceled = true, ctx.Err() != nil
+				return errors.New("close failed")
+			}, nil
+		})
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // client went away
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	require(t, path == "server/request", "unexpected scope path %q", path)
+	require(t, reaped && !canceled, "expected request scope to be exited with a live context")
+	require(t, len(errs) == 1 && errs[0].Error() == "close failed", "unexpected errors %v", errs)
+	require(t, root.Snapshot().TotalReapers() == 0 && len(root.Snapshot().Children) == 0,
+		"expected request scope to be gone")
+}
+
+func TestMiddlewareExitingParent(t *testing.T) {
+	root := nls.NewScope()
+	var errs []error
+	mw := nlshttp.Middleware(root, nlshttp.WithErrorHandler(func(_ *http.Request, err error) {
+		errs = append(errs, err)
+	}))
+	h := mw(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Error("expected handler not to be called")
+	}))
+	rec := httptest.NewRecorder()
+	served := make(chan struct{})
+	release := make(chan struct{})
+	nls.MustSpawn(context.TODO(), root, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			h.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+			close(served)
+			<-release
+			return nil
+		}, nil
+	})
+	done := root.ExitAsync(context.TODO())
+	<-served
+	require(t, rec.Code == http.StatusServiceUnavailable, "unexpected status %d", rec.Code)
+	require(t, len(errs) == 1 && errors.Is(errs[0], nls.ErrExiting), "unexpected errors %v", errs)
+	close(release)
+	require(t, <-done == nil, "unexpected exit error")
+}
@@ -0,0 +1,132 @@
+// Package nlsnet manages the lifetime of network listeners and connections
+// with an nls.Scope.
+package nlsnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+
+	"github.com/mmcshane/nls"
+)
+
+// Listen announces on the supplied network address (see net.Listen) and
+// adopts the resulting listener into the supplied Scope as per AdoptListener.
+func Listen(ctx context.Context, s nls.Lifetime, network, address string) (net.Listener, error) {
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	tl, err := AdoptListener(s, l)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return tl, nil
+}
+
+// AdoptListener transfers ownership of the supplied net.Listener to the
+// supplied Scope and returns a wrapping net.Listener that tracks every
+// connection that it accepts. When the Scope exits the listener is closed
+// first, unblocking any goroutine blocked in Accept, and then every tracked
+// connection that has not already been closed is closed. If an error is
+// returned then ownership remains with the caller. The listener counts as an
+// nls.ResourceSocket.
+func AdoptListener(s nls.Lifetime, l net.Listener) (net.Listener, error) {
+	tl := &listener{Listener: l, conns: make(map[*conn]struct{})}
+	err := s.Spawn(context.Background(), func(context.Context) (nls.Reaper, error) {
+		return nls.ReaperFromFunc(tl.shutdown), nil
+	}, nls.WithResource(nls.ResourceSocket))
+	if err != nil {
+		return nil, err
+	}
+	return tl, nil
+}
+
+// Dial connects to the supplied network address (see net.Dialer.DialContext)
+// and adopts the resulting connection into the supplied Scope as per
+// AdoptConn.
+func Dial(ctx context.Context, s nls.Lifetime, network, address string) (net.Conn, error) {
+	var d net.Dialer
+	c, err := d.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := AdoptConn(s, c); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// AdoptConn transfers ownership of the supplied net.Conn to the supplied Scope,
+// which will close it on Exit unless it has already been closed. The
+// connection counts as an nls.ResourceSocket until then.
+func AdoptConn(s nls.Lifetime, c net.Conn) error {
+	return s.Spawn(context.Background(), func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return ignoreClosed(c.Close()) }, nil
+	}, nls.WithResource(nls.ResourceSocket))
+}
+
+type listener struct {
+	net.Listener
+	mu     sync.Mutex
+	conns  map[*conn]struct{}
+	closed bool
+}
+
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		c.Close()
+		return nil, net.ErrClosed
+	}
+	tc := &conn{Conn: c, l: l}
+	l.conns[tc] = struct{}{}
+	return tc, nil
+}
+
+func (l *listener) shutdown() error {
+	errs := []error{ignoreClosed(l.Listener.Close())}
+	l.mu.Lock()
+	l.closed = true
+	conns := l.conns
+	l.conns = nil
+	l.mu.Unlock()
+	for c := range conns {
+		errs = append(errs, ignoreClosed(c.Conn.Close()))
+	}
+	return errors.Join(errs...)
+}
+
+type conn struct {
+	net.Conn
+	l *listener
+}
+
+func (c *conn) Close() error {
+	c.l.mu.Lock()
+	delete(c.l.conns, c)
+	c.l.mu.Unlock()
+	return c.Conn.Close()
+}
+
+func ignoreClosed(err error) error {
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+func init() {
+	nls.RegisterAdapter(reflect.TypeOf((*net.Conn)(nil)).Elem(),
+		func(s nls.Lifetime, r interface{}) error { return AdoptConn(s, r.(net.Conn)) })
+}
@@ -0,0 +1,76 @@
+package nlsnet_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlsnet"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+func TestListenerShutdownOrder(t *testing.T) {
+	s := nls.NewScope()
+	l, err := nlsnet.Listen(context.TODO(), s, "tcp", "127.0.0.1:0")
+	require(t, err == nil, "unexpected error: %q", err)
+
+	accepted := make(chan net.Conn)
+	acceptErr := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			acceptErr <- err
+			return
+		}
+		accepted <- c
+		_, err = l.Accept()
+		acceptErr <- err
+	}()
+
+	client := nls.NewScope()
+	defer client.Exit(context.TODO())
+	cc, err := nlsnet.Dial(context.TODO(), client, "tcp", l.Addr().String())
+	require(t, err == nil, "unexpected error: %q", err)
+	sc := <-accepted
+
+	err = s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) {
+		t.Errorf("unexpected reap error: %q", err)
+	}))
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	require(t, errors.Is(<-acceptErr, net.ErrClosed),
+		"expected blocked Accept to be released")
+
+	_, err = sc.Read(make([]byte, 1))
+	require(t, err != nil, "expected server-side conn to be closed")
+	_, err = cc.Read(make([]byte, 1))
+	require(t, err == io.EOF, "expected client to observe close, got %q", err)
+}
+
+func TestClosedConnNotTracked(t *testing.T) {
+	s := nls.NewScope()
+	l, err := nlsnet.Listen(context.TODO(), s, "tcp", "127.0.0.1:0")
+	require(t, err == nil, "unexpected error: %q", err)
+	go func() {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err == nil {
+			defer c.Close()
+			io.Copy(io.Discard, c)
+		}
+	}()
+	sc, err := l.Accept()
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, sc.Close() == nil, "unexpected close error")
+	err = s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) {
+		t.Errorf("unexpected reap error: %q", err)
+	}))
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+}
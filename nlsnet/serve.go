@@ -0,0 +1,120 @@
+package nlsnet
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/mmcshane/nls"
+)
+
+// Handler serves a single connection accepted by Serve. The supplied Scope
+// is the connection's own Scope, which owns the connection, and the context
+// is canceled when that Scope exits.
+type Handler func(ctx context.Context, s *nls.Scope, c net.Conn)
+
+type config struct {
+	maxConns int
+}
+
+// Opt is a type for optional parameters to Serve.
+type Opt func(*config)
+
+// WithMaxConns yields an Opt that caps the number of connections handled at
+// once: Serve stops accepting while n connections are open. By default there
+// is no cap.
+func WithMaxConns(n int) Opt {
+	return func(cfg *config) {
+		cfg.maxConns = n
+	}
+}
+
+// Serve runs an Accept loop for the supplied net.Listener on a goroutine
+// managed by the supplied Scope. Each accepted connection is given its own
+// child Scope, named "conn", which owns the connection (see AdoptConn) and is
+// passed to the Handler on a new goroutine; the child Scope is exited when
+// the Handler returns or, failing that, by the exit of the parent. The
+// listener is closed when the parent exits, which stops the loop; it may also
+// be owned by the parent, e.g. via Listen. An Accept error other than that
+// of a closed listener stops the loop and is reported via Scope.OfferErr, so
+// it is dropped if nothing is ready to receive it. An error is returned if
+// the Scope has already exited.
+func Serve(s *nls.Scope, l net.Listener, handle Handler, opts ...Opt) error {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	var slots chan struct{}
+	if cfg.maxConns > 0 {
+		slots = make(chan struct{}, cfg.maxConns)
+	}
+	return s.Go(func(ctx context.Context) {
+		stopped := make(chan struct{})
+		defer close(stopped)
+		go func() {
+			select {
+			case <-ctx.Done():
+				l.Close()
+			case <-stopped:
+			}
+		}()
+		for {
+			if slots != nil {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			c, err := l.Accept()
+			if err != nil {
+				if ctx.Err() == nil && !errors.Is(err, net.ErrClosed) {
+					s.OfferErr(err)
+				}
+				return
+			}
+			serveConn(s, c, handle, slots)
+		}
+	})
+}
+
+// serveConn runs the Handler for the supplied connection in a new child of
+// Scope s, releasing a slot, if any, once the child has exited.
+func serveConn(s *nls.Scope, c net.Conn, handle Handler, slots chan struct{}) {
+	release := func() {
+		if slots != nil {
+			<-slots
+		}
+	}
+	cs, err := s.NewChildScopeErr(nls.WithName("conn"))
+	if err != nil {
+		c.Close()
+		release()
+		return
+	}
+	if err := AdoptConn(cs, c); err != nil {
+		c.Close()
+		cs.Exit(context.Background())
+		release()
+		return
+	}
+	err = s.Go(func(ctx context.Context) {
+		defer release()
+		defer cs.Exit(context.Background())
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		err := cs.Spawn(context.Background(), func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				cancel()
+				return nil
+			}, nil
+		})
+		if err == nil {
+			handle(ctx, cs, c)
+		}
+	})
+	if err != nil {
+		cs.Exit(context.Background())
+		release()
+	}
+}
@@ -0,0 +1,61 @@
+package nlsnet_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlsnet"
+)
+
+func TestServe(t *testing.T) {
+	s := nls.NewScope(nls.WithName("srv"))
+	l, err := nlsnet.Listen(context.TODO(), s, "tcp", "127.0.0.1:0")
+	require(t, err == nil, "unexpected error: %v", err)
+
+	handled := make(chan string)
+	release := make(chan struct{})
+	err = nlsnet.Serve(s, l, func(ctx context.Context, cs *nls.Scope, c net.Conn) {
+		handled <- cs.Path()
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+	}, nlsnet.WithMaxConns(1))
+	require(t, err == nil, "unexpected error: %v", err)
+
+	var conns []net.Conn
+	for i := 0; i < 2; i++ {
+		c, err := net.Dial("tcp", l.Addr().String())
+		require(t, err == nil, "unexpected error: %v", err)
+		defer c.Close()
+		conns = append(conns, c)
+	}
+	require(t, <-handled == "srv/conn", "expected connection handled in its own scope")
+	select {
+	case <-handled:
+		t.Fatal("expected second connection to wait for a slot")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release <- struct{}{}
+	<-handled
+
+	// the first connection was closed when its handler returned
+	conns[0].SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conns[0].Read(make([]byte, 1))
+	require(t, err != nil && !isTimeout(err), "expected first connection closed, got %v", err)
+
+	// exiting the server cancels the remaining handler and stops the loop
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, s.Wait(context.TODO()) == nil, "unexpected wait error")
+	conns[1].SetReadDeadline(time.Now().Add(time.Second))
+	_, err = conns[1].Read(make([]byte, 1))
+	require(t, err != nil && !isTimeout(err), "expected second connection closed, got %v", err)
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
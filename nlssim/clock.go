@@ -0,0 +1,166 @@
+// Package nlssim provides a simulated, manually advanced nls.Clock so that
+// the time-based behavior of Scopes (and of the programs built on them) can
+// be driven step by step in tests. It simulates time only: the order in which
+// goroutines woken by an Advance go on to run, and so the interleaving of
+// their channel operations, is left to the Go runtime. Tests that depend on a
+// particular interleaving should synchronize on the events they care about,
+// e.g. with Clock.BlockUntil.
+package nlssim
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+// Clock is an nls.Clock whose time only moves when Clock.Advance is called.
+// Timers and Tickers created from a Clock fire synchronously within Advance in
+// deadline order, ties being broken by creation order, so a given sequence of
+// calls always produces the same sequence of firings.
+type Clock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	seq     uint64
+	waiters []*waiter
+}
+
+type waiter struct {
+	clock  *Clock
+	ch     chan time.Time
+	at     time.Time
+	period time.Duration
+	seq    uint64
+}
+
+var _ nls.Clock = (*Clock)(nil)
+
+// New creates a Clock whose current time is the supplied time.
+func New(start time.Time) *Clock {
+	c := &Clock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the Clock's current simulated time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer creates a Timer that fires once the Clock has been advanced by at
+// least the supplied duration.
+func (c *Clock) NewTimer(d time.Duration) nls.Timer {
+	return c.add(d, 0)
+}
+
+// NewTicker creates a Ticker that fires every time the Clock is advanced past
+// a multiple of the supplied period.
+func (c *Clock) NewTicker(d time.Duration) nls.Ticker {
+	if d <= 0 {
+		panic("nlssim: non-positive interval for NewTicker")
+	}
+	return ticker{c.add(d, d)}
+}
+
+// Advance moves the Clock forward by the supplied duration, firing every
+// Timer and Ticker that falls due along the way.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	target := c.now.Add(d)
+	for len(c.waiters) > 0 && !c.waiters[0].at.After(target) {
+		w := c.waiters[0]
+		c.now = w.at
+		w.fire()
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+			c.sortLocked()
+		} else {
+			c.removeLocked(w)
+		}
+	}
+	c.now = target
+}
+
+// Pending returns the number of active Timers and Tickers.
+func (c *Clock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until at least n Timers and Tickers are active. It is
+// used to synchronize a test with goroutines that are expected to begin
+// waiting on the Clock.
+func (c *Clock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+func (c *Clock) add(d, period time.Duration) *waiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	w := &waiter{
+		clock:  c,
+		ch:     make(chan time.Time, 1),
+		at:     c.now.Add(d),
+		period: period,
+		seq:    c.seq,
+	}
+	if d <= 0 {
+		w.fire()
+		return w
+	}
+	c.waiters = append(c.waiters, w)
+	c.sortLocked()
+	c.cond.Broadcast()
+	return w
+}
+
+func (c *Clock) sortLocked() {
+	sort.Slice(c.waiters, func(i, j int) bool {
+		a, b := c.waiters[i], c.waiters[j]
+		if a.at.Equal(b.at) {
+			return a.seq < b.seq
+		}
+		return a.at.Before(b.at)
+	})
+}
+
+func (c *Clock) removeLocked(w *waiter) bool {
+	for i, candidate := range c.waiters {
+		if candidate == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+func (w *waiter) fire() {
+	select {
+	case w.ch <- w.clock.now:
+	default:
+	}
+}
+
+func (w *waiter) C() <-chan time.Time { return w.ch }
+
+func (w *waiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.removeLocked(w)
+}
+
+type ticker struct{ *waiter }
+
+func (t ticker) Stop() { t.waiter.Stop() }
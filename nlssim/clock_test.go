@@ -0,0 +1,132 @@
+package nlssim_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestTimerOrdering(t *testing.T) {
+	c := nlssim.New(epoch)
+	late := c.NewTimer(2 * time.Second)
+	early := c.NewTimer(time.Second)
+	stopped := c.NewTimer(time.Second)
+	require(t, stopped.Stop(), "expected Stop to report active timer")
+	require(t, c.Pending() == 2, "expected 2 pending timers")
+
+	c.Advance(1500 * time.Millisecond)
+	require(t, (<-early.C()).Equal(epoch.Add(time.Second)),
+		"expected early timer to fire at its deadline")
+	select {
+	case <-late.C():
+		t.Fatal("expected late timer not to have fired")
+	default:
+	}
+	c.Advance(time.Second)
+	<-late.C()
+	require(t, c.Now().Equal(epoch.Add(2500*time.Millisecond)),
+		"unexpected clock time %v", c.Now())
+}
+
+func TestSimulatedHeartbeat(t *testing.T) {
+	c := nlssim.New(epoch)
+	s := nls.NewScope(nls.WithClock(c))
+	beats := make(chan nls.Beat, 1)
+	err := nls.Heartbeat(s.NewChildScope(), time.Minute, func(b nls.Beat) {
+		beats <- b
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+
+	c.BlockUntil(1)
+	for i := 1; i <= 3; i++ {
+		c.Advance(time.Minute)
+		b := <-beats
+		require(t, b.Seq == uint64(i), "unexpected beat %+v", b)
+		require(t, b.Time.Equal(epoch.Add(time.Duration(i)*time.Minute)),
+			"unexpected beat time %v", b.Time)
+	}
+	go s.Exit(context.TODO())
+	require(t, (<-beats).Stopped, "expected final stopped beat")
+}
+
+func TestSimulatedJanitorBackoff(t *testing.T) {
+	c := nlssim.New(epoch)
+	s := nls.NewScope(nls.WithClock(c))
+	defer s.Exit(context.TODO())
+	final := make(chan nls.Disposition, 1)
+	j, err := nls.NewJanitor(s,
+		nls.WithRetryAttempts(3),
+		nls.WithRetryInterval(time.Second),
+		nls.WithDispositionHandler(func(d nls.Disposition) { final <- d }))
+	require(t, err == nil, "unexpected error: %q", err)
+
+	attempts := make(chan time.Time, 3)
+	err = j.Submit(func(context.Context) error {
+		attempts <- c.Now()
+		return errors.New("failed")
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+
+	require(t, (<-attempts).Equal(epoch), "expected immediate first attempt")
+	c.BlockUntil(1)
+	c.Advance(time.Second)
+	require(t, (<-attempts).Equal(epoch.Add(time.Second)),
+		"expected second attempt after initial interval")
+	c.BlockUntil(1)
+	c.Advance(2 * time.Second)
+	require(t, (<-attempts).Equal(epoch.Add(3*time.Second)),
+		"expected third attempt after doubled interval")
+	d := <-final
+	require(t, d.Outcome == nls.GaveUp && d.Attempts == 3,
+		"unexpected disposition %+v", d)
+}
+
+func TestSimulatedExitTimeout(t *testing.T) {
+	c := nlssim.New(epoch)
+	s := nls.NewScope(nls.WithClock(c), nls.WithExitTimeout(time.Minute))
+	deadline := make(chan time.Time, 1)
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			d, _ := ctx.Deadline()
+			deadline <- d
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil
+	})
+	done := s.ExitAsync(context.TODO())
+	require(t, (<-deadline).Equal(epoch.Add(time.Minute)),
+		"expected deadline measured by simulated clock")
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	err := <-done
+	require(t, errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+}
+
+func TestClockTimeout(t *testing.T) {
+	c := nlssim.New(epoch)
+	ctx, cancel := nls.ClockTimeout(context.Background(), c, time.Second)
+	defer cancel()
+	c.Advance(999 * time.Millisecond)
+	require(t, ctx.Err() == nil, "expected context to be live before its deadline")
+	c.Advance(time.Millisecond)
+	<-ctx.Done()
+	require(t, ctx.Err() == context.DeadlineExceeded, "unexpected error: %v", ctx.Err())
+
+	ctx, cancel = nls.ClockTimeout(context.Background(), c, time.Second)
+	cancel()
+	require(t, ctx.Err() == context.Canceled, "unexpected error: %v", ctx.Err())
+	require(t, c.Pending() == 0, "expected cancel to stop the timer")
+}
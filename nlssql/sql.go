@@ -0,0 +1,101 @@
+// Package nlssql manages the lifetime of database/sql resources with an
+// nls.Scope.
+package nlssql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+type config struct {
+	pingCtx  context.Context
+	interval time.Duration
+	report   func(sql.DBStats)
+}
+
+// Opt is a type for optional parameters to Adopt.
+type Opt func(*config)
+
+// WithPing yields an Opt that causes Adopt to verify the database connection
+// by pinging it with the supplied context before registering its Reaper.
+func WithPing(ctx context.Context) Opt {
+	return func(cfg *config) {
+		cfg.pingCtx = ctx
+	}
+}
+
+// WithStats yields an Opt that causes the pool statistics of the adopted
+// *sql.DB to be delivered to the supplied func every interval for as long as
+// the Scope is active, and one final time immediately before the pool is
+// closed.
+func WithStats(interval time.Duration, report func(sql.DBStats)) Opt {
+	return func(cfg *config) {
+		cfg.interval = interval
+		cfg.report = report
+	}
+}
+
+// Adopt transfers ownership of the supplied *sql.DB to the supplied Lifetime,
+// which will close it on Exit. If Adopt returns an error then ownership
+// remains with the caller.
+func Adopt(s nls.Lifetime, db *sql.DB, opts ...Opt) error {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	err := s.Spawn(context.Background(), func(context.Context) (nls.Reaper, error) {
+		if cfg.pingCtx != nil {
+			if err := db.PingContext(cfg.pingCtx); err != nil {
+				return nil, err
+			}
+		}
+		return nls.ReaperFromFunc(db.Close), nil
+	})
+	if err != nil || cfg.report == nil {
+		return err
+	}
+	return nls.Heartbeat(s, cfg.interval, func(nls.Beat) {
+		cfg.report(db.Stats())
+	})
+}
+
+// AdoptConn transfers ownership of the supplied *sql.Conn to the supplied
+// Scope, which will return it to its pool on Exit.
+func AdoptConn(s nls.Lifetime, conn *sql.Conn) error {
+	return s.Spawn(context.Background(), func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			if err := conn.Close(); !errors.Is(err, sql.ErrConnDone) {
+				return err
+			}
+			return nil
+		}, nil
+	})
+}
+
+// AdoptTx registers the supplied *sql.Tx with the supplied Scope such that the
+// transaction is rolled back on Exit unless it has already been committed or
+// rolled back.
+func AdoptTx(s nls.Lifetime, tx *sql.Tx) error {
+	return s.Spawn(context.Background(), func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			if err := tx.Rollback(); !errors.Is(err, sql.ErrTxDone) {
+				return err
+			}
+			return nil
+		}, nil
+	})
+}
+
+func init() {
+	nls.RegisterAdapter(reflect.TypeOf((*sql.DB)(nil)),
+		func(s nls.Lifetime, r interface{}) error { return Adopt(s, r.(*sql.DB)) })
+	nls.RegisterAdapter(reflect.TypeOf((*sql.Conn)(nil)),
+		func(s nls.Lifetime, r interface{}) error { return AdoptConn(s, r.(*sql.Conn)) })
+	nls.RegisterAdapter(reflect.TypeOf((*sql.Tx)(nil)),
+		func(s nls.Lifetime, r interface{}) error { return AdoptTx(s, r.(*sql.Tx)) })
+}
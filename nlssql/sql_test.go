@@ -0,0 +1,109 @@
+package nlssql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssql"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+var errPing = errors.New("ping failed")
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{failPing: name == "unreachable"}, nil
+}
+
+type fakeConn struct {
+	failPing bool
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+func (c *fakeConn) Ping(context.Context) error {
+	if c.failPing {
+		return errPing
+	}
+	return nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+var register sync.Once
+
+func open(t *testing.T, dsn string) *sql.DB {
+	t.Helper()
+	register.Do(func() { sql.Register("nlssqlfake", fakeDriver{}) })
+	db, err := sql.Open("nlssqlfake", dsn)
+	require(t, err == nil, "unexpected error: %q", err)
+	return db
+}
+
+func TestAdopt(t *testing.T) {
+	s := nls.NewScope()
+	db := open(t, "ok")
+	var mu sync.Mutex
+	reports := 0
+	err := nlssql.Adopt(s, db,
+		nlssql.WithPing(context.TODO()),
+		nlssql.WithStats(time.Hour, func(sql.DBStats) {
+			mu.Lock()
+			reports++
+			mu.Unlock()
+		}))
+	require(t, err == nil, "unexpected error: %q", err)
+
+	conn, err := db.Conn(context.TODO())
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, nlssql.AdoptConn(s, conn) == nil, "unexpected AdoptConn error")
+
+	tx, err := db.Begin()
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, nlssql.AdoptTx(s, tx) == nil, "unexpected AdoptTx error")
+	require(t, tx.Commit() == nil, "unexpected commit error")
+
+	err = s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) {
+		t.Errorf("unexpected reap error: %q", err)
+	}))
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	require(t, db.PingContext(context.TODO()) != nil, "expected db to be closed")
+	mu.Lock()
+	defer mu.Unlock()
+	require(t, reports == 1, "expected final stats report, got %d", reports)
+}
+
+func TestAdoptPingFailure(t *testing.T) {
+	s := nls.NewScope()
+	db := open(t, "unreachable")
+	defer db.Close()
+	err := nlssql.Adopt(s, db, nlssql.WithPing(context.TODO()))
+	require(t, errors.Is(err, errPing), "expected ping error, got %q", err)
+}
+
+func TestAuto(t *testing.T) {
+	s := nls.NewScope()
+	db := open(t, "ok")
+	require(t, nls.Auto(s, db) == nil, "expected *sql.DB adapter")
+	s.Exit(context.TODO())
+	require(t, db.PingContext(context.TODO()) != nil, "expected db to be closed")
+}
@@ -0,0 +1,112 @@
+package nlstest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+// ErrChaos is matched (via errors.Is) by the errors injected by Chaos.
+var ErrChaos = errors.New("nlstest: injected reaper failure")
+
+type chaos struct {
+	mu       sync.Mutex
+	rand     *rand.Rand
+	pDelay   float64
+	maxDelay time.Duration
+	pError   float64
+	pPanic   float64
+}
+
+// ChaosOpt is a type for optional parameters to Chaos.
+type ChaosOpt func(*chaos)
+
+// WithChaosDelay yields a ChaosOpt that delays each Reaper, with the supplied
+// probability, by a random duration of up to max before it runs. The delay
+// is measured by the Clock of the Scope to which Chaos is applied (see
+// nls.WithClock) and is cut short if the Reaper's context is done.
+func WithChaosDelay(p float64, max time.Duration) ChaosOpt {
+	return func(c *chaos) {
+		c.pDelay, c.maxDelay = p, max
+	}
+}
+
+// WithChaosError yields a ChaosOpt that causes each Reaper, with the
+// supplied probability, to return an error matching ErrChaos.
+func WithChaosError(p float64) ChaosOpt {
+	return func(c *chaos) {
+		c.pError = p
+	}
+}
+
+// WithChaosPanic yields a ChaosOpt that causes each Reaper, with the
+// supplied probability, to panic with an error matching ErrChaos. Scopes do
+// not recover panicking Reapers, so unless the application recovers it the
+// panic kills the process (and with it the whole test binary). It can only
+// be recovered by an interceptor registered ahead of Chaos, on an ancestor
+// Scope or earlier in the same option list, as later interceptors are nested
+// inside it (see nls.WithInterceptor).
+func WithChaosPanic(p float64) ChaosOpt {
+	return func(c *chaos) {
+		c.pPanic = p
+	}
+}
+
+// Chaos returns a ScopeOpt that injects faults, as configured by the supplied
+// options, into the Reapers spawned into the new Scope and its descendants so
+// that tests can check how an application copes with partial teardown
+// failures. Faults are drawn from a random source seeded with the supplied
+// seed so that a failing sequence can be reproduced, given the same order of
+// Reaper invocations. Injected errors and panics occur after the underlying
+// Reaper has run, so that resources are still released, e.g.
+//
+//	s := nlstest.Scope(t, nlstest.Chaos(seed, nlstest.WithChaosError(0.2)))
+func Chaos(seed int64, opts ...ChaosOpt) nls.ScopeOpt {
+	c := &chaos{rand: rand.New(rand.NewSource(seed))}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return func(s *nls.Scope) {
+		nls.WithInterceptor(nil, func(next nls.Reaper) nls.Reaper {
+			return c.wrap(s, next)
+		})(s)
+	}
+}
+
+func (c *chaos) wrap(s *nls.Scope, next nls.Reaper) nls.Reaper {
+	return func(ctx context.Context) error {
+		delay, fail, panics := c.draw()
+		if delay > 0 {
+			t := nls.ClockOf(s).NewTimer(delay)
+			select {
+			case <-t.C():
+			case <-ctx.Done():
+				t.Stop()
+			}
+		}
+		err := next(ctx)
+		switch {
+		case panics:
+			panic(fmt.Errorf("%w (panic)", ErrChaos))
+		case fail:
+			return errors.Join(err, ErrChaos)
+		}
+		return err
+	}
+}
+
+// draw decides the faults for a single Reaper invocation.
+func (c *chaos) draw() (delay time.Duration, fail, panics bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.rand.Float64() < c.pDelay && c.maxDelay > 0 {
+		delay = time.Duration(c.rand.Int63n(int64(c.maxDelay)))
+	}
+	u := c.rand.Float64()
+	return delay, u < c.pError, u >= c.pError && u < c.pError+c.pPanic
+}
@@ -0,0 +1,93 @@
+package nlstest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+	"github.com/mmcshane/nls/nlstest"
+)
+
+// chaosRun exits a Scope with n Reapers under Chaos with the supplied seed,
+// returning which Reapers failed and confirming that each of them ran.
+func chaosRun(t *testing.T, seed int64, n int) []bool {
+	s := nls.NewScope(nls.WithName("chaos"), nlstest.Chaos(seed,
+		nlstest.WithChaosError(0.5), nlstest.WithChaosDelay(0.5, time.Millisecond)))
+	ran := make([]bool, n)
+	for i := 0; i < n; i++ {
+		i := i
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				ran[i] = true
+				return fmt.Errorf("reaper %d", i)
+			}, nil
+		})
+	}
+	failed := make([]bool, n)
+	s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) {
+		if errors.Is(err, nlstest.ErrChaos) {
+			var i int
+			fmt.Sscanf(err.Error(), "reaper %d", &i)
+			failed[i] = true
+		}
+	}))
+	for i, ok := range ran {
+		require(t, ok, "expected reaper %d to run", i)
+	}
+	return failed
+}
+
+func TestChaosReproducible(t *testing.T) {
+	first := chaosRun(t, 42, 20)
+	require(t, fmt.Sprint(chaosRun(t, 42, 20)) == fmt.Sprint(first),
+		"expected the same seed to inject the same faults")
+	n := 0
+	for _, f := range first {
+		if f {
+			n++
+		}
+	}
+	require(t, n > 0 && n < len(first), "expected some but not all reapers to fail, got %d", n)
+}
+
+func TestChaosPanic(t *testing.T) {
+	recovering := nls.WithInterceptor(nil, func(next nls.Reaper) nls.Reaper {
+		return func(ctx context.Context) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = p.(error)
+				}
+			}()
+			return next(ctx)
+		}
+	})
+	s := nls.NewScope(recovering, nlstest.Chaos(1, nlstest.WithChaosPanic(1)))
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	})
+	var errs []error
+	s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require(t, len(errs) == 1 && errors.Is(errs[0], nlstest.ErrChaos),
+		"expected recovered chaos panic, got %v", errs)
+}
+
+func TestChaosSimulatedDelay(t *testing.T) {
+	c := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := nls.NewScope(nls.WithClock(c), nlstest.Chaos(1, nlstest.WithChaosDelay(1, time.Hour)))
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	})
+	done := s.ExitAsync(context.TODO())
+	c.BlockUntil(1)
+	select {
+	case <-done:
+		t.Fatal("expected reaper to be delayed on the simulated clock")
+	default:
+	}
+	c.Advance(time.Hour)
+	require(t, <-done == nil, "unexpected exit error")
+}
@@ -0,0 +1,145 @@
+// Package nlstest provides helpers for tests that use nls.Scopes, in
+// particular for suites of parallel tests that each need an isolated scope
+// tree.
+package nlstest
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+// CleanupTimeout bounds the Exit of, and the subsequent wait for goroutines
+// managed by, each Scope created by this package when its test completes.
+var CleanupTimeout = 10 * time.Second
+
+// Parallel marks t as a parallel test (see testing.T.Parallel) and returns an
+// isolated root Scope for it as per Scope.
+func Parallel(t *testing.T, opts ...nls.ScopeOpt) *nls.Scope {
+	t.Helper()
+	t.Parallel()
+	return Scope(t, opts...)
+}
+
+// Scope returns a new root Scope, configured with the supplied options, that
+// is exited when t completes. The Scope has its own error channel: every error
+// delivered to it fails t, and tracks its goroutines (see
+// nls.WithGoroutineTracking). When t completes the Scope is exited and t fails if
+// the Exit does not complete, or if goroutines started via Scope.Go are still
+// running, within CleanupTimeout.
+func Scope(t testing.TB, opts ...nls.ScopeOpt) *nls.Scope {
+	t.Helper()
+	s, _ := root(t, opts)
+	return s
+}
+
+// Shared returns a new root Scope, as per Scope, for fixtures that are set up
+// once by t and shared read-only by its parallel subtests. The Scope is exited
+// only after t and all of its subtests have completed; its Exit is delegated
+// (see nls.Scope.DelegateExit) so an attempt by a subtest to exit it returns
+// nls.ErrDelegated rather than tearing down fixtures that other subtests are
+// still using.
+func Shared(t testing.TB, opts ...nls.ScopeOpt) *nls.Scope {
+	t.Helper()
+	s, o := root(t, opts)
+	if err := s.DelegateExit(o); err != nil {
+		t.Fatalf("nlstest: delegating shared scope: %v", err)
+	}
+	return s
+}
+
+// cleanup is the ExitOwner for a Scope created by this package.
+type cleanup struct {
+	exit nls.ExitFunc
+}
+
+func (c *cleanup) OwnExit(_ *nls.Scope, exit nls.ExitFunc) {
+	c.exit = exit
+}
+
+func root(t testing.TB, opts []nls.ScopeOpt) (*nls.Scope, *cleanup) {
+	errs := make(chan error)
+	s := nls.NewScope(append(opts, nls.WithErrorChan(errs), nls.WithGoroutineTracking())...)
+	c := &cleanup{exit: s.Exit}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case err := <-errs:
+				t.Errorf("nlstest: scope error: %v", err)
+			case <-done:
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), CleanupTimeout)
+		defer cancel()
+		if err := c.exit(ctx); err != nil {
+			t.Errorf("nlstest: scope exit: %v", err)
+		}
+		verifyNoLeaks(ctx, t, s)
+		close(done)
+		<-stopped
+	})
+	return s, c
+}
+
+// VerifyNoLeaks fails t if goroutines launched via nls.Scope.Go (or by an
+// nls.Pool) on the supplied Scope or its descendants are still running once
+// CleanupTimeout has elapsed, reporting for each the Scope that launched it,
+// where it was launched and its current stack. It is typically called after
+// the Scope has exited; Scopes created by this package are verified in this
+// way automatically. Leaked goroutines are reported only if the Scope tracks
+// them (see nls.WithGoroutineTracking).
+func VerifyNoLeaks(t testing.TB, s *nls.Scope) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), CleanupTimeout)
+	defer cancel()
+	verifyNoLeaks(ctx, t, s)
+}
+
+func verifyNoLeaks(ctx context.Context, t testing.TB, s *nls.Scope) {
+	t.Helper()
+	if s.Wait(ctx) == nil {
+		return
+	}
+	live := s.LiveGoroutines()
+	stacks := goroutineStacks()
+	var b strings.Builder
+	for _, g := range live {
+		fmt.Fprintf(&b, "\ngoroutine %d launched by scope %s at:\n%s", g.ID, g.Scope, g.Created)
+		if stack, ok := stacks[g.ID]; ok {
+			fmt.Fprintf(&b, "currently:\n%s\n", stack)
+		}
+	}
+	t.Errorf("nlstest: %d goroutines leaked after scope exit:%s", len(live), b.String())
+}
+
+// goroutineStacks returns the current stack of every goroutine, keyed by ID.
+func goroutineStacks() map[uint64]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := make(map[uint64]string)
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		var id uint64
+		if _, err := fmt.Sscanf(g, "goroutine %d ", &id); err == nil {
+			stacks[id] = g
+		}
+	}
+	return stacks
+}
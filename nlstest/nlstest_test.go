@@ -0,0 +1,120 @@
+package nlstest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlstest"
+)
+
+func require(t *testing.T, expr bool, msg string, args ...interface{}) {
+	t.Helper()
+	if !expr {
+		t.Fatalf(msg, args...)
+	}
+}
+
+// recorder is a testing.TB that records failures and cleanups rather than
+// acting on them.
+type recorder struct {
+	testing.TB
+	errs     []string
+	cleanups []func()
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Cleanup(fn func()) { r.cleanups = append(r.cleanups, fn) }
+
+func (r *recorder) finish() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestParallel(t *testing.T) {
+	var reaped int32
+	t.Cleanup(func() {
+		require(t, atomic.LoadInt32(&reaped) == 1, "expected shared fixture to be reaped")
+	})
+	shared := nlstest.Shared(t)
+	nls.MustSpawn(context.TODO(), shared, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			atomic.AddInt32(&reaped, 1)
+			return nil
+		}, nil
+	})
+
+	for i := 0; i < 4; i++ {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			s := nlstest.Parallel(t)
+			err := shared.Exit(context.TODO())
+			require(t, errors.Is(err, nls.ErrDelegated), "expected ErrDelegated, got %v", err)
+			err = s.Go(func(ctx context.Context) { <-ctx.Done() })
+			require(t, err == nil, "unexpected error: %v", err)
+			require(t, atomic.LoadInt32(&reaped) == 0, "expected shared fixture to be live")
+		})
+	}
+}
+
+func TestScopeReportsErrors(t *testing.T) {
+	r := &recorder{}
+	s := nlstest.Scope(r)
+	s.ReportErr(errors.New("boom"))
+	r.finish()
+	require(t, len(r.errs) == 1, "expected one failure, got %v", r.errs)
+}
+
+func TestScopeDetectsLeaks(t *testing.T) {
+	defer func(d time.Duration) { nlstest.CleanupTimeout = d }(nlstest.CleanupTimeout)
+	nlstest.CleanupTimeout = 10 * time.Millisecond
+
+	r := &recorder{}
+	s := nlstest.Scope(r)
+	release := make(chan struct{})
+	defer close(release)
+	err := s.Go(func(context.Context) { <-release })
+	require(t, err == nil, "unexpected error: %v", err)
+	r.finish()
+	require(t, len(r.errs) == 2, "expected exit and leak failures, got %v", r.errs)
+}
+
+func TestVerifyNoLeaks(t *testing.T) {
+	defer func(d time.Duration) { nlstest.CleanupTimeout = d }(nlstest.CleanupTimeout)
+	nlstest.CleanupTimeout = 10 * time.Millisecond
+
+	s := nls.NewScope(nls.WithName("svc"), nls.WithGoroutineTracking())
+	release := make(chan struct{})
+	defer close(release)
+	err := s.NewChildScope(nls.WithName("worker")).Go(func(context.Context) { <-release })
+	require(t, err == nil, "unexpected error: %v", err)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	s.Exit(ctx)
+
+	r := &recorder{}
+	nlstest.VerifyNoLeaks(r, s)
+	require(t, len(r.errs) == 1, "expected one failure, got %v", r.errs)
+	report := r.errs[0]
+	for _, want := range []string{"1 goroutines leaked", "scope svc/worker",
+		"TestVerifyNoLeaks", "currently:"} {
+		require(t, strings.Contains(report, want), "expected %q in report:\n%s", want, report)
+	}
+
+	clean := nls.NewScope()
+	clean.Go(func(ctx context.Context) { <-ctx.Done() })
+	clean.Exit(context.TODO())
+	r = &recorder{}
+	nlstest.VerifyNoLeaks(r, clean)
+	require(t, len(r.errs) == 0, "unexpected failures %v", r.errs)
+}
@@ -0,0 +1,41 @@
+package nls
+
+import "context"
+
+// WithSubscriber yields a SpawnOpt that subscribes the supplied func to
+// events broadcast with Scope.Notify to the owning Scope or any of its
+// ancestors, e.g. to reload configuration on SIGHUP. The subscription lasts
+// as long as the spawned object: it is removed when the object is reaped,
+// whether by Scope.Exit or otherwise.
+func WithSubscriber(fn func(ctx context.Context, event interface{})) SpawnOpt {
+	return func(r *reaper) {
+		r.onEvent = fn
+	}
+}
+
+// Subscribe subscribes the supplied func to events broadcast with
+// Scope.Notify to the supplied Scope or any of its ancestors for as long as
+// the Scope is active. An error is returned if the Scope has already exited.
+func Subscribe(s Lifetime, fn func(ctx context.Context, event interface{})) error {
+	return s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		return NopReaper, nil
+	}, WithSubscriber(fn))
+}
+
+// Notify delivers the supplied event to every subscriber (see WithSubscriber)
+// registered with this Scope or any of its descendants, a Scope's own
+// subscribers before those of its children and otherwise in the order in which
+// they subscribed. Subscribers are called synchronously, without any of the
+// Scopes' locks held, so Notify returns once every subscriber has returned.
+func (s *Scope) Notify(ctx context.Context, event interface{}) {
+	s.mu.Lock()
+	children := s.childScopes()
+	subscribers := append([](func(context.Context, interface{}))(nil), s.subscribers...)
+	s.mu.Unlock()
+	for _, fn := range subscribers {
+		fn(ctx, event)
+	}
+	for _, c := range children {
+		c.Notify(ctx, event)
+	}
+}
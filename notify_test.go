@@ -0,0 +1,41 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestNotify(t *testing.T) {
+	root := nls.NewScope()
+	defer root.Exit(context.TODO())
+	var got []string
+	subscribe := func(s *nls.Scope, name string) {
+		err := nls.Subscribe(s, func(_ context.Context, ev interface{}) {
+			got = append(got, name+":"+ev.(string))
+		})
+		require(t, err == nil, "unexpected subscribe error %v", err)
+	}
+	a := root.NewChildScope()
+	b := root.NewChildScope()
+	subscribe(a.NewChildScope(), "a1")
+	subscribe(b, "b")
+	subscribe(root, "root")
+	subscribe(a, "a")
+
+	root.Notify(context.TODO(), "reload")
+	want := []string{"root:reload", "a:reload", "a1:reload", "b:reload"}
+	require(t, len(got) == len(want), "unexpected deliveries %v", got)
+	for i := range want {
+		require(t, got[i] == want[i], "expected %v, got %v", want, got)
+	}
+
+	got = nil
+	require(t, a.Exit(context.TODO()) == nil, "unexpected exit error")
+	root.Notify(context.TODO(), "again")
+	require(t, len(got) == 2 && got[0] == "root:again" && got[1] == "b:again",
+		"expected subscriptions of exited scopes to be removed, got %v", got)
+	require(t, nls.Subscribe(a, func(context.Context, interface{}) {}) != nil,
+		"expected subscribe to exited scope to fail")
+}
@@ -0,0 +1,106 @@
+package nls
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Observer receives the lifecycle events of the Scopes to which it is
+// attached with WithObserver, e.g. to adapt them to a logging, metrics or
+// tracing library. Methods are called synchronously, without any Scope lock
+// held, by the goroutine performing the operation, so they may be called
+// concurrently and should return promptly. Embed NopObserver to implement
+// only some of them.
+type Observer interface {
+	// OnScopeCreated is called once a new Scope has been attached to its
+	// parent, if any.
+	OnScopeCreated(s *Scope)
+
+	// OnSpawn is called once Scope.Spawn has registered a Reaper with s.
+	OnSpawn(ctx context.Context, s *Scope, r ReaperSnapshot)
+
+	// OnExitStart is called when s begins to exit, before any of its
+	// children or Reapers.
+	OnExitStart(ctx context.Context, s *Scope)
+
+	// OnReaperDone is called when a Reaper invoked by the exit of s
+	// returns, with the time it took and the error it returned.
+	OnReaperDone(ctx context.Context, s *Scope, r ReaperSnapshot, d time.Duration, err error)
+
+	// OnExitEnd is called once s has exited, with the time taken and the
+	// error of the Exit context, if any.
+	OnExitEnd(ctx context.Context, s *Scope, d time.Duration, err error)
+}
+
+// NopObserver is an Observer that ignores every event.
+type NopObserver struct{}
+
+// OnScopeCreated implements Observer.
+func (NopObserver) OnScopeCreated(*Scope) {}
+
+// OnSpawn implements Observer.
+func (NopObserver) OnSpawn(context.Context, *Scope, ReaperSnapshot) {}
+
+// OnExitStart implements Observer.
+func (NopObserver) OnExitStart(context.Context, *Scope) {}
+
+// OnReaperDone implements Observer.
+func (NopObserver) OnReaperDone(context.Context, *Scope, ReaperSnapshot, time.Duration, error) {}
+
+// OnExitEnd implements Observer.
+func (NopObserver) OnExitEnd(context.Context, *Scope, time.Duration, error) {}
+
+// WithObserver yields a ScopeOpt that attaches the supplied Observer to the
+// new Scope and its descendants, in addition to any Observers they inherit
+// or are given. An Observer that is already attached is not attached again.
+func WithObserver(o Observer) ScopeOpt {
+	return func(s *Scope) {
+		for _, have := range s.observers {
+			if reflect.TypeOf(have).Comparable() && have == o {
+				return
+			}
+		}
+		s.observers = append(s.observers[:len(s.observers):len(s.observers)], o)
+	}
+}
+
+func (s *Scope) observeCreated() {
+	for _, o := range s.observers {
+		o.OnScopeCreated(s)
+	}
+}
+
+func (s *Scope) observeSpawn(ctx context.Context, r reaper) {
+	for _, o := range s.observers {
+		o.OnSpawn(ctx, s, r.snapshot())
+	}
+}
+
+// observeExitStart notifies this Scope's Observers that it has begun to exit
+// and returns the time at which it did so.
+func (s *Scope) observeExitStart(ctx context.Context) time.Time {
+	start := s.clock.Now()
+	for _, o := range s.observers {
+		o.OnExitStart(ctx, s)
+	}
+	return start
+}
+
+func (s *Scope) observeExitEnd(ctx context.Context, start time.Time) {
+	d := s.clock.Now().Sub(start)
+	for _, o := range s.observers {
+		o.OnExitEnd(ctx, s, d, ctx.Err())
+	}
+}
+
+// observeReap is exitCfg.reap for a Scope with Observers.
+func (s *Scope) observeReap(ctx context.Context, ec *exitCfg, r reaper) {
+	start := s.clock.Now()
+	err := ec.invoke(ctx, s, r)
+	d := s.clock.Now().Sub(start)
+	for _, o := range s.observers {
+		o.OnReaperDone(ctx, s, r.snapshot(), d, err)
+	}
+	s.exitFailed(ec, ec.finish(ctx, r, err))
+}
@@ -0,0 +1,78 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+type recordingObserver struct {
+	nls.NopObserver
+	mu     sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(format string, args ...interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, args...))
+}
+
+func (o *recordingObserver) OnScopeCreated(s *nls.Scope) {
+	o.record("created %s", s.Path())
+}
+
+func (o *recordingObserver) OnSpawn(_ context.Context, s *nls.Scope, r nls.ReaperSnapshot) {
+	o.record("spawn %s %v", s.Path(), r.Labels)
+}
+
+func (o *recordingObserver) OnExitStart(_ context.Context, s *nls.Scope) {
+	o.record("exiting %s", s.Path())
+}
+
+func (o *recordingObserver) OnReaperDone(_ context.Context, s *nls.Scope, r nls.ReaperSnapshot,
+	_ time.Duration, err error) {
+	o.record("reaped %s %v %v", s.Path(), r.Labels, err)
+}
+
+func (o *recordingObserver) OnExitEnd(_ context.Context, s *nls.Scope, _ time.Duration, err error) {
+	o.record("exited %s %v", s.Path(), err)
+}
+
+func TestObserver(t *testing.T) {
+	obs := &recordingObserver{}
+	root := nls.NewScope(nls.WithName("app"), nls.WithObserver(obs))
+	child := root.NewChildScope(nls.WithName("db"))
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return errors.New("close failed") }, nil
+	}, nls.WithLabel("conn"))
+	root.Exit(context.TODO())
+
+	want := []string{
+		"created app",
+		"created app/db",
+		"spawn app/db [conn]",
+		"exiting app",
+		"exiting app/db",
+		"reaped app/db [conn] close failed",
+		"exited app/db <nil>",
+		"exited app <nil>",
+	}
+	require(t, fmt.Sprint(obs.events) == fmt.Sprint(want),
+		"unexpected events\n got: %q\nwant: %q", obs.events, want)
+}
+
+func TestObserversAccumulate(t *testing.T) {
+	a, b := &recordingObserver{}, &recordingObserver{}
+	root := nls.NewScope(nls.WithObserver(a))
+	defer root.Exit(context.TODO())
+	root.NewChildScope(nls.WithName("x"), nls.WithObserver(b))
+	root.NewChildScope(nls.WithName("y"))
+	require(t, len(a.events) == 3, "expected inherited observer to see all scopes, got %q", a.events)
+	require(t, len(b.events) == 1, "expected added observer to see only its scope, got %q", b.events)
+}
@@ -0,0 +1,107 @@
+package nls
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ReapOnDone spawns the supplied Reaper into s, as per Scope.Spawn with the
+// supplied SpawnOpts, and arranges for it to be run early should ctx be done
+// before s exits, in the manner of context.AfterFunc. In that case the Reaper
+// is removed from s, so that it runs exactly once, and invoked with a
+// background context; its error is reported via Scope.OfferErr, so it is
+// dropped if nothing is ready to receive it. Otherwise it runs as part of the
+// Exit of s like any other Reaper. This ties a resource to
+// whichever of a request and a Scope ends first, e.g. to release a lock taken
+// on behalf of a request if the client goes away. The supplied SpawnOpts must
+// not include WithHandle. If s is not a *Scope the Reaper cannot be removed
+// from it and so stays registered until s exits, but is still invoked only
+// once. An error is returned if s has already exited.
+func ReapOnDone(ctx context.Context, s Lifetime, r Reaper, opts ...SpawnOpt) error {
+	if sc, ok := s.(*Scope); ok {
+		return sc.reapOnDone(ctx, r, opts)
+	}
+	var claimed atomic.Bool
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	err := s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		return func(ctx context.Context) error {
+			close(stop)
+			if claimed.CompareAndSwap(false, true) {
+				return r(ctx)
+			}
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+			if claimed.CompareAndSwap(false, true) {
+				if err := r(context.Background()); err != nil {
+					s.OfferErr(err)
+				}
+			}
+		case <-stop:
+		}
+	}()
+	return nil
+}
+
+// reapOnDone is ReapOnDone for a *Scope, which removes the Reaper from this
+// Scope should it be run early.
+func (s *Scope) reapOnDone(ctx context.Context, r Reaper, opts []SpawnOpt) error {
+	h := &Handle{}
+	ran := make(chan struct{})
+	var once sync.Once
+	fn := func(ctx context.Context) error {
+		once.Do(func() { close(ran) })
+		return r(ctx)
+	}
+	opts = append(opts[:len(opts):len(opts)], WithHandle(h))
+	err := s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		return fn, nil
+	}, opts...)
+	if err != nil {
+		return err
+	}
+	s.launch(func() {
+		select {
+		case <-ctx.Done():
+			s.reapHandle(h)
+		case <-ran:
+		case <-s.Done():
+		}
+	})
+	return nil
+}
+
+// reapHandle removes from this Scope, and invokes, the reaper bound to the
+// supplied Handle, reporting its error via OfferErr. It does nothing if this
+// Scope no longer owns the reaper, e.g. because it is already being reaped.
+func (s *Scope) reapHandle(h *Handle) {
+	s.mu.Lock()
+	i := s.handleIndex(h)
+	if s.state != active || i < 0 {
+		s.mu.Unlock()
+		return
+	}
+	r := s.reapers[i]
+	s.remove(i)
+	s.recordOwnership(OwnershipRelease, nil, 1)
+	s.mu.Unlock()
+	h.bind(nil)
+
+	ec := s.newExitCfg([]ExitOpt{WithErrorHandler(s.OfferErr)})
+	ctx := context.WithValue(context.Background(), teardownKey{}, ec)
+	ec.reapAll(ctx, s, nil, ordered([]reaper{r}))
+}
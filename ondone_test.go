@@ -0,0 +1,68 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestReapOnDone(t *testing.T) {
+	s := nls.NewScope(nls.WithErrorChan(make(chan error, 1)))
+	var calls atomic.Int32
+	boom := errors.New("boom")
+	reaper := func(context.Context) error {
+		calls.Add(1)
+		return boom
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	require(t, nls.ReapOnDone(ctx, s, reaper) == nil, "unexpected spawn error")
+	require(t, s.Snapshot().Reapers == 1, "expected reaper to be spawned")
+
+	cancel()
+	select {
+	case err := <-s.Err():
+		require(t, errors.Is(err, boom), "expected reaper error to be reported, got %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("reaper not run on context cancellation")
+	}
+	require(t, calls.Load() == 1, "expected one call, got %d", calls.Load())
+	require(t, s.Snapshot().Reapers == 0, "expected reaper to be removed from scope")
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, calls.Load() == 1, "expected reaper not to run again, got %d calls", calls.Load())
+	require(t, nls.ReapOnDone(ctx, s, reaper) != nil, "expected error from exited scope")
+}
+
+func TestReapOnDoneUnreadError(t *testing.T) {
+	s := nls.NewScope()
+	defer s.Exit(context.TODO())
+	ctx, cancel := context.WithCancel(context.Background())
+	err := nls.ReapOnDone(ctx, s, func(context.Context) error { return errors.New("unread") })
+	require(t, err == nil, "unexpected spawn error")
+	cancel()
+	wctx, wcancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer wcancel()
+	require(t, s.Wait(wctx) == nil, "expected the early reap not to block on an unread error")
+}
+
+func TestReapOnDoneScopeExit(t *testing.T) {
+	s := nls.NewScope()
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err := nls.ReapOnDone(ctx, s, func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}, nls.WithLabel("lock"))
+	require(t, err == nil, "unexpected spawn error")
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, calls.Load() == 1, "expected reaper to run on exit, got %d calls", calls.Load())
+	cancel()
+	require(t, s.Wait(context.TODO()) == nil, "expected watcher to return")
+	require(t, calls.Load() == 1, "expected reaper not to run again, got %d calls", calls.Load())
+}
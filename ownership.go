@@ -0,0 +1,127 @@
+package nls
+
+import (
+	"sync"
+	"time"
+)
+
+// OwnershipOp identifies the kind of change of ownership recorded by an
+// OwnershipEvent.
+type OwnershipOp string
+
+const (
+	// OwnershipAcquire records that a Scope took ownership of a newly
+	// spawned resource.
+	OwnershipAcquire OwnershipOp = "acquire"
+
+	// OwnershipAttach records that a child Scope was attached to its parent.
+	OwnershipAttach OwnershipOp = "attach"
+
+	// OwnershipDetach records that a child Scope was detached from its
+	// parent, either because it exited or because it was moved elsewhere.
+	OwnershipDetach OwnershipOp = "detach"
+
+	// OwnershipRelease records that a Scope exited, releasing every
+	// resource that it owned.
+	OwnershipRelease OwnershipOp = "release"
+
+	// OwnershipTransfer records that a resource was moved between Scopes by
+	// Scope.Transfer. It is recorded by both Scopes, each naming the other
+	// as its Peer.
+	OwnershipTransfer OwnershipOp = "transfer"
+)
+
+// OwnershipEvent records a single change in what a Scope owns.
+type OwnershipEvent struct {
+	Time time.Time   `json:"time"`
+	Op   OwnershipOp `json:"op"`
+
+	// Scope is the path (see Scope.Path) of the Scope whose ownership
+	// changed.
+	Scope string `json:"scope"`
+
+	// Peer is the path of the other Scope involved in the change (e.g. the
+	// child for OwnershipAttach), if any.
+	Peer string `json:"peer,omitempty"`
+
+	// Resource identifies the affected resource by its spawn position in
+	// Scope (starting at 1) for OwnershipAcquire, or is the number of
+	// resources released for OwnershipRelease.
+	Resource int `json:"resource,omitempty"`
+}
+
+// WithOwnershipHistory yields a ScopeOpt that causes the new Scope to retain
+// the most recent limit OwnershipEvents that it records, for retrieval via
+// Scope.OwnershipHistory. History is not inherited by child Scopes.
+func WithOwnershipHistory(limit int) ScopeOpt {
+	return func(s *Scope) {
+		s.history = &ownershipLog{limit: limit}
+	}
+}
+
+// WithOwnershipObserver yields a ScopeOpt that installs a func to be notified
+// of every OwnershipEvent recorded by the new Scope and, unless they are
+// given their own, its descendants. The func is invoked synchronously,
+// possibly while the Scope's internal lock is held, so it must not call back
+// into the Scope.
+func WithOwnershipObserver(fn func(OwnershipEvent)) ScopeOpt {
+	return func(s *Scope) {
+		s.onOwn = fn
+	}
+}
+
+// OwnershipHistory returns the OwnershipEvents retained by this Scope (see
+// WithOwnershipHistory), oldest first.
+func (s *Scope) OwnershipHistory() []OwnershipEvent {
+	if s.history == nil {
+		return nil
+	}
+	return s.history.events()
+}
+
+// recordOwnership records an OwnershipEvent, naming the supplied peer Scope
+// if it is non-nil. Paths are only computed if the event will be used.
+func (s *Scope) recordOwnership(op OwnershipOp, peer *Scope, resource int) {
+	if s.history == nil && s.onOwn == nil && s.encoder == nil {
+		return
+	}
+	ev := OwnershipEvent{
+		Time:     s.clock.Now(),
+		Op:       op,
+		Scope:    s.Path(),
+		Resource: resource,
+	}
+	if peer != nil {
+		ev.Peer = peer.Path()
+	}
+	if s.history != nil {
+		s.history.append(ev)
+	}
+	if s.onOwn != nil {
+		s.onOwn(ev)
+	}
+	if s.encoder != nil {
+		s.encoder.Encode(ev)
+	}
+}
+
+type ownershipLog struct {
+	mu    sync.Mutex
+	limit int
+	log   []OwnershipEvent
+}
+
+func (l *ownershipLog) append(ev OwnershipEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.log = append(l.log, ev)
+	if over := len(l.log) - l.limit; over > 0 {
+		l.log = append(l.log[:0], l.log[over:]...)
+	}
+}
+
+func (l *ownershipLog) events() []OwnershipEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]OwnershipEvent(nil), l.log...)
+}
@@ -0,0 +1,52 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestOwnershipHistory(t *testing.T) {
+	var observed []nls.OwnershipEvent
+	root := nls.NewScope(
+		nls.WithName("root"),
+		nls.WithOwnershipHistory(3),
+		nls.WithOwnershipObserver(func(ev nls.OwnershipEvent) {
+			observed = append(observed, ev)
+		}))
+	child := root.NewChildScope(nls.WithName("child"))
+	require(t, child.Path() == "root/child", "unexpected path %q", child.Path())
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return nilReaper, nil
+	})
+	child.Exit(context.TODO())
+	nls.MustSpawn(context.TODO(), root, func(context.Context) (nls.Reaper, error) {
+		return nilReaper, nil
+	})
+	root.Exit(context.TODO())
+
+	var ops []nls.OwnershipOp
+	for _, ev := range observed {
+		ops = append(ops, nls.OwnershipOp(ev.Scope+":"+string(ev.Op)))
+	}
+	want := []nls.OwnershipOp{
+		"root:attach",
+		"root/child:acquire",
+		"root/child:release",
+		"root:detach",
+		"root:acquire",
+		"root:release",
+	}
+	require(t, len(ops) == len(want), "unexpected events %v", ops)
+	for i := range want {
+		require(t, ops[i] == want[i], "unexpected events %v", ops)
+	}
+
+	history := root.OwnershipHistory()
+	require(t, len(history) == 3, "expected bounded history, got %v", history)
+	require(t, history[0].Op == nls.OwnershipDetach &&
+		history[0].Peer == "root/child", "unexpected history %+v", history)
+	require(t, child.OwnershipHistory() == nil,
+		"expected history not to be inherited")
+}
@@ -0,0 +1,53 @@
+package nls
+
+// ExitPlan describes, without performing it, the teardown that Scope.Exit
+// would currently perform for a Scope. Children are listed in the order in
+// which they would be exited and Reapers in the order in which they would be
+// invoked (after all Children).
+type ExitPlan struct {
+	Scope    string          `json:"scope"`
+	Kind     Kind            `json:"kind,omitempty"`
+	Children []ExitPlan      `json:"children,omitempty"`
+	Reapers  []PlannedReaper `json:"reapers,omitempty"`
+}
+
+// PlannedReaper describes a single Reaper within an ExitPlan.
+type PlannedReaper struct {
+	// Index is the position of the Reaper in the order in which it was
+	// spawned into its Scope, starting at 1.
+	Index int   `json:"index"`
+	Class Class `json:"class"`
+}
+
+// Plan returns the ExitPlan for this Scope: a dry run of what Scope.Exit would
+// do if it were called now. The plan is a point-in-time snapshot; concurrent
+// spawns or child creation may cause the actual Exit to differ.
+func (s *Scope) Plan() ExitPlan {
+	s.mu.Lock()
+	plan := ExitPlan{Scope: s.name, Kind: s.kind}
+	positions := order(s.reapers)
+	for k := len(s.reapers) - 1; k >= 0; k-- {
+		i := k
+		if positions != nil {
+			i = positions[k]
+		}
+		plan.Reapers = append(plan.Reapers,
+			PlannedReaper{Index: i + 1, Class: s.reapers[i].class})
+	}
+	children := s.childScopes()
+	s.mu.Unlock()
+	for i := len(children) - 1; i >= 0; i-- {
+		plan.Children = append(plan.Children, children[i].Plan())
+	}
+	return plan
+}
+
+// Steps returns the number of Reapers that would be invoked by the plan,
+// including those of all descendants.
+func (p ExitPlan) Steps() int {
+	n := len(p.Reapers)
+	for _, c := range p.Children {
+		n += c.Steps()
+	}
+	return n
+}
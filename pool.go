@@ -0,0 +1,242 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// ErrPoolClosed is returned from Pool.Submit once the Scope that owns the Pool
+// has begun to exit.
+var ErrPoolClosed = errors.New("pool closed")
+
+// Task is a unit of work executed by a Pool. The context passed to a Task is
+// canceled if the owning Scope's Exit context is done before the Pool has
+// finished draining.
+type Task func(ctx context.Context)
+
+// Pool is a set of worker goroutines, owned by a Scope, that execute submitted
+// Tasks. When the owning Scope exits the Pool stops accepting new Tasks, runs
+// every Task that has already been queued, and waits (subject to the Exit
+// context) for its workers to return.
+type Pool struct {
+	gate    sync.RWMutex // guards closed against concurrent Submits
+	closed  bool
+	closing chan struct{}
+	sealed  chan struct{}
+
+	mu      sync.Mutex
+	workers int
+
+	tasks  chan Task
+	min    int
+	max    int
+	queue  int
+	idle   time.Duration
+	clock  Clock
+	launch func(func())
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// PoolOpt is a type for optional parameters to NewPool.
+type PoolOpt func(*Pool)
+
+// WithWorkers yields a PoolOpt that sets the number of worker goroutines that
+// a Pool keeps running for the lifetime of its Scope. The default is
+// runtime.GOMAXPROCS(0).
+func WithWorkers(n int) PoolOpt {
+	return func(p *Pool) {
+		p.min = n
+	}
+}
+
+// WithMaxWorkers yields a PoolOpt that allows a Pool to grow beyond the number
+// of workers set by WithWorkers, up to the supplied limit, when every worker
+// is busy. Workers started in this way exit after being idle for the duration
+// set by WithIdleTimeout. By default a Pool does not grow.
+func WithMaxWorkers(n int) PoolOpt {
+	return func(p *Pool) {
+		p.max = n
+	}
+}
+
+// WithIdleTimeout yields a PoolOpt that sets how long a worker started beyond
+// the WithWorkers count may remain idle before exiting. The default is one
+// minute.
+func WithIdleTimeout(d time.Duration) PoolOpt {
+	return func(p *Pool) {
+		p.idle = d
+	}
+}
+
+// WithQueueSize yields a PoolOpt that sets the number of Tasks that may be
+// queued awaiting a worker before Submit blocks. The default is zero, i.e.
+// Submit blocks until a worker accepts the Task.
+func WithQueueSize(n int) PoolOpt {
+	return func(p *Pool) {
+		p.queue = n
+	}
+}
+
+// NewPool starts a Pool whose workers are managed by the supplied Lifetime.
+// If it is a *Scope the workers are tracked like goroutines started with
+// Scope.Go and so can be awaited with Scope.Wait. An error is returned if the
+// Lifetime has already exited.
+func NewPool(s Lifetime, opts ...PoolOpt) (*Pool, error) {
+	launch := func(fn func()) { go fn() }
+	if sc, ok := s.(*Scope); ok {
+		launch = sc.launch
+	}
+	p := &Pool{
+		closing: make(chan struct{}),
+		sealed:  make(chan struct{}),
+		min:     runtime.GOMAXPROCS(0),
+		idle:    time.Minute,
+		clock:   ClockOf(s),
+		launch:  launch,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if p.min < 1 {
+		p.min = 1
+	}
+	if p.max < p.min {
+		p.max = p.min
+	}
+	p.tasks = make(chan Task, p.queue)
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	err := s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		p.workers = p.min
+		for i := 0; i < p.min; i++ {
+			p.spawn(false)
+		}
+		return p.drain, nil
+	})
+	if err != nil {
+		p.cancel()
+		return nil, err
+	}
+	return p, nil
+}
+
+// Submit hands the supplied Task to the Pool, blocking until it has been
+// accepted by a worker or queued. If the supplied context is done first its
+// error is returned. ErrPoolClosed is returned if the Pool's Scope has begun
+// to exit.
+func (p *Pool) Submit(ctx context.Context, task Task) error {
+	p.gate.RLock()
+	defer p.gate.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	select {
+	case p.tasks <- task:
+		return nil
+	default:
+	}
+	p.grow()
+	select {
+	case p.tasks <- task:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.closing:
+		return ErrPoolClosed
+	}
+}
+
+// Workers returns the number of worker goroutines currently running.
+func (p *Pool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.workers
+}
+
+// grow starts an additional worker, if the Pool is permitted to grow, because
+// no existing worker was ready to accept a Task.
+func (p *Pool) grow() {
+	p.mu.Lock()
+	if p.workers >= p.max {
+		p.mu.Unlock()
+		return
+	}
+	p.workers++
+	p.mu.Unlock()
+	p.spawn(true)
+}
+
+func (p *Pool) spawn(transient bool) {
+	p.wg.Add(1)
+	p.launch(func() {
+		defer p.wg.Done()
+		p.work(transient)
+	})
+}
+
+func (p *Pool) work(transient bool) {
+	defer func() {
+		p.mu.Lock()
+		p.workers--
+		p.mu.Unlock()
+	}()
+	for {
+		task, ok := p.next(transient)
+		if !ok {
+			return
+		}
+		task(p.ctx)
+	}
+}
+
+// next blocks until a Task is available, returning false once the Pool has
+// been sealed and its queue is empty or, for a transient worker, once the
+// idle timeout elapses.
+func (p *Pool) next(transient bool) (Task, bool) {
+	var idle <-chan time.Time
+	if transient {
+		t := p.clock.NewTimer(p.idle)
+		defer t.Stop()
+		idle = t.C()
+	}
+	select {
+	case task := <-p.tasks:
+		return task, true
+	case <-p.sealed:
+		select {
+		case task := <-p.tasks:
+			return task, true
+		default:
+			return nil, false
+		}
+	case <-idle:
+		return nil, false
+	}
+}
+
+// drain is the Reaper through which a Pool's Scope shuts it down.
+func (p *Pool) drain(ctx context.Context) error {
+	close(p.closing)
+	p.gate.Lock()
+	p.closed = true
+	p.gate.Unlock()
+	close(p.sealed)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+}
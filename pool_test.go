@@ -0,0 +1,97 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestPoolDrainsOnExit(t *testing.T) {
+	s := nls.NewScope()
+	p, err := nls.NewPool(s, nls.WithWorkers(2), nls.WithQueueSize(8))
+	require(t, err == nil, "unexpected error: %v", err)
+
+	release := make(chan struct{})
+	var ran int32
+	for i := 0; i < 8; i++ {
+		err := p.Submit(context.TODO(), func(context.Context) {
+			<-release
+			atomic.AddInt32(&ran, 1)
+		})
+		require(t, err == nil, "unexpected submit error: %v", err)
+	}
+
+	exited := s.ExitAsync(context.TODO())
+	close(release)
+	err = <-exited
+	require(t, err == nil, "unexpected exit error: %v", err)
+	require(t, atomic.LoadInt32(&ran) == 8, "expected all queued tasks to run, got %v", ran)
+
+	err = p.Submit(context.TODO(), func(context.Context) {})
+	require(t, errors.Is(err, nls.ErrPoolClosed), "expected ErrPoolClosed, got %v", err)
+	require(t, p.Workers() == 0, "expected workers to have exited")
+}
+
+func TestPoolExitDeadlineCancelsTasks(t *testing.T) {
+	s := nls.NewScope()
+	p, err := nls.NewPool(s, nls.WithWorkers(1))
+	require(t, err == nil, "unexpected error: %v", err)
+
+	started := make(chan struct{})
+	err = p.Submit(context.TODO(), func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+	})
+	require(t, err == nil, "unexpected submit error: %v", err)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	err = s.Exit(ctx)
+	require(t, errors.Is(err, context.DeadlineExceeded), "expected deadline, got %v", err)
+	err = s.Wait(context.TODO())
+	require(t, err == nil, "expected canceled task to let worker return: %v", err)
+}
+
+func TestPoolGrows(t *testing.T) {
+	s := nls.NewScope()
+	defer s.Exit(context.TODO())
+	p, err := nls.NewPool(s, nls.WithWorkers(1), nls.WithMaxWorkers(3),
+		nls.WithIdleTimeout(time.Millisecond))
+	require(t, err == nil, "unexpected error: %v", err)
+
+	var wg sync.WaitGroup
+	release := make(chan struct{})
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		err := p.Submit(context.TODO(), func(context.Context) {
+			wg.Done()
+			<-release
+		})
+		require(t, err == nil, "unexpected submit error: %v", err)
+	}
+	wg.Wait()
+	require(t, p.Workers() == 3, "expected pool to grow to 3, got %v", p.Workers())
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+	defer cancel()
+	err = p.Submit(ctx, func(context.Context) {})
+	require(t, errors.Is(err, context.DeadlineExceeded), "expected pool at capacity, got %v", err)
+
+	close(release)
+	for p.Workers() > 1 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestNewPoolAfterExit(t *testing.T) {
+	s := nls.NewScope()
+	s.Exit(context.TODO())
+	_, err := nls.NewPool(s)
+	require(t, err != nil, "expected error from exited scope")
+}
@@ -0,0 +1,105 @@
+package nls
+
+import (
+	"sync"
+	"time"
+)
+
+// ExitReport describes the outcome of a single Scope.Exit, including the exit
+// of all of the Scope's descendants.
+type ExitReport struct {
+	Start    time.Time
+	Duration time.Duration
+
+	// Invoked, Failed and Abandoned count Reapers that were run, that
+	// returned an error and that were cut off by the Exit context.
+	Invoked   int
+	Failed    int
+	Abandoned int
+
+	// Leaked counts the abandoned Reapers that were left running by
+	// WithReaperTimeout.
+	Leaked int
+
+	// Errors holds every error passed to the Exit error handler (see
+	// WithErrorHandler).
+	Errors []error
+
+	// Err is the error returned from Scope.Exit.
+	Err error
+
+	// Reason is the reason supplied via WithReason, if any.
+	Reason error
+}
+
+// ExitedScope pairs a ScopeSnapshot, taken as a Scope began to exit, with the
+// ExitReport of that Exit.
+type ExitedScope struct {
+	Snapshot ScopeSnapshot
+	Report   ExitReport
+}
+
+// WithExitedChildHistory yields a ScopeOpt that causes the new Scope to retain
+// the most recent limit ExitedScopes describing children that were exited
+// directly (rather than as part of this Scope's own Exit), for retrieval via
+// Scope.ExitedChildren. History is not inherited by child Scopes.
+func WithExitedChildHistory(limit int) ScopeOpt {
+	return func(s *Scope) {
+		s.exited = &exitedLog{limit: limit}
+	}
+}
+
+// ExitedChildren returns the ExitedScopes retained by this Scope (see
+// WithExitedChildHistory), oldest first.
+func (s *Scope) ExitedChildren() []ExitedScope {
+	if s.exited == nil {
+		return nil
+	}
+	return s.exited.entries()
+}
+
+// postmortem returns the log, if any, in which the parent of this Scope
+// retains the ExitedScopes of its children.
+func (s *Scope) postmortem() *exitedLog {
+	if p := s.up(); p != nil {
+		return p.exited
+	}
+	return nil
+}
+
+func (ec *exitCfg) report(start time.Time, d time.Duration, err error) ExitReport {
+	ec.mu.Lock()
+	defer ec.mu.Unlock()
+	return ExitReport{
+		Start:     start,
+		Duration:  d,
+		Invoked:   ec.invoked,
+		Failed:    ec.failed,
+		Abandoned: ec.abandoned,
+		Leaked:    ec.leaked,
+		Errors:    append([]error(nil), ec.errs...),
+		Err:       err,
+		Reason:    ec.reason,
+	}
+}
+
+type exitedLog struct {
+	mu     sync.Mutex
+	limit  int
+	exited []ExitedScope
+}
+
+func (l *exitedLog) append(e ExitedScope) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.exited = append(l.exited, e)
+	if over := len(l.exited) - l.limit; over > 0 {
+		l.exited = append(l.exited[:0], l.exited[over:]...)
+	}
+}
+
+func (l *exitedLog) entries() []ExitedScope {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]ExitedScope(nil), l.exited...)
+}
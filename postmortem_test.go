@@ -0,0 +1,40 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestExitedChildHistory(t *testing.T) {
+	root := nls.NewScope(nls.WithName("root"), nls.WithExitedChildHistory(2))
+	boom := errors.New("boom")
+	for i := 0; i < 3; i++ {
+		child := root.NewChildScope(nls.WithName(fmt.Sprintf("request-%d", i)))
+		nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error { return boom }, nil
+		})
+		child.Exit(context.TODO())
+		child.Exit(context.TODO())
+	}
+	root.NewChildScope(nls.WithName("live"))
+
+	exited := root.ExitedChildren()
+	require(t, len(exited) == 2, "expected 2 retained children, got %v", len(exited))
+	for i, e := range exited {
+		name := fmt.Sprintf("request-%d", i+1)
+		require(t, e.Snapshot.Name == name && e.Snapshot.Reapers == 1,
+			"unexpected snapshot %+v", e.Snapshot)
+		require(t, e.Report.Invoked == 1 && e.Report.Failed == 1 && e.Report.Err == nil,
+			"unexpected report %+v", e.Report)
+		require(t, len(e.Report.Errors) == 1 && errors.Is(e.Report.Errors[0], boom),
+			"unexpected errors %v", e.Report.Errors)
+	}
+
+	root.Exit(context.TODO())
+	require(t, len(root.ExitedChildren()) == 2, "expected parent exit not to be recorded")
+	require(t, nls.NewScope().ExitedChildren() == nil, "expected no history by default")
+}
@@ -0,0 +1,16 @@
+package nls
+
+// WithPriority yields a SpawnOpt that sets the priority of the spawned
+// object's Reaper within its Scope: Reapers with a higher priority are invoked
+// before those with a lower one, and Reapers of equal priority in reverse
+// spawn order. The default priority is zero. This allows e.g. a metrics buffer
+// to be flushed before the network client it flushes to is closed, even though
+// the client was spawned first. Dependencies declared with WithDependsOn take
+// precedence over priority. As with dependencies, priority only orders the
+// Reapers of a single Scope; a Scope's children always exit before any of its
+// own Reapers are invoked.
+func WithPriority(p int) SpawnOpt {
+	return func(r *reaper) {
+		r.priority = p
+	}
+}
@@ -0,0 +1,59 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestWithPriority(t *testing.T) {
+	s := nls.NewScope()
+	var order []string
+	record := func(name string) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				order = append(order, name)
+				return nil
+			}, nil
+		}
+	}
+	nls.MustSpawn(context.TODO(), s, record("client"))
+	nls.MustSpawn(context.TODO(), s, record("low"), nls.WithPriority(-1))
+	nls.MustSpawn(context.TODO(), s, record("flush-a"), nls.WithPriority(10))
+	nls.MustSpawn(context.TODO(), s, record("server"))
+	nls.MustSpawn(context.TODO(), s, record("flush-b"), nls.WithPriority(10))
+
+	plan := s.Plan()
+	wantIndex := []int{5, 3, 4, 1, 2}
+	require(t, len(plan.Reapers) == len(wantIndex), "unexpected plan %+v", plan)
+	for i, want := range wantIndex {
+		require(t, plan.Reapers[i].Index == want, "expected plan order %v, got %+v", wantIndex, plan.Reapers)
+	}
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	want := []string{"flush-b", "flush-a", "server", "client", "low"}
+	require(t, len(order) == len(want), "expected %v, got %v", want, order)
+	for i := range want {
+		require(t, order[i] == want[i], "expected %v, got %v", want, order)
+	}
+}
+
+func TestPriorityYieldsToDependencies(t *testing.T) {
+	s := nls.NewScope()
+	var order []string
+	record := func(name string) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				order = append(order, name)
+				return nil
+			}, nil
+		}
+	}
+	var db nls.Handle
+	nls.MustSpawn(context.TODO(), s, record("db"), nls.WithHandle(&db), nls.WithPriority(5))
+	nls.MustSpawn(context.TODO(), s, record("repo"), nls.WithDependsOn(&db))
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, len(order) == 2 && order[0] == "repo" && order[1] == "db",
+		"expected dependent to be reaped first, got %v", order)
+}
@@ -0,0 +1,66 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// Once wraps the supplied Reaper so that it is invoked at most once, however
+// many times the returned Reaper is called. The first call invokes it; later
+// and concurrent calls wait for that invocation to return, or for their own
+// context to be done, and then return its error. This is useful for cleanup
+// that is reachable from several paths, e.g. both a Reaper registered with a
+// Scope and an explicit early release. Note that a Scope itself already invokes
+// each Reaper registered with it at most once (see Reaper) so Once is only
+// needed where a Reaper is also called from elsewhere. As every call returns
+// the first error, a Reaper wrapped with Once gains nothing from being retried
+// by a Janitor.
+func Once(r Reaper) Reaper {
+	var started atomic.Bool
+	done := make(chan struct{})
+	var err error
+	return func(ctx context.Context) error {
+		if started.CompareAndSwap(false, true) {
+			defer close(done)
+			err = r(ctx)
+			return err
+		}
+		select {
+		case <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// NopReaper is a Reaper that does nothing, for Spawners with nothing to clean
+// up.
+func NopReaper(context.Context) error {
+	return nil
+}
+
+// Reapers combines the supplied Reapers into one that invokes them in reverse
+// order, mirroring the order in which a Scope reaps, continuing past failures
+// and returning their errors joined. Nil Reapers are skipped.
+func Reapers(rs ...Reaper) Reaper {
+	return func(ctx context.Context) error {
+		var errs []error
+		for i := len(rs) - 1; i >= 0; i-- {
+			if rs[i] != nil {
+				errs = append(errs, rs[i](ctx))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// ReaperFromFunc adapts a cleanup func that takes no context, such as a
+// Close or Stop method value, to a Reaper. The context passed to the Reaper
+// is ignored; see Detached for cleanup that may block.
+func ReaperFromFunc(fn func() error) Reaper {
+	return func(context.Context) error {
+		return fn()
+	}
+}
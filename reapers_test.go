@@ -0,0 +1,121 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestOnce(t *testing.T) {
+	var calls atomic.Int32
+	boom := errors.New("boom")
+	release := make(chan struct{})
+	fn := nls.Once(func(context.Context) error {
+		calls.Add(1)
+		<-release
+		return boom
+	})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 4)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(context.TODO())
+		}(i)
+	}
+	close(release)
+	wg.Wait()
+	require(t, calls.Load() == 1, "expected one invocation, got %d", calls.Load())
+	for _, err := range errs {
+		require(t, errors.Is(err, boom), "expected first error for every call, got %v", err)
+	}
+	require(t, errors.Is(fn(context.TODO()), boom), "expected first error for later call")
+}
+
+func TestOnceWaiterContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{})
+	fn := nls.Once(func(context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	go fn(context.TODO())
+	<-started
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require(t, errors.Is(fn(ctx), context.Canceled), "expected waiter to give up with its context")
+}
+
+func TestReaperInvokedOnce(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		s := nls.NewScope()
+		var labeled, replaced, early atomic.Int32
+		count := func(n *atomic.Int32) nls.Spawner {
+			return func(context.Context) (nls.Reaper, error) {
+				return func(context.Context) error {
+					n.Add(1)
+					return nil
+				}, nil
+			}
+		}
+		var h nls.Handle
+		nls.MustSpawn(context.TODO(), s, count(&labeled), nls.WithLabel("l"))
+		nls.MustSpawn(context.TODO(), s, count(&replaced), nls.WithHandle(&h))
+		ctx, cancel := context.WithCancel(context.Background())
+		err := nls.ReapOnDone(ctx, s, func(context.Context) error {
+			early.Add(1)
+			return nil
+		})
+		require(t, err == nil, "unexpected spawn error %v", err)
+
+		var wg sync.WaitGroup
+		wg.Add(4)
+		go func() { defer wg.Done(); s.ReapLabel(context.TODO(), "l") }()
+		go func() { defer wg.Done(); s.Replace(context.TODO(), &h, count(&replaced)) }()
+		go func() { defer wg.Done(); cancel() }()
+		go func() { defer wg.Done(); s.Exit(context.TODO()) }()
+		wg.Wait()
+		s.Exit(context.TODO())
+		s.Wait(context.TODO())
+
+		require(t, labeled.Load() == 1, "expected labeled reaper once, got %d", labeled.Load())
+		require(t, early.Load() == 1, "expected early reaper once, got %d", early.Load())
+		require(t, replaced.Load() >= 1 && replaced.Load() <= 2,
+			"expected old and replacement reapers at most once each, got %d", replaced.Load())
+	}
+}
+
+func TestReapers(t *testing.T) {
+	var order []int
+	boom, bang := errors.New("boom"), errors.New("bang")
+	step := func(i int, err error) nls.Reaper {
+		return func(context.Context) error {
+			order = append(order, i)
+			return err
+		}
+	}
+	err := nls.Reapers(step(1, boom), nil, step(2, nil), nls.NopReaper, step(3, bang))(context.TODO())
+	require(t, errors.Is(err, boom) && errors.Is(err, bang), "expected joined errors, got %v", err)
+	require(t, len(order) == 3 && order[0] == 3 && order[1] == 2 && order[2] == 1,
+		"expected reverse order, got %v", order)
+	require(t, nls.Reapers()(context.TODO()) == nil, "expected empty combination to succeed")
+}
+
+func TestReaperFromFunc(t *testing.T) {
+	boom := errors.New("boom")
+	called := false
+	fn := nls.ReaperFromFunc(func() error {
+		called = true
+		return boom
+	})
+	require(t, errors.Is(fn(context.TODO()), boom), "expected func error")
+	require(t, called, "expected func to be called")
+}
@@ -0,0 +1,25 @@
+package nls
+
+import "context"
+
+// WithReason yields an ExitOpt that records why the Scope is being exited,
+// e.g. to distinguish a crash-triggered teardown from a graceful shutdown.
+// The reason is available to every Reaper invoked by the Exit via
+// ReasonFromContext and is included in the ExitReport (see
+// WithExitedChildHistory) and ExitRecord (see WithExitTracker) of the Exit.
+// Use errors.New to supply a plain string.
+func WithReason(reason error) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.reason = reason
+	}
+}
+
+// ReasonFromContext returns the reason supplied via WithReason to the Exit
+// that invoked the Reaper to which the supplied context was passed, or nil if
+// there is none.
+func ReasonFromContext(ctx context.Context) error {
+	if ec, _ := ctx.Value(teardownKey{}).(*exitCfg); ec != nil {
+		return ec.reason
+	}
+	return nil
+}
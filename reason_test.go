@@ -0,0 +1,39 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestExitReason(t *testing.T) {
+	crash := errors.New("crash")
+	parent := nls.NewScope(nls.WithExitedChildHistory(1))
+	s := parent.NewChildScope()
+	var seen []error
+	for i := 0; i < 2; i++ {
+		nls.MustSpawn(context.TODO(), s.NewChildScope(), func(context.Context) (nls.Reaper, error) {
+			return func(ctx context.Context) error {
+				seen = append(seen, nls.ReasonFromContext(ctx))
+				return nil
+			}, nil
+		})
+	}
+	store := nls.NewMemoryStore(1)
+	tracker := nls.NewExitTracker(store)
+	err := s.Exit(context.TODO(), nls.WithReason(crash), nls.WithExitTracker(tracker))
+	require(t, err == nil, "unexpected exit error %v", err)
+	require(t, len(seen) == 2 && seen[0] == crash && seen[1] == crash,
+		"expected every reaper to see the reason, got %v", seen)
+
+	exited := parent.ExitedChildren()
+	require(t, len(exited) == 1 && exited[0].Report.Reason == crash,
+		"expected reason in exit report, got %+v", exited)
+	recs, _ := store.Records()
+	require(t, len(recs) == 1 && recs[0].Reason == "crash",
+		"expected reason in exit record, got %+v", recs)
+
+	require(t, nls.ReasonFromContext(context.TODO()) == nil, "expected no reason outside exit")
+}
@@ -0,0 +1,76 @@
+package nls
+
+import (
+	"errors"
+	"sync"
+)
+
+var errRecycleActive = errors.New("cannot recycle a scope that has not exited")
+
+var errRecycleBusy = errors.New("cannot recycle a scope with work still in flight")
+
+var errRecycleLinked = errors.New("cannot recycle a scope with children or timers still running")
+
+// scopeBlock co-locates a Scope with the internal structures to which it
+// points so that creating a Scope costs a single allocation.
+type scopeBlock struct {
+	scope    Scope
+	tracker  tracker
+	inflight tracker
+	guard    guard
+}
+
+// recycled holds exited Scopes, reset to their zero state but retaining their
+// internal allocations, for reuse by NewScope and Scope.NewChildScope.
+var recycled = sync.Pool{
+	New: func() interface{} {
+		b := &scopeBlock{}
+		b.scope.tracker = &b.tracker
+		b.scope.inflight = &b.inflight
+		b.scope.guard = &b.guard
+		b.guard.scope = &b.scope
+		return &b.scope
+	},
+}
+
+// Recycle returns this exited Scope to an internal pool from which NewScope
+// and Scope.NewChildScope may reuse it, saving allocations for programs that
+// create many short-lived (e.g. request) Scopes. The caller must hold the only
+// remaining reference to the Scope: it must not be used, nor be reachable via
+// a Handle, ScopeMap, one of its former children or similar, after Recycle
+// returns. An error is returned, and the Scope is not recycled, if it has not
+// exited, if a child is still exiting or a timer's goroutine has yet to
+// return, or if goroutines started via Scope.Go, or work registered via
+// Scope.Acquire, in its subtree have not yet completed.
+func (s *Scope) Recycle() error {
+	s.mu.Lock()
+	st, linked := s.state, s.kids > 0 || s.timersLive > 0
+	s.mu.Unlock()
+	if st != done {
+		return errRecycleActive
+	}
+	if linked {
+		return errRecycleLinked
+	}
+	if s.tracker.busy() || s.inflight.busy() || s.refs.busy() {
+		return errRecycleBusy
+	}
+	s.reset()
+	recycled.Put(s)
+	return nil
+}
+
+// reset returns this Scope to its zero state, retaining only its internal
+// trackers and guard (themselves reset) for reuse, and its timer sequence so
+// that a PendingTimer held from before the reset cannot cancel a timer
+// scheduled after it.
+func (s *Scope) reset() {
+	t, in, g, seq := s.tracker, s.inflight, s.guard, s.timerSeq
+	*t = tracker{}
+	*in = tracker{}
+	g.parent = nil
+	for r := range g.counts {
+		delete(g.counts, r)
+	}
+	*s = Scope{tracker: t, inflight: in, guard: g, timerSeq: seq}
+}
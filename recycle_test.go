@@ -0,0 +1,87 @@
+package nls_test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestRecycle(t *testing.T) {
+	root := nls.NewScope(nls.WithName("root"))
+	for i := 0; i < 10; i++ {
+		req := root.NewChildScope(nls.WithName("request"))
+		require(t, req.Path() == "root/request", "unexpected path %q", req.Path())
+		require(t, req.Recycle() != nil, "expected error recycling active scope")
+		nls.MustSpawn(context.TODO(), req, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error { return nil }, nil
+		}, nls.WithResource(nls.ResourceFile))
+		err := req.Acquire()
+		require(t, err == nil, "unexpected error: %v", err)
+		req.Release()
+		req.Exit(context.TODO())
+		err = req.Recycle()
+		require(t, err == nil, "unexpected error: %v", err)
+	}
+	s := root.NewChildScope()
+	snap := s.Snapshot()
+	require(t, snap.Name == "" && snap.State == "active" && snap.Reapers == 0,
+		"expected fresh scope, got %+v", snap)
+	require(t, s.ResourceCount(nls.ResourceFile) == 0, "expected fresh resource counts")
+
+	busy := nls.NewScope()
+	release := make(chan struct{})
+	busy.Go(func(context.Context) { <-release })
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	busy.Exit(ctx)
+	require(t, busy.Recycle() != nil, "expected error recycling scope with running goroutine")
+	close(release)
+}
+
+func TestRecycleLinked(t *testing.T) {
+	parent := nls.NewScope()
+	child := parent.NewChildScope()
+	started, release := make(chan struct{}), make(chan struct{})
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}, nil
+	})
+	childDone := child.ExitAsync(context.TODO())
+	<-started
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	parent.Exit(ctx)
+	require(t, parent.Recycle() != nil, "expected error recycling scope with an exiting child")
+	close(release)
+	require(t, <-childDone == nil, "unexpected child exit error")
+	require(t, parent.Recycle() == nil, "unexpected error recycling scope once its child exited")
+
+	root := nls.NewScope()
+	defer root.Exit(context.TODO())
+	for i := 0; i < 100; i++ {
+		req := root.NewChildScope()
+		stale, err := req.ExitAfter(time.Hour)
+		require(t, err == nil, "unexpected error: %v", err)
+		req.Exit(context.TODO())
+		for req.Recycle() != nil {
+			runtime.Gosched()
+		}
+		next := root.NewChildScope()
+		if next != req {
+			next.Exit(context.TODO())
+			continue
+		}
+		_, err = next.ExitAfter(time.Hour)
+		require(t, err == nil, "unexpected error: %v", err)
+		require(t, !stale.Cancel(), "expected stale timer not to cancel a timer of the reused scope")
+		require(t, len(next.PendingTimers()) == 1, "expected the new timer to remain pending")
+		return
+	}
+	t.Skip("no scope was reused")
+}
@@ -0,0 +1,61 @@
+package nls
+
+import (
+	"context"
+	"errors"
+)
+
+var errNoRefs = errors.New("scope has no outstanding references")
+
+// WithExitWhenReleased yields a ScopeOpt that causes the new Scope to exit
+// when the last reference taken with Scope.Retain is released with
+// Scope.ReleaseRef. It suits a Scope holding a resource shared by several
+// independent owners, none of which should exit it unilaterally: each owner
+// takes a reference and the Scope exits once all are done with it.
+func WithExitWhenReleased() ScopeOpt {
+	return func(s *Scope) {
+		s.exitOnRelease = true
+	}
+}
+
+// Retain takes a reference to this Scope, deferring any Exit of it until the
+// reference is released with Scope.ReleaseRef. An error is returned if the
+// Scope has exited. References do not prevent the Scope from being exited
+// along with an ancestor.
+func (s *Scope) Retain() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != active {
+		return stateError{"retain", s.state}
+	}
+	s.refs.add(1)
+	return nil
+}
+
+// ReleaseRef releases a reference taken with Scope.Retain. If it is the last
+// outstanding reference then any Exit waiting on references proceeds and, if
+// the Scope was created with WithExitWhenReleased, the Scope is exited with
+// the supplied context and ExitOpts, the result of which is returned. An
+// error is returned if there is no reference to release.
+func (s *Scope) ReleaseRef(ctx context.Context, opts ...ExitOpt) error {
+	s.refs.mu.Lock()
+	if s.refs.n == 0 {
+		s.refs.mu.Unlock()
+		return s.annotate(errNoRefs)
+	}
+	s.refs.addLocked(-1)
+	last := s.refs.n == 0
+	s.refs.mu.Unlock()
+	if last && s.exitOnRelease {
+		return s.Exit(ctx, opts...)
+	}
+	return nil
+}
+
+// References returns the number of outstanding references taken with
+// Scope.Retain.
+func (s *Scope) References() int {
+	s.refs.mu.Lock()
+	defer s.refs.mu.Unlock()
+	return s.refs.n
+}
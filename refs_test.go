@@ -0,0 +1,42 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestExitWaitsForReferences(t *testing.T) {
+	s := nls.NewScope()
+	require(t, s.Retain() == nil, "unexpected retain error")
+	require(t, s.References() == 1, "expected one reference")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require(t, s.Exit(ctx) == context.DeadlineExceeded, "expected exit to time out")
+	require(t, s.Spawn(context.TODO(), nopSpawner) == nil,
+		"expected scope to remain active after timed out exit")
+
+	done := s.ExitAsync(context.TODO())
+	require(t, s.ReleaseRef(context.TODO()) == nil, "unexpected release error")
+	require(t, <-done == nil, "unexpected exit error")
+	require(t, s.Retain() != nil, "expected retain of exited scope to fail")
+	require(t, s.ReleaseRef(context.TODO()) != nil, "expected release without reference to fail")
+}
+
+func TestExitWhenReleased(t *testing.T) {
+	s := nls.NewScope(nls.WithExitWhenReleased())
+	reaped := false
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { reaped = true; return nil }, nil
+	})
+	for i := 0; i < 2; i++ {
+		require(t, s.Retain() == nil, "unexpected retain error")
+	}
+	require(t, s.ReleaseRef(context.TODO()) == nil, "unexpected release error")
+	require(t, !reaped, "expected scope to outlive its remaining owner")
+	require(t, s.ReleaseRef(context.TODO()) == nil, "unexpected release error")
+	require(t, reaped, "expected last release to exit the scope")
+}
@@ -0,0 +1,87 @@
+package nls
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Format selects the output format of RenderPlan.
+type Format int
+
+const (
+	// FormatTable renders one row per Reaper in invocation order.
+	FormatTable Format = iota
+
+	// FormatJSON renders the ExitPlan as an indented JSON document.
+	FormatJSON
+
+	// FormatTree renders the Scope hierarchy as an indented tree.
+	FormatTree
+)
+
+const unnamed = "(unnamed)"
+
+// RenderPlan writes a human- or machine-readable rendering of the supplied
+// ExitPlan to the supplied io.Writer in the supplied Format.
+func RenderPlan(w io.Writer, plan ExitPlan, format Format) error {
+	switch format {
+	case FormatTable:
+		return renderTable(w, plan)
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	case FormatTree:
+		return renderTree(w, plan, "")
+	}
+	return fmt.Errorf("unknown plan format %d", int(format))
+}
+
+func renderTable(w io.Writer, plan ExitPlan) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tSCOPE\tKIND\tREAPER\tCLASS")
+	step := 0
+	var walk func(p ExitPlan, path string)
+	walk = func(p ExitPlan, path string) {
+		path += "/" + displayName(p.Scope)
+		for _, c := range p.Children {
+			walk(c, path)
+		}
+		for _, r := range p.Reapers {
+			step++
+			fmt.Fprintf(tw, "%d\t%s\t%s\t#%d\t%s\n", step, path, p.Kind, r.Index, r.Class)
+		}
+	}
+	walk(plan, "")
+	return tw.Flush()
+}
+
+func renderTree(w io.Writer, p ExitPlan, indent string) error {
+	label := displayName(p.Scope)
+	if p.Kind != "" {
+		label += " [" + string(p.Kind) + "]"
+	}
+	if _, err := fmt.Fprintf(w, "%s%s\n", indent, label); err != nil {
+		return err
+	}
+	for _, c := range p.Children {
+		if err := renderTree(w, c, indent+"  "); err != nil {
+			return err
+		}
+	}
+	for _, r := range p.Reapers {
+		if _, err := fmt.Fprintf(w, "%s  - reaper #%d (%s)\n", indent, r.Index, r.Class); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func displayName(name string) string {
+	if name == "" {
+		return unnamed
+	}
+	return name
+}
@@ -0,0 +1,72 @@
+package nls_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func planFixture() *nls.Scope {
+	root := nls.NewScope(nls.WithName("root"))
+	spawn := func(s *nls.Scope, opts ...nls.SpawnOpt) {
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return nilReaper, nil
+		}, opts...)
+	}
+	spawn(root)
+	db := root.NewChildScope(nls.WithName("db"))
+	spawn(db, nls.WithClass(nls.ClassCritical))
+	req := root.NewRequestScope(nls.WithName("req"))
+	spawn(req)
+	spawn(req)
+	return root
+}
+
+func TestPlan(t *testing.T) {
+	plan := planFixture().Plan()
+	require(t, plan.Steps() == 4, "expected 4 steps, got %d", plan.Steps())
+	require(t, len(plan.Children) == 2 && plan.Children[0].Scope == "req",
+		"expected most recent child first: %+v", plan.Children)
+	require(t, plan.Children[0].Reapers[0].Index == 2,
+		"expected most recent reaper first: %+v", plan.Children[0].Reapers)
+}
+
+func TestRenderPlan(t *testing.T) {
+	plan := planFixture().Plan()
+
+	var table bytes.Buffer
+	err := nls.RenderPlan(&table, plan, nls.FormatTable)
+	require(t, err == nil, "unexpected error: %q", err)
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	require(t, len(lines) == 5, "expected header and 4 rows:\n%s", &table)
+	require(t, strings.Contains(lines[1], "/root/req") &&
+		strings.Contains(lines[1], "#2"), "unexpected first row %q", lines[1])
+	require(t, strings.Contains(lines[3], "critical"),
+		"unexpected third row %q", lines[3])
+
+	var js bytes.Buffer
+	err = nls.RenderPlan(&js, plan, nls.FormatJSON)
+	require(t, err == nil, "unexpected error: %q", err)
+	var decoded map[string]interface{}
+	require(t, json.Unmarshal(js.Bytes(), &decoded) == nil, "invalid JSON:\n%s", &js)
+	require(t, decoded["scope"] == "root", "unexpected JSON:\n%s", &js)
+
+	var tree bytes.Buffer
+	err = nls.RenderPlan(&tree, plan, nls.FormatTree)
+	require(t, err == nil, "unexpected error: %q", err)
+	want := "root\n" +
+		"  req [request]\n" +
+		"    - reaper #2 (normal)\n" +
+		"    - reaper #1 (normal)\n" +
+		"  db\n" +
+		"    - reaper #1 (critical)\n" +
+		"  - reaper #1 (normal)\n"
+	require(t, tree.String() == want, "unexpected tree:\n%s", &tree)
+
+	err = nls.RenderPlan(&tree, plan, nls.Format(99))
+	require(t, err != nil, "expected error for unknown format")
+}
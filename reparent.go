@@ -0,0 +1,74 @@
+package nls
+
+import (
+	"context"
+	"errors"
+)
+
+var errCycle = errors.New("cannot reparent a scope beneath itself or its descendant")
+
+// Reparent moves this Scope, together with everything that it owns, beneath
+// newParent, e.g. when a session migrates between tenants. Thereafter the
+// Scope is exited when newParent exits, forwards errors (see
+// WithErrorPropagation) to newParent, and counts its goroutines, in-flight
+// work and Resources against newParent's subtree. Options that the Scope
+// inherited from its former parent are retained. Reparent may be called on a
+// root Scope to attach it beneath newParent. An error is returned if either
+// Scope has exited, if newParent is this Scope or one of its descendants, or
+// (matching ErrResourceLimit) if the Scope's Resources would exceed a limit
+// imposed by newParent or its ancestors.
+func (s *Scope) Reparent(newParent *Scope) error {
+	s.moving.Lock()
+	defer s.moving.Unlock()
+	for p := newParent; p != nil; p = p.up() {
+		if p == s {
+			return errCycle
+		}
+	}
+	if old := s.up(); old == newParent {
+		return nil
+	}
+	s.mu.Lock()
+	st := s.state
+	s.mu.Unlock()
+	if st != active {
+		return stateError{"reparent", st}
+	}
+
+	newParent.mu.Lock()
+	st = newParent.state
+	newParent.mu.Unlock()
+	if st != active {
+		return stateError{"reparent beneath", st}
+	}
+	if err := s.guard.move(newParent.guard); err != nil {
+		return err
+	}
+	s.tracker.move(newParent.tracker)
+	s.inflight.move(newParent.inflight)
+
+	// Detach from the old parent before attaching to the new one as the
+	// Scope's slot in its parent's children is only valid for one parent at
+	// a time.
+	s.detacher()()
+	s.conf.Lock()
+	s.parent = newParent
+	s.detach = func() {}
+	s.conf.Unlock()
+
+	newParent.mu.Lock()
+	if newParent.state != active {
+		// newParent exited after it was checked above; had the move
+		// completed first this Scope would have exited with it.
+		newParent.mu.Unlock()
+		s.exitScope(context.Background())
+		return stateError{"reparent beneath", done}
+	}
+	detach := newParent.attach(s)
+	newParent.recordOwnership(OwnershipAttach, s, 0)
+	newParent.mu.Unlock()
+	s.conf.Lock()
+	s.detach = detach
+	s.conf.Unlock()
+	return nil
+}
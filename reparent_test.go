@@ -0,0 +1,80 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestReparent(t *testing.T) {
+	root := nls.NewScope(nls.WithName("root"))
+	a := root.NewChildScope(nls.WithName("a"))
+	b := root.NewChildScope(nls.WithName("b"), nls.WithResourceLimit(nls.ResourceFile, 5))
+	session := a.NewChildScope(nls.WithName("session"), nls.WithErrorPropagation())
+
+	reaped := false
+	nls.MustSpawn(context.TODO(), session, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			reaped = true
+			return nil
+		}, nil
+	}, nls.WithResource(nls.ResourceFile))
+	release := make(chan struct{})
+	err := session.Go(func(context.Context) { <-release })
+	require(t, err == nil, "unexpected error: %v", err)
+
+	err = session.Reparent(b)
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, session.Path() == "root/b/session", "unexpected path %q", session.Path())
+	require(t, a.ResourceCount(nls.ResourceFile) == 0, "expected file moved out of a")
+	require(t, b.ResourceCount(nls.ResourceFile) == 1, "expected file counted by b")
+	require(t, root.ResourceCount(nls.ResourceFile) == 1, "expected root count unchanged")
+
+	a.Exit(context.TODO())
+	require(t, !reaped, "expected session to survive its former parent")
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	require(t, a.Wait(ctx) == nil, "expected goroutine no longer tracked by a")
+	require(t, b.Wait(ctx) != nil, "expected goroutine tracked by b")
+
+	go session.ReportErr(errors.New("boom"))
+	err = <-b.Err()
+	require(t, err != nil && err.Error() == "session: boom", "unexpected error %v", err)
+
+	close(release)
+	b.Exit(context.TODO())
+	require(t, reaped, "expected session to exit with its new parent")
+	require(t, root.ResourceCount(nls.ResourceFile) == 0, "expected file released")
+}
+
+func TestReparentErrors(t *testing.T) {
+	root := nls.NewScope()
+	child := root.NewChildScope()
+	grandchild := child.NewChildScope()
+	err := child.Reparent(grandchild)
+	require(t, err != nil, "expected cycle to be rejected")
+	err = child.Reparent(child)
+	require(t, err != nil, "expected self-parenting to be rejected")
+
+	small := nls.NewScope(nls.WithResourceLimit(nls.ResourceSocket, 0))
+	_, err = grandchild.Reserve(nls.ResourceSocket)
+	require(t, err == nil, "unexpected error: %v", err)
+	err = child.Reparent(small)
+	require(t, errors.Is(err, nls.ErrResourceLimit), "expected ErrResourceLimit, got %v", err)
+	require(t, root.ResourceCount(nls.ResourceSocket) == 1, "expected counts unchanged")
+
+	orphan := nls.NewScope()
+	err = orphan.Reparent(child)
+	require(t, err == nil, "unexpected error adopting root scope: %v", err)
+	exited := nls.NewScope()
+	exited.Exit(context.TODO())
+	err = orphan.Reparent(exited)
+	require(t, err != nil, "expected error reparenting beneath exited scope")
+	root.Exit(context.TODO())
+	err = orphan.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	})
+	require(t, err != nil, "expected adopted scope to exit with new ancestors")
+}
@@ -0,0 +1,96 @@
+package nls
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// Rollout manages a child Scope, typically holding a canary subsystem, that
+// is active only while a runtime-controlled percentage of keys or requests is
+// routed to it. The child Scope is created (and its components spawned) when
+// the percentage rises above zero and exited when it returns to zero.
+type Rollout struct {
+	mu      sync.Mutex
+	parent  *Scope
+	setup   func(s *Scope) error
+	opts    []ScopeOpt
+	percent int
+	scope   *Scope
+}
+
+// NewRollout creates a Rollout, initially at zero percent, whose child Scopes
+// are created beneath the supplied parent with the supplied options and then
+// passed to setup to spawn their components.
+func NewRollout(parent *Scope, setup func(s *Scope) error, opts ...ScopeOpt) *Rollout {
+	return &Rollout{parent: parent, setup: setup, opts: opts}
+}
+
+// SetPercent changes the percentage, clamped to [0, 100], of keys and
+// requests routed to the Rollout's child Scope. Raising the percentage from
+// zero creates the child Scope and runs setup; if setup fails the child is
+// exited, the percentage remains zero and setup's error is returned. Lowering
+// the percentage to zero exits the child Scope with the supplied context and
+// options, returning the error from Scope.Exit.
+func (r *Rollout) SetPercent(ctx context.Context, p int, opts ...ExitOpt) error {
+	if p < 0 {
+		p = 0
+	} else if p > 100 {
+		p = 100
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case p > 0 && r.scope == nil:
+		s, err := r.parent.NewChildScopeErr(r.opts...)
+		if err != nil {
+			return fmt.Errorf("cannot activate rollout: %w", err)
+		}
+		if err := r.setup(s); err != nil {
+			s.Exit(ctx, opts...)
+			return err
+		}
+		r.scope = s
+	case p == 0 && r.scope != nil:
+		s := r.scope
+		r.scope = nil
+		r.percent = 0
+		return s.Exit(ctx, opts...)
+	}
+	r.percent = p
+	return nil
+}
+
+// Percent returns the current rollout percentage.
+func (r *Rollout) Percent() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.percent
+}
+
+// For returns the active child Scope if the supplied key falls within the
+// current percentage. A given key is consistently included as long as the
+// percentage does not decrease.
+func (r *Rollout) For(key string) (*Scope, bool) {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return r.route(int(h.Sum32() % 100))
+}
+
+// Sample returns the active child Scope for a random proportion of calls
+// equal to the current percentage, for routing requests that have no stable
+// key.
+func (r *Rollout) Sample() (*Scope, bool) {
+	return r.route(rand.Intn(100))
+}
+
+func (r *Rollout) route(bucket int) (*Scope, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.scope == nil || bucket >= r.percent {
+		return nil, false
+	}
+	return r.scope, true
+}
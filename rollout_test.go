@@ -0,0 +1,63 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestRollout(t *testing.T) {
+	parent := nls.NewScope()
+	var started, stopped int
+	r := nls.NewRollout(parent, func(s *nls.Scope) error {
+		started++
+		return s.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				stopped++
+				return nil
+			}, nil
+		})
+	}, nls.WithName("canary"))
+
+	_, ok := r.For("key")
+	require(t, !ok && started == 0, "expected inactive rollout")
+
+	require(t, r.SetPercent(context.TODO(), 10) == nil, "unexpected error")
+	require(t, started == 1, "expected canary to start")
+	in := 0
+	var key string
+	for i := 0; i < 1000; i++ {
+		if s, ok := r.For(fmt.Sprint(i)); ok {
+			require(t, s.Name() == "canary", "unexpected scope %q", s.Name())
+			in++
+			key = fmt.Sprint(i)
+		}
+	}
+	require(t, in > 50 && in < 150, "expected roughly 10%% of keys, got %v", in)
+
+	require(t, r.SetPercent(context.TODO(), 50) == nil, "unexpected error")
+	require(t, started == 1, "expected canary not to restart")
+	_, ok = r.For(key)
+	require(t, ok, "expected key to remain included as percentage rises")
+
+	require(t, r.SetPercent(context.TODO(), 0) == nil, "unexpected error")
+	require(t, stopped == 1 && r.Percent() == 0, "expected canary to stop")
+	_, ok = r.Sample()
+	require(t, !ok, "expected inactive rollout")
+
+	require(t, r.SetPercent(context.TODO(), 200) == nil, "unexpected error")
+	require(t, started == 2 && r.Percent() == 100, "expected canary to restart at 100%%")
+	_, ok = r.Sample()
+	require(t, ok, "expected all requests routed")
+}
+
+func TestRolloutSetupFailure(t *testing.T) {
+	boom := errors.New("boom")
+	r := nls.NewRollout(nls.NewScope(), func(*nls.Scope) error { return boom })
+	err := r.SetPercent(context.TODO(), 5)
+	require(t, errors.Is(err, boom), "expected setup error, got %v", err)
+	require(t, r.Percent() == 0, "expected percentage to remain zero")
+}
@@ -0,0 +1,46 @@
+package nls_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2021, time.March, 15, 10, 7, 30, 0, time.UTC) // a Monday
+	for _, tc := range []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2021, 3, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2021, 3, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2021, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 2 1,15 * *", time.Date(2021, 4, 1, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 6", time.Date(2021, 3, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 3", time.Date(2021, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2021, 3, 16, 0, 0, 0, 0, time.UTC)},
+	} {
+		sched, err := nls.ParseCron(tc.spec)
+		require(t, err == nil, "%s: unexpected error: %q", tc.spec, err)
+		got := sched.Next(base)
+		require(t, got.Equal(tc.want), "%s: want %v, got %v", tc.spec, tc.want, got)
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, spec := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* * 0 * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		_, err := nls.ParseCron(spec)
+		require(t, err != nil, "%s: expected parse error", spec)
+	}
+	require(t, !nls.Every(time.Minute).Next(time.Time{}).IsZero(),
+		"expected interval schedule to activate")
+}
@@ -0,0 +1,89 @@
+package nls
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Job is a unit of scheduled work. Each run of a Job is given its own job
+// Scope (see Scope.NewJobScope) which is exited when the run completes, so
+// resources acquired for the run can be spawned into it.
+type Job func(ctx context.Context, s *Scope) error
+
+type jobCfg struct {
+	timeout time.Duration
+}
+
+// JobOpt is a type for optional parameters to Scheduler.Add.
+type JobOpt func(*jobCfg)
+
+// WithJobTimeout yields a JobOpt that bounds the duration of each run of a
+// Job, including the Exit of the run's Scope.
+func WithJobTimeout(d time.Duration) JobOpt {
+	return func(cfg *jobCfg) {
+		cfg.timeout = d
+	}
+}
+
+// Scheduler runs Jobs according to their Schedules for as long as the Scope
+// that owns it remains active. Runs of the same Job never overlap; an
+// activation that falls due while the previous run is still in progress is
+// skipped, as is an activation that falls due once the Scheduler's Scope has
+// begun exiting. Errors returned by Jobs are reported, wrapped with the Job
+// name, via Scope.OfferErr on the run's Scope which, unless configured
+// otherwise (see WithKindDefaults), propagates them to the Scheduler's Scope.
+type Scheduler struct {
+	scope *Scope
+}
+
+// NewScheduler creates a Scheduler owned by the supplied Scope.
+func NewScheduler(s *Scope) *Scheduler {
+	return &Scheduler{scope: s}
+}
+
+// Add registers a Job to be run under the supplied name according to the
+// supplied Schedule. An error is returned if the Scheduler's Scope has exited.
+func (sch *Scheduler) Add(name string, sched Schedule, job Job, opts ...JobOpt) error {
+	var cfg jobCfg
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := sch.scope
+	return s.Go(func(ctx context.Context) {
+		for {
+			next := sched.Next(s.clock.Now())
+			if next.IsZero() {
+				return
+			}
+			t := s.clock.NewTimer(next.Sub(s.clock.Now()))
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return
+			case <-t.C():
+			}
+			sch.run(ctx, name, job, cfg)
+		}
+	})
+}
+
+func (sch *Scheduler) run(ctx context.Context, name string, job Job, cfg jobCfg) {
+	if cfg.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = ClockTimeout(ctx, sch.scope.clock, cfg.timeout)
+		defer cancel()
+	}
+	opts := sch.scope.kindOpts(KindJob, []ScopeOpt{WithName(name)})
+	run, err := sch.scope.NewChildScopeErr(opts...)
+	if err != nil {
+		return
+	}
+	err = job(ctx, run)
+	if exitErr := run.Exit(ctx, WithErrorHandler(run.OfferErr)); err == nil {
+		err = exitErr
+	}
+	if err != nil {
+		run.OfferErr(fmt.Errorf("job failed: %w", err))
+	}
+}
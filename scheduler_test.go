@@ -0,0 +1,56 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestScheduler(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	errs := make(chan error, 1)
+	root := nls.NewScope(nls.WithClock(clock), nls.WithErrorChan(errs))
+	sch := nls.NewScheduler(root)
+
+	runs := make(chan *nls.Scope)
+	reaped := make(chan struct{}, 2)
+	want := errors.New(t.Name())
+	n := 0
+	err := sch.Add("sweep", nls.Every(time.Minute), func(ctx context.Context, s *nls.Scope) error {
+		n++
+		nls.MustSpawn(ctx, s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error { reaped <- struct{}{}; return nil }, nil
+		})
+		runs <- s
+		if n == 2 {
+			return want
+		}
+		return nil
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	s := <-runs
+	require(t, s.Kind() == nls.KindJob && s.Name() == "sweep",
+		"expected named job scope, got %q %q", s.Kind(), s.Name())
+	<-reaped
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-runs
+	<-reaped
+	got := <-errs
+	require(t, errors.Is(got, want), "expected job error, got %q", got)
+	require(t, got.Error() == "sweep: job failed: "+want.Error(),
+		"unexpected error text %q", got)
+
+	err = root.Exit(context.TODO())
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	err = sch.Add("late", nls.Every(time.Minute), nil)
+	require(t, err != nil, "expected error adding job to exited scheduler")
+}
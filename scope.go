@@ -1,14 +1,19 @@
 package nls
 
 import (
-	"container/list"
 	"context"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Reaper is a func type that reclaims the resources from a previously spawned
-// (i.e. by a Spawner func) objecct or process.
+// (i.e. by a Spawner func) objecct or process. A Scope invokes each Reaper
+// registered with it at most once, whichever of Exit, Cancel, ReapLabel,
+// Replace, ReapOnDone and the like reaches it first; the only exception is a
+// failed Reaper handed to a Janitor to be retried (see WithJanitor).
 type Reaper func(context.Context) error
 
 // Spawner launches an object or process and returns a Reaper that will run
@@ -18,10 +23,42 @@ type Spawner func(context.Context) (Reaper, error)
 type state string
 
 const (
-	active state = "active"
-	done   state = "done"
+	active  state = "active"
+	closing state = "exiting" // Reapers are running; see Scope.exit
+	done    state = "done"
 )
 
+var (
+	// ErrExiting is matched (via errors.Is) by errors returned when an
+	// operation is refused because its Scope is exiting, including a Reaper
+	// that calls back into the Scope tearing it down.
+	ErrExiting = errors.New("scope is exiting")
+
+	// ErrScopeDone is matched (via errors.Is) by errors returned when an
+	// operation is refused because its Scope has exited.
+	ErrScopeDone = errors.New("scope has exited")
+)
+
+// stateError reports an operation refused because of the state of its Scope.
+type stateError struct {
+	op string // e.g. "spawn in"
+	st state
+}
+
+func (e stateError) Error() string {
+	return fmt.Sprintf("cannot %s scope with state %q", e.op, e.st)
+}
+
+func (e stateError) Is(target error) bool {
+	switch e.st {
+	case closing:
+		return target == ErrExiting
+	case done:
+		return target == ErrScopeDone
+	}
+	return false
+}
+
 // Scoper is a func signature realized by both nls.NewScope and
 // Scope.NewChildScope. It is useful to pass this abstraction around when the
 // ability to create a new Scope instance is desirable but the code should not
@@ -32,12 +69,70 @@ type Scoper func(...ScopeOpt) *Scope
 // onto a set of Reapers and child Scopes for execution at some dynamically
 // determined point in the future (by calling Scope.Exit).
 type Scope struct {
-	mu       sync.Mutex
-	state    state
-	children *list.List
-	reapers  []Reaper
-	errors   chan error
-	detach   func()
+	mu            sync.Mutex
+	name          string
+	kind          Kind
+	kinds         map[Kind][]ScopeOpt
+	state         state
+	children      []*Scope // in creation order, nil where a child was removed
+	holes         int      // the number of nil entries in children
+	slot          int      // index in the parent's children, guarded by its mu
+	attached      bool     // whether counted in the parent's kids, ditto
+	kids          int      // children attached and not yet detached
+	reapers       []reaper
+	checks        []healthCheck
+	drainers      []func(context.Context)
+	waiters       []func(context.Context) error
+	subscribers   []func(context.Context, interface{})
+	timers        map[uint64]*scopeTimer
+	timerSeq      uint64
+	timersLive    int           // timer goroutines yet to return
+	joined        chan struct{} // see Scope.Done
+	exitErrs      []error       // reported so far by the Exit in progress
+	exitErr       error         // see Scope.ExitErr
+	closer        *exitCfg      // the Exit tearing this Scope down, if any
+	exiting       atomic.Bool
+	draining      atomic.Bool
+	lastErr       atomic.Pointer[reportedErr] // see debug.go
+	clock         Clock
+	created       time.Time
+	history       *ownershipLog
+	exited        *exitedLog
+	onOwn         func(OwnershipEvent)
+	encoder       Encoder
+	tracker       *tracker
+	inflight      *tracker
+	goroutines    *goroutineLog // see WithGoroutineTracking
+	profiled      bool          // see WithProfileLabels
+	limits        map[Resource]int
+	guard         *guard
+	owner         ExitOwner
+	exitOpts      []ExitOpt
+	childLimit    int // see limits.go
+	reaperLimit   int
+	onLimit       func(error)
+	stats         *Stats // see stats.go
+	observers     []Observer
+	inherited     []ScopeOpt
+	interceptors  []interceptor
+	ttl           time.Duration
+	deadline      time.Time
+	idle          time.Duration
+	idleTimer     uint64  // ID of the pending TimerIdle, guarded by mu
+	refs          tracker // references taken via Scope.Retain
+	exitOnRelease bool
+	reconfig      bool       // set by the options that Scope.Configure accepts
+	moving        sync.Mutex // serializes Scope.Reparent
+	// conf guards the fields below which may be changed after construction
+	// via Scope.Configure or Scope.Reparent.
+	conf      sync.RWMutex
+	parent    *Scope
+	detach    func()
+	errors    chan error
+	ownErrs   bool
+	propagate bool
+	timeout   time.Duration
+	onErr     func(error)
 }
 
 // ScopeOpt is a type for optional parameters to the Scope constructors.
@@ -45,25 +140,125 @@ type ScopeOpt func(*Scope)
 
 // WithErrorChan yields a ScopeOpt that allows the creation of a new Scope that
 // will use the `chan error` supplied here as its internal error channel
-// (observable via Scope.Err).
+// (observable via Scope.Err). It may also be applied via Scope.Configure.
 func WithErrorChan(errs chan error) ScopeOpt {
 	return func(s *Scope) {
 		s.errors = errs
+		s.ownErrs = true
+		s.reconfig = true
+	}
+}
+
+// WithScopeErrorHandler yields a ScopeOpt that installs a persistent handler
+// for the errors of the new Scope and, unless they install their own, of its
+// descendants, so that error handling does not depend on who calls Exit. The
+// handler receives the errors passed to Scope.ReportErr in place of the
+// Scope's error channel, including errors reported asynchronously by helpers
+// and those forwarded by descendants created with WithErrorPropagation, and
+// serves as the default error handler for every Exit of the Scope (see
+// WithErrorHandler, which overrides it for a single Exit). The *SpawnError of
+// a failed Spawner is passed to the handler as well as being returned to the
+// caller of Scope.Spawn (or Transaction.Commit). The handler may be called
+// concurrently from several goroutines. It may also be applied via
+// Scope.Configure.
+func WithScopeErrorHandler(fn func(error)) ScopeOpt {
+	return func(s *Scope) {
+		s.onErr = fn
+		s.reconfig = true
+	}
+}
+
+// WithName yields a ScopeOpt that assigns a human-readable name to the new
+// Scope. The name is used to annotate errors that propagate out of the Scope.
+func WithName(name string) ScopeOpt {
+	return func(s *Scope) {
+		s.name = name
+	}
+}
+
+// WithErrorPropagation yields a ScopeOpt that causes errors reported to a
+// child Scope to be forwarded to the nearest ancestor Scope that consumes its
+// own errors (i.e. a root Scope, a Scope created with WithErrorChan, or a Scope
+// created without this option). Errors forwarded via Scope.ReportErr are
+// wrapped with the name of each Scope through which they pass. This option has
+// no effect on root Scopes. It may also be applied via Scope.Configure.
+func WithErrorPropagation() ScopeOpt {
+	return func(s *Scope) {
+		s.propagate = true
+		s.reconfig = true
+	}
+}
+
+// WithExitTimeout yields a ScopeOpt that bounds the duration of every Exit of
+// the new Scope: the context passed to Scope.Exit is given a timeout of the
+// supplied duration. A duration of zero (the default) imposes no bound. It may
+// also be applied via Scope.Configure.
+func WithExitTimeout(d time.Duration) ScopeOpt {
+	return func(s *Scope) {
+		s.timeout = d
+		s.reconfig = true
+	}
+}
+
+// WithInheritedOpts yields a ScopeOpt that causes the supplied options to be
+// applied to every descendant of the new Scope, though not to the Scope
+// itself, so that policy (e.g. Observers, resource limits or error
+// propagation) can be enforced on a subtree whose Scopes are created by other
+// code. They are applied after the descendant's own options, which therefore
+// cannot override them, and accumulate with those inherited from ancestors.
+// See also Enforce.
+func WithInheritedOpts(opts ...ScopeOpt) ScopeOpt {
+	return func(s *Scope) {
+		s.inherited = join(s.inherited[:len(s.inherited):len(s.inherited)], opts)
+	}
+}
+
+// WithDefaultExitOpts yields a ScopeOpt that supplies ExitOpts to be applied
+// whenever Scope.Exit is called on the new Scope or, unless they supply their
+// own defaults, on its descendants. They are applied before those passed to
+// Scope.Exit, which therefore take precedence. This allows code that creates
+// Scopes internally, e.g. via a Scoper, to get the intended teardown behavior
+// without every Exit call site repeating it. Exits triggered automatically,
+// e.g. by a timer or signal, are affected likewise.
+func WithDefaultExitOpts(opts ...ExitOpt) ScopeOpt {
+	return func(s *Scope) {
+		s.exitOpts = opts
 	}
 }
 
 // NewScope instantiates a Scope with the supplied options. The new Scope is
-// immediately usable and remains so until Scope.Exit is invoked.
+// immediately usable and remains so until Scope.Exit is invoked. The Scope may
+// be one previously returned via Scope.Recycle.
 func NewScope(opts ...ScopeOpt) *Scope {
-	s := &Scope{
-		state:    active,
-		errors:   make(chan error),
-		children: list.New(),
-		detach:   func() {},
+	s := newScope(nil, opts)
+	s.observeCreated()
+	s.expire()
+	return s
+}
+
+// newScope instantiates a Scope that inherits from the supplied parent, if
+// any, and then applies the supplied options. The caller must attach the new
+// Scope to the parent.
+func newScope(parent *Scope, opts []ScopeOpt) *Scope {
+	s := recycled.Get().(*Scope)
+	s.state = active
+	s.clock = RealClock{}
+	s.detach = func() {}
+	if parent != nil {
+		s.inherit(parent)
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	if parent != nil {
+		for _, opt := range parent.inherited {
+			opt(s)
+		}
+	}
+	s.created = s.clock.Now()
+	if s.stats != nil {
+		s.stats.scopesCreated.Add(1)
+	}
 	return s
 }
 
@@ -72,44 +267,319 @@ func NewScope(opts ...ScopeOpt) *Scope {
 // NewChildScope is called on a Scope instance that has aready exited, a Scope
 // pointer will still be returned however that Scope will be useless as the
 // child scope will inherit the state (in this case the exited state) of the
-// creating parent.
+// creating parent. Likewise, if the parent is still exiting (e.g. when called
+// from one of its Reapers), Spawns into the returned Scope fail with a "scope
+// is exiting" error naming it. Callers that need to tell these cases apart
+// from a new child should use NewChildScopeErr.
 func (s *Scope) NewChildScope(opts ...ScopeOpt) *Scope {
+	child, err := s.NewChildScopeErr(opts...)
+	switch {
+	case err == nil:
+		return child
+	case errors.Is(err, ErrScopeLimit):
+		return s.refused(opts)
+	}
+	return s
+}
+
+// NewChildScopeErr is NewChildScope for callers that must not mistake the
+// parent for a new child: rather than returning this Scope when it is exiting
+// or has exited it returns a nil Scope and an error matching ErrExiting or
+// ErrScopeDone respectively, and rather than returning an already exited
+// Scope when WithChildLimit is exceeded it returns an error matching
+// ErrScopeLimit.
+func (s *Scope) NewChildScopeErr(opts ...ScopeOpt) (*Scope, error) {
 	parent := s
 	parent.mu.Lock()
-	defer parent.mu.Unlock()
 	if parent.state != active {
-		return s
+		err := stateError{"create child of", parent.state}
+		parent.mu.Unlock()
+		return nil, err
+	}
+	if err := parent.childLimited(); err != nil {
+		parent.mu.Unlock()
+		return nil, parent.breached(err)
+	}
+	child := newScope(parent, opts)
+	child.parent = parent
+	child.tracker.parent = parent.tracker
+	child.inflight.parent = parent.inflight
+	child.guard.parent = parent.guard
+	child.detach = parent.attach(child)
+	parent.recordOwnership(OwnershipAttach, child, 0)
+	parent.mu.Unlock()
+	child.observeCreated()
+	child.expire()
+	return child, nil
+}
+
+// attach adds the supplied child to this Scope's children and returns a func
+// that removes it again. The caller must hold s.mu.
+func (s *Scope) attach(child *Scope) (detach func()) {
+	child.slot = len(s.children)
+	child.attached = true
+	s.children = append(s.children, child)
+	s.kids++
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.releaseLocked(child)
+		if s.removeChild(child) {
+			s.recordOwnership(OwnershipDetach, child, 0)
+		}
+	}
+}
+
+// releaseLocked stops counting the supplied child as one of this Scope's
+// attached children, once it has exited or been moved away (see
+// Scope.Recycle). The caller must hold s.mu.
+func (s *Scope) releaseLocked(child *Scope) {
+	if child.attached {
+		child.attached = false
+		s.kids--
 	}
-	child := NewScope(opts...)
-	ele := parent.children.PushBack(child)
-	child.detach = func() {
-		parent.mu.Lock()
-		defer parent.mu.Unlock()
-		parent.children.Remove(ele)
+}
+
+// removeChild removes the supplied child from this Scope's children,
+// reporting whether it was present; it will not be if this Scope has exited
+// in the meantime. Removal leaves a hole so that creation order, and hence
+// exit order, is preserved; holes are trimmed from the end immediately and
+// compacted away once they make up half of the slice. The caller must hold
+// s.mu.
+func (s *Scope) removeChild(child *Scope) bool {
+	i := child.slot
+	if i >= len(s.children) || s.children[i] != child {
+		return false
+	}
+	s.children[i] = nil
+	s.holes++
+	for n := len(s.children); n > 0 && s.children[n-1] == nil; n-- {
+		s.children = s.children[:n-1]
+		s.holes--
+	}
+	if s.holes > 16 && s.holes > len(s.children)/2 {
+		live := s.children[:0]
+		for _, c := range s.children {
+			if c != nil {
+				c.slot = len(live)
+				live = append(live, c)
+			}
+		}
+		for j := len(live); j < len(s.children); j++ {
+			s.children[j] = nil
+		}
+		s.children = live
+		s.holes = 0
+	}
+	return true
+}
+
+// inherit copies the settings that a child Scope inherits from the supplied
+// parent to this Scope. They are applied before (and so may be overridden by)
+// the child's own options.
+func (s *Scope) inherit(parent *Scope) {
+	s.clock = parent.clock
+	s.onOwn = parent.onOwn
+	s.encoder = parent.encoder
+	s.exitOpts = parent.exitOpts
+	s.stats = parent.stats
+	s.goroutines = parent.goroutines
+	s.profiled = parent.profiled
+	s.observers = parent.observers
+	s.inherited = parent.inherited
+	s.interceptors = parent.interceptors
+	s.onErr = parent.errHandler()
+}
+
+// reaper is a Reaper as stored by a Scope along with the attributes assigned
+// to it at spawn time.
+type reaper struct {
+	fn       Reaper
+	class    Class
+	check    *healthCheck
+	onDrain  func(context.Context)
+	onWait   func(context.Context) error
+	resource Resource
+	res      *reservation
+	handle   *Handle
+	weight   int
+	forced   Reaper
+	deps     []*Handle
+	labels   []string
+	onEvent  func(context.Context, interface{})
+	values   *spawnValues
+	priority int
+	critical bool
+	async    bool
+}
+
+// SpawnOpt is a type for optional parameters to the Scope.Spawn function.
+type SpawnOpt func(*reaper)
+
+// WithClass yields a SpawnOpt that assigns the spawned object's Reaper to the
+// supplied Class. See WithAbandonPolicy.
+func WithClass(c Class) SpawnOpt {
+	return func(r *reaper) {
+		r.class = c
 	}
-	return child
 }
 
 // Spawn invokes the supplied Spawner function and stores the returned Reaper
 // for execution when this Scope exits. If the Spawner returns an error, that
-// error is propagated as the retun value from this function. If this Scope has
-// already exited then this function will return an error.
-func (s *Scope) Spawn(ctx context.Context, sp Spawner) error {
+// error is propagated as the retun value from this function, wrapped in a
+// *SpawnError so that it still matches via errors.Is. If this Scope is
+// exiting or has exited then this function will return an error matching
+// ErrExiting or ErrScopeDone respectively. The Spawner runs without holding
+// this Scope's lock so that a slow Spawner does not hold up concurrent Spawns
+// (and may itself Spawn into this Scope); Reapers are therefore ordered by
+// when their Spawner returned. If this Scope begins exiting while the Spawner
+// runs then the returned Reaper is invoked immediately and an error is
+// returned.
+func (s *Scope) Spawn(ctx context.Context, sp Spawner, opts ...SpawnOpt) error {
+	r, err := s.prepare(ctx, sp, opts)
+	if err != nil {
+		return err
+	}
+	fn := r.fn
+	s.mu.Lock()
+	if err := s.spawnableLocked(); err != nil {
+		s.mu.Unlock()
+		return s.breached(errors.Join(err, fn(context.Background())))
+	}
+	if r.handle != nil && r.handle.Scope() != nil {
+		s.mu.Unlock()
+		return errors.Join(errHandleInUse, fn(context.Background()))
+	}
+	s.adopt(r)
+	s.recordOwnership(OwnershipAcquire, nil, len(s.reapers))
+	s.mu.Unlock()
+	if len(s.observers) > 0 {
+		s.observeSpawn(ctx, r)
+	}
+	return nil
+}
+
+// prepare applies the supplied SpawnOpts and invokes the supplied Spawner,
+// returning the resulting reaper ready to be adopted by this Scope.
+func (s *Scope) prepare(ctx context.Context, sp Spawner, opts []SpawnOpt) (reaper, error) {
+	if err := s.spawnable(); err != nil {
+		return reaper{}, s.breached(err)
+	}
+	var r reaper
+	if len(opts) > 0 {
+		r = spawnOpts(opts)
+	}
+	if r.handle != nil && r.handle.Scope() != nil {
+		return reaper{}, errHandleInUse
+	}
+	if len(s.interceptors) > 0 {
+		sp = s.intercept(sp)
+		if r.forced != nil {
+			r.forced = s.interceptReaper(r.forced)
+		}
+	}
+	if r.resource != "" {
+		r.res = &reservation{}
+		sp = s.reserve(r.resource, r.res, sp)
+	}
+	if r.values != nil {
+		r.values = r.values.capture(ctx)
+	}
+	fn, err := sp(ctx)
+	if err != nil {
+		err = &SpawnError{ScopePath: s.Path(), Err: err}
+		if handle := s.errHandler(); handle != nil {
+			handle(err)
+		}
+		return reaper{}, err
+	}
+	r.fn = fn
+	return r, nil
+}
+
+// spawnable returns an error if this Scope no longer accepts Spawns.
+func (s *Scope) spawnable() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.spawnableLocked()
+}
+
+// spawnableLocked is spawnable for callers that hold s.mu.
+func (s *Scope) spawnableLocked() error {
+	if s.state == closing {
+		return s.misuse("Spawn", ErrExiting)
+	}
 	if s.state != active {
-		return fmt.Errorf("cannot spawn in scope with state %q", s.state)
+		return stateError{"spawn in", s.state}
 	}
-	r, err := sp(ctx)
-	if err != nil {
-		return err
+	return s.reaperLimited()
+}
+
+// spawnOpts applies the supplied SpawnOpts to a new reaper. It is kept apart
+// from Spawn so that the reaper escapes to the heap only when options are
+// supplied.
+func spawnOpts(opts []SpawnOpt) reaper {
+	r := new(reaper)
+	for _, opt := range opts {
+		opt(r)
 	}
+	return *r
+}
+
+// adopt stores the supplied reaper, and the hooks registered with it, in this
+// Scope. The caller must hold s.mu.
+func (s *Scope) adopt(r reaper) {
 	s.reapers = append(s.reapers, r)
-	return nil
+	if s.stats != nil {
+		s.stats.reapersPending.Add(1)
+	}
+	if r.check != nil {
+		s.checks = append(s.checks, *r.check)
+	}
+	if r.onDrain != nil {
+		s.drainers = append(s.drainers, r.onDrain)
+	}
+	if r.onWait != nil {
+		s.waiters = append(s.waiters, r.onWait)
+	}
+	if r.onEvent != nil {
+		s.subscribers = append(s.subscribers, r.onEvent)
+	}
+	if r.handle != nil {
+		r.handle.bind(s)
+	}
 }
 
 type exitCfg struct {
-	onError func(err error)
+	mu       sync.Mutex // serializes error handling and counters
+	onError  func(err error)
+	policies map[Class]AbandonPolicy
+	retry    map[Class]bool
+	janitor  *Janitor
+	escalate func(err error)
+	clock    Clock
+	inflight bool
+	tracker  *ExitTracker
+	limiter  Limiter
+	interval time.Duration
+	parallel int
+	subtrees int
+	budget   bool
+	reason   error
+	forced   bool
+	stall    time.Duration
+	onStall  func(StallInfo)
+	watchdog time.Duration
+	forget   bool
+	keep     func(err error) bool
+	skipped  bool // see WithAbandonSkipped
+	errs     []error
+
+	// counters maintained for the ExitTracker
+	invoked   int
+	failed    int
+	abandoned int
+	leaked    int
 }
 
 // ExitOpt is a type for optional parameters to the Scope.Exit function.
@@ -128,62 +598,363 @@ func WithErrorHandler(eh func(err error)) ExitOpt {
 // scopes in the reverse order of creation and then invoking all of it's managed
 // Reaper functions again in the reverse of the order in which they were
 // spawned. The *only* error emitted by this function is a if the supplied
-// context.Context
+// context.Context is done before teardown completes. Reapers that are cut off
+// by the context while running are handled according to the AbandonPolicy of
+// their Class; those not yet invoked when it is done are skipped without being
+// reported (see WithAbandonSkipped), though every descendant is still marked
+// exited.
+// The whole subtree is marked unhealthy (see Scope.Healthy) before any Reaper
+// is invoked. Reapers run without this Scope's lock held, so a Reaper may
+// inspect or report errors to the Scope that owns it; Spawns during teardown
+// fail with an error naming the Scope. A concurrent call waits for the
+// teardown in progress to complete, returning its ExitErr (or the context's
+// error if the context is done first), except that a Reaper which exits a
+// Scope in the tree being torn down, passing on the context it was given,
+// gets a "scope is exiting" error naming the Scope rather than deadlocking. If
+// this Scope's Exit has been delegated (see Scope.DelegateExit) then
+// ErrDelegated is returned and nothing is exited. If references to this Scope
+// are outstanding (see Scope.Retain) then Exit first waits for them to be
+// released, returning the context's error, without exiting anything, if it is
+// done first.
 func (s *Scope) Exit(ctx context.Context, opts ...ExitOpt) error {
-	ec := exitCfg{
-		onError: func(err error) {},
+	if s.delegated() {
+		return ErrDelegated
 	}
-	for _, opt := range opts {
-		opt(&ec)
+	return s.exitOwned(ctx, opts...)
+}
+
+// exitOwned is Exit for the party entitled to exit this Scope: the ExitFunc
+// handed to an ExitOwner by Scope.DelegateExit.
+func (s *Scope) exitOwned(ctx context.Context, opts ...ExitOpt) error {
+	if s.reentered(ctx) {
+		return s.misuse("Exit", ErrExiting)
+	}
+	if err := s.refs.wait(ctx); err != nil {
+		return err
+	}
+	return s.exitScope(ctx, opts...)
+}
+
+// teardownKey is the context key under which the exitCfg of an Exit is
+// passed to the Reapers it invokes, allowing calls back into the tree being
+// torn down to be detected.
+type teardownKey struct{}
+
+// reentered reports whether the supplied context was passed to a Reaper by
+// the Exit that is currently tearing down this Scope.
+func (s *Scope) reentered(ctx context.Context) bool {
+	ec, _ := ctx.Value(teardownKey{}).(*exitCfg)
+	if ec == nil {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state == closing && s.closer == ec
+}
+
+// misuse describes a call, named op, that this Scope cannot accept.
+func (s *Scope) misuse(op string, err error) error {
+	return fmt.Errorf("%s on scope %s: %w", op, s.Path(), err)
+}
+
+func (s *Scope) exitScope(ctx context.Context, opts ...ExitOpt) error {
+	ec := s.newExitCfg(opts)
+	if timeout := s.exitTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = ClockTimeout(ctx, s.clock, timeout)
+		defer cancel()
+	}
+	ctx = context.WithValue(ctx, teardownKey{}, ec)
+	start := s.clock.Now()
+	log := s.postmortem()
+	var snap ScopeSnapshot
+	if log != nil {
+		snap = s.Snapshot()
+	}
+	s.markExiting()
+	if ec.inflight {
+		s.inflight.wait(ctx)
+		s.waitExternal(ctx)
+	}
+	err := s.exit(ctx, ec)
+	s.detacher()()
+	if ec.tracker != nil {
+		ec.tracker.record(s, start, ec, err)
+	}
+	if s.stats != nil {
+		ec.mu.Lock()
+		s.stats.reapErrors.Add(int64(ec.failed))
+		s.stats.reapersAbandoned.Add(int64(ec.abandoned))
+		ec.mu.Unlock()
+	}
+	if log != nil && snap.State == string(active) {
+		log.append(ExitedScope{snap, ec.report(start, s.clock.Now().Sub(start), err)})
 	}
-	err := s.exit(ctx, &ec)
-	s.detach()
 	return err
 }
 
-// Err observes this Scope's asynchronous error channel.
+// newExitCfg returns the configuration for an Exit of this Scope given the
+// ExitOpts supplied by the caller, which are applied after this Scope's
+// defaults (see WithDefaultExitOpts).
+func (s *Scope) newExitCfg(opts []ExitOpt) *exitCfg {
+	ec := &exitCfg{
+		onError:  func(err error) {},
+		escalate: escalate,
+		clock:    s.clock,
+	}
+	if fn := s.errHandler(); fn != nil {
+		ec.onError = fn
+	}
+	for _, opt := range s.exitOpts {
+		opt(ec)
+	}
+	for _, opt := range opts {
+		opt(ec)
+	}
+	if ec.limiter == nil && ec.interval > 0 {
+		ec.limiter = &clockLimiter{clock: s.clock, interval: ec.interval}
+	}
+	return ec
+}
+
+// ExitAsync begins exiting this Scope on a new goroutine and returns a channel
+// on which the result of the Exit will be delivered. The channel is closed
+// after the result is delivered so it can be used directly in a select
+// statement alongside other events. The supplied context and ExitOpts are
+// interpreted exactly as by Scope.Exit.
+func (s *Scope) ExitAsync(ctx context.Context, opts ...ExitOpt) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		defer close(done)
+		done <- s.Exit(ctx, opts...)
+	}()
+	return done
+}
+
+// Err observes this Scope's asynchronous error channel. For a Scope created
+// with WithErrorPropagation this is the channel of the ancestor Scope to which
+// errors are forwarded.
 func (s *Scope) Err() chan error {
+	return s.errSink().errChan()
+}
+
+// Name returns the name assigned to this Scope via WithName.
+func (s *Scope) Name() string {
+	return s.name
+}
+
+// Path returns the names of this Scope and its ancestors, root first,
+// separated by slashes. Unnamed Scopes appear as "(unnamed)".
+func (s *Scope) Path() string {
+	path := displayName(s.name)
+	for p := s.up(); p != nil; p = p.up() {
+		path = displayName(p.name) + "/" + path
+	}
+	return path
+}
+
+// ReportErr delivers the supplied error to this Scope's error channel,
+// blocking until it is received, or to its error handler if it has one (see
+// WithScopeErrorHandler). If this Scope was created with WithErrorPropagation
+// the error is instead forwarded up the tree, wrapped with the name of each
+// forwarding Scope, until it reaches a Scope that consumes its own errors.
+func (s *Scope) ReportErr(err error) {
+	s.reportErr(err, true)
+}
+
+// OfferErr is ReportErr for callers that must not block, such as background
+// goroutines and automatic Exits: if the error reaches an error channel on
+// which nothing is ready to receive, it is dropped.
+func (s *Scope) OfferErr(err error) {
+	s.reportErr(err, false)
+}
+
+// reportErr implements ReportErr and OfferErr.
+func (s *Scope) reportErr(err error, block bool) {
+	s.lastErr.Store(&reportedErr{err: err, at: s.clock.Now()})
+	sink := s
+	for sink.forwards() {
+		if sink.name != "" {
+			err = fmt.Errorf("%s: %w", sink.name, err)
+		}
+		sink = sink.up()
+	}
+	if fn := sink.errHandler(); fn != nil {
+		fn(err)
+		return
+	}
+	if block {
+		sink.errChan() <- err
+		return
+	}
+	select {
+	case sink.errChan() <- err:
+	default:
+	}
+}
+
+// childScopes returns the current children of this Scope in creation order.
+// The caller must hold s.mu.
+func (s *Scope) childScopes() []*Scope {
+	children := make([]*Scope, 0, len(s.children)-s.holes)
+	for _, c := range s.children {
+		if c != nil {
+			children = append(children, c)
+		}
+	}
+	return children
+}
+
+func (s *Scope) forwards() bool {
+	s.conf.RLock()
+	defer s.conf.RUnlock()
+	return s.propagate && !s.ownErrs && s.parent != nil
+}
+
+// errHandler returns the handler set with WithScopeErrorHandler, if any.
+func (s *Scope) errHandler() func(error) {
+	s.conf.RLock()
+	defer s.conf.RUnlock()
+	return s.onErr
+}
+
+// errChan returns this Scope's own error channel, creating it on first use
+// as most Scopes never report or observe errors.
+func (s *Scope) errChan() chan error {
+	s.conf.RLock()
+	errs := s.errors
+	s.conf.RUnlock()
+	if errs != nil {
+		return errs
+	}
+	s.conf.Lock()
+	defer s.conf.Unlock()
+	if s.errors == nil {
+		s.errors = make(chan error)
+	}
 	return s.errors
 }
 
+func (s *Scope) exitTimeout() time.Duration {
+	s.conf.RLock()
+	defer s.conf.RUnlock()
+	return s.timeout
+}
+
+func (s *Scope) errSink() *Scope {
+	sink := s
+	for sink.forwards() {
+		sink = sink.up()
+	}
+	return sink
+}
+
+// isDone reports whether this Scope has exited.
+func (s *Scope) isDone() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state != active
+}
+
+// up returns this Scope's parent, or nil for a root Scope.
+func (s *Scope) up() *Scope {
+	s.conf.RLock()
+	defer s.conf.RUnlock()
+	return s.parent
+}
+
+// within reports whether this Scope is, or currently descends from, the
+// supplied Scope.
+func (s *Scope) within(ancestor *Scope) bool {
+	for ; s != nil; s = s.up() {
+		if s == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scope) detacher() func() {
+	s.conf.RLock()
+	defer s.conf.RUnlock()
+	return s.detach
+}
+
+// exit tears down this Scope and its descendants. The children and reapers
+// to be reaped are captured under s.mu but run without it so that a Reaper
+// may still use (though not exit) the Scope that owns it. A concurrent call
+// waits for the teardown already in progress to complete, returning its
+// ExitErr, or for ctx to be done.
 func (s *Scope) exit(ctx context.Context, ec *exitCfg) error {
 	s.mu.Lock()
-	defer func() {
-		s.reapers = make([]Reaper, 0)
-		s.children = s.children.Init()
-		s.state = done
+	switch s.state {
+	case closing:
+		wait := s.doneLocked()
+		s.mu.Unlock()
+		select {
+		case <-wait:
+			return s.ExitErr()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case done:
 		s.mu.Unlock()
-	}()
-	if s.state != active {
 		return nil
 	}
-	for ele := s.children.Back(); ele != nil; ele = ele.Prev() {
-		err := ele.Value.(*Scope).exit(ctx, ec)
-		if err != nil && err != ctx.Err() {
-			ec.onError(err)
-		}
-		if ctxerr := ctx.Err(); ctxerr != nil {
-			return ctxerr
-		}
+	s.state = closing
+	s.closer = ec
+	children := s.childScopes()
+	reapers := ordered(s.reapers)
+	s.stopTimers()
+	s.mu.Unlock()
+	var start time.Time
+	if len(s.observers) > 0 {
+		start = s.observeExitStart(ctx)
 	}
-	for i := len(s.reapers) - 1; i >= 0; i-- {
-		err := s.reapers[i](ctx)
-		if err != nil && err != ctx.Err() {
-			ec.onError(err)
-		}
-		if ctxerr := ctx.Err(); ctxerr != nil {
-			return ctxerr
-		}
+
+	b := ec.newBudget(children, reapers)
+	ec.exitAll(ctx, b, children)
+	ec.reapAll(ctx, s, b, reapers)
+	result := s.exitResult(ctx, children)
+
+	s.mu.Lock()
+	s.recordOwnership(OwnershipRelease, nil, len(reapers))
+	if s.stats != nil {
+		s.stats.reapersPending.Add(-int64(len(s.reapers)))
+		s.stats.scopesExited.Add(1)
 	}
-	return nil
+	s.reapers = nil
+	s.checks = nil
+	s.drainers = nil
+	s.waiters = nil
+	s.subscribers = nil
+	s.children = nil
+	s.holes = 0
+	s.state = done
+	s.closer = nil
+	s.exitErr = result
+	if s.joined != nil {
+		close(s.joined)
+		s.joined = nil
+	}
+	s.mu.Unlock()
+	if p := s.up(); p != nil {
+		p.mu.Lock()
+		p.releaseLocked(s)
+		p.mu.Unlock()
+	}
+	if len(s.observers) > 0 {
+		s.observeExitEnd(ctx, start)
+	}
+	return ctx.Err()
 }
 
 // MustSpawn is a helper function that passes the supplied Spawner to the
 // Scope.Spawn function on the supplied Scope instance with the provided
 // context. If an error is returned from Scope.Spawn then this function will
 // panic.
-func MustSpawn(ctx context.Context, sc *Scope, sp Spawner) {
-	err := sc.Spawn(ctx, sp)
+func MustSpawn(ctx context.Context, sc *Scope, sp Spawner, opts ...SpawnOpt) {
+	err := sc.Spawn(ctx, sp, opts...)
 	if err != nil {
 		panic(err)
 	}
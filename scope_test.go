@@ -3,6 +3,8 @@ package nls_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -73,7 +75,7 @@ func TestExitErrorHandler(t *testing.T) {
 	err = s.Exit(context.TODO(),
 		nls.WithErrorHandler(func(err error) { got = err }))
 	require(t, err == nil, "unexpected error: %q", err)
-	require(t, want == got,
+	require(t, errors.Is(got, want),
 		"expected error handler invocation with %#v (got %#v)", want, got)
 }
 
@@ -96,7 +98,7 @@ func TestSyncSpawnError(t *testing.T) {
 	got := s.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
 		return nil, want
 	})
-	require(t, want == got, "expected error. want: %q, got: %q", want, got)
+	require(t, errors.Is(got, want), "expected error. want: %q, got: %q", want, got)
 }
 
 const (
@@ -171,7 +173,8 @@ func TestExitTimeout(t *testing.T) {
 		}, nil
 	})
 
-	ctx, _ := context.WithDeadline(context.Background(), time.Now())
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
 	err := s.Exit(ctx)
 	require(t, err == context.DeadlineExceeded, "expected context error")
 }
@@ -185,3 +188,416 @@ func TestMustSpawn(t *testing.T) {
 			return nil, errors.New("testerr")
 		})
 }
+
+func TestErrorPropagation(t *testing.T) {
+	want := errors.New(t.Name())
+	out := make(chan error)
+	root := nls.NewScope(nls.WithErrorChan(out))
+	mid := root.NewChildScope(nls.WithName("mid"), nls.WithErrorPropagation())
+	leaf := mid.NewChildScope(nls.WithName("leaf"), nls.WithErrorPropagation())
+	require(t, leaf.Err() == out, "expected propagating scope to expose "+
+		"the consumer's error channel")
+
+	go leaf.ReportErr(want)
+	got := <-out
+	require(t, errors.Is(got, want), "expected wrapped error, got %q", got)
+	require(t, got.Error() == "mid: leaf: "+want.Error(),
+		"unexpected error text %q", got)
+
+	isolated := mid.NewChildScope(nls.WithName("isolated"))
+	require(t, isolated.Err() != out,
+		"expected non-propagating child to own its error channel")
+}
+
+func TestExitAsync(t *testing.T) {
+	s := nls.NewScope()
+	svc := new(testProcess)
+	nls.MustSpawn(context.TODO(), s, svc.Spawn)
+
+	select {
+	case err := <-s.ExitAsync(context.TODO()):
+		require(t, err == nil, "unexpected error from Scope.ExitAsync: %q", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected Scope.ExitAsync to complete")
+	}
+	require(t, svc.Is(reaped), "expected svc to have been reaped")
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	blocked := nls.NewScope()
+	nls.MustSpawn(context.TODO(), blocked, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil
+	})
+	err := <-blocked.ExitAsync(ctx)
+	require(t, err == context.DeadlineExceeded, "expected context error")
+}
+
+func TestScopeExitTimeout(t *testing.T) {
+	s := nls.NewScope(nls.WithExitTimeout(time.Millisecond))
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil
+	})
+	err := s.Exit(context.Background())
+	require(t, err == context.DeadlineExceeded, "expected context error")
+}
+
+func TestNewChildScopeErr(t *testing.T) {
+	s := nls.NewScope(nls.WithChildLimit(1))
+	child, err := s.NewChildScopeErr()
+	require(t, err == nil && child != nil && child != s, "unexpected result %v, %v", child, err)
+	_, err = s.NewChildScopeErr()
+	require(t, errors.Is(err, nls.ErrScopeLimit), "expected limit error, got %v", err)
+
+	var exiting error
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			_, exiting = s.NewChildScopeErr()
+			return nil
+		}, nil
+	})
+	s.Exit(context.TODO())
+	require(t, errors.Is(exiting, nls.ErrExiting), "expected ErrExiting, got %v", exiting)
+
+	child, err = s.NewChildScopeErr()
+	require(t, child == nil && errors.Is(err, nls.ErrScopeDone),
+		"expected ErrScopeDone, got %v, %v", child, err)
+	require(t, s.NewChildScope() == s, "expected NewChildScope to keep returning the parent")
+}
+
+func TestChildRemovalPreservesExitOrder(t *testing.T) {
+	root := nls.NewScope()
+	var order []int
+	children := make([]*nls.Scope, 100)
+	for i := range children {
+		i := i
+		children[i] = root.NewChildScope()
+		nls.MustSpawn(context.TODO(), children[i], func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				order = append(order, i)
+				return nil
+			}, nil
+		})
+	}
+	for i := 99; i >= 0; i -= 3 {
+		children[i].Exit(context.TODO())
+	}
+	for i := 1; i < 100; i += 3 {
+		children[i].Exit(context.TODO())
+	}
+	order = nil
+	root.Exit(context.TODO())
+	require(t, len(order) == 33, "expected 33 remaining children, got %v", len(order))
+	for j := 1; j < len(order); j++ {
+		require(t, order[j] < order[j-1], "expected reverse creation order, got %v", order)
+	}
+	require(t, len(root.Snapshot().Children) == 0, "expected no children after exit")
+
+	// Exiting a child after its parent has exited must not disturb the
+	// parent's (now empty) set of children.
+	parent := nls.NewScope()
+	late := parent.NewChildScope()
+	parent.Exit(context.TODO())
+	late.Exit(context.TODO())
+	require(t, len(parent.Snapshot().Children) == 0, "expected no children")
+}
+
+func BenchmarkNewChildScope(b *testing.B) {
+	root := nls.NewScope()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		root.NewChildScope().Exit(context.TODO())
+	}
+}
+
+func TestLazyErrChan(t *testing.T) {
+	s := nls.NewScope()
+	chans := make(chan chan error, 8)
+	for i := 0; i < cap(chans); i++ {
+		go func() { chans <- s.Err() }()
+	}
+	first := <-chans
+	for i := 1; i < cap(chans); i++ {
+		require(t, <-chans == first, "expected a single error channel")
+	}
+	go s.ReportErr(errors.New("boom"))
+	err := <-s.Err()
+	require(t, err != nil && err.Error() == "boom", "unexpected error %v", err)
+}
+
+func TestSlowSpawnerDoesNotBlockSiblings(t *testing.T) {
+	s := nls.NewScope()
+	defer s.Exit(context.TODO())
+	release := make(chan struct{})
+	started := make(chan struct{})
+	slow := make(chan error, 1)
+	go func() {
+		slow <- s.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+			close(started)
+			<-release
+			return nilReaper, nil
+		})
+	}()
+	<-started
+	done := make(chan error, 1)
+	go func() { done <- s.Spawn(context.TODO(), nopSpawner) }()
+	select {
+	case err := <-done:
+		require(t, err == nil, "unexpected error %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("spawn blocked behind a slow spawner")
+	}
+	close(release)
+	require(t, <-slow == nil, "unexpected error from slow spawn")
+}
+
+func TestExitDuringSpawnReapsImmediately(t *testing.T) {
+	s := nls.NewScope()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	reaped := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) {
+			close(started)
+			<-release
+			return func(context.Context) error {
+				close(reaped)
+				return nil
+			}, nil
+		})
+	}()
+	<-started
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	close(release)
+	require(t, <-done != nil, "expected spawn into exited scope to fail")
+	select {
+	case <-reaped:
+	default:
+		t.Fatal("expected reaper of late spawn to have run")
+	}
+}
+
+func TestReaperMayUseOwningScope(t *testing.T) {
+	s := nls.NewScope()
+	child := s.NewChildScope()
+	var spawnErr error
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			_ = child.Snapshot()
+			_ = s.Path()
+			spawnErr = child.Spawn(context.TODO(), nopSpawner)
+			return nil
+		}, nil
+	})
+	done := s.ExitAsync(context.TODO())
+	select {
+	case err := <-done:
+		require(t, err == nil, "unexpected exit error %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("reaper deadlocked on its owning scope")
+	}
+	require(t, spawnErr != nil, "expected spawn during teardown to fail")
+}
+
+func TestConcurrentExitWaitsForTeardown(t *testing.T) {
+	s := nls.NewScope()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			close(started)
+			<-release
+			return nil
+		}, nil
+	})
+	first := s.ExitAsync(context.TODO())
+	<-started
+	second := s.ExitAsync(context.TODO())
+	select {
+	case <-second:
+		t.Fatal("concurrent exit returned before teardown completed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	require(t, <-first == nil, "unexpected error from first exit")
+	require(t, <-second == nil, "unexpected error from second exit")
+}
+
+func TestConcurrentExitReportsOutcome(t *testing.T) {
+	boom := errors.New("boom")
+	s := nls.NewScope()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			close(started)
+			<-release
+			return boom
+		}, nil
+	})
+	first := s.ExitAsync(context.TODO())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := s.Exit(ctx)
+	require(t, err == context.DeadlineExceeded,
+		"expected concurrent exit to give up with its context, got %v", err)
+
+	second := s.ExitAsync(context.TODO())
+	select {
+	case <-second:
+		t.Fatal("concurrent exit returned before teardown completed")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(release)
+	require(t, <-first == nil, "unexpected error from first exit")
+	err = <-second
+	require(t, errors.Is(err, boom), "expected concurrent exit to report the teardown's error, got %v", err)
+}
+
+func TestReentrantCallsReportExiting(t *testing.T) {
+	s := nls.NewScope(nls.WithName("svc"))
+	var spawnErr, exitErr error
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			spawnErr = s.Spawn(ctx, nopSpawner)
+			exitErr = s.Exit(ctx)
+			return nil
+		}, nil
+	})
+	done := s.ExitAsync(context.TODO())
+	select {
+	case err := <-done:
+		require(t, err == nil, "unexpected exit error %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("reentrant exit deadlocked")
+	}
+	for op, err := range map[string]error{"Spawn": spawnErr, "Exit": exitErr} {
+		want := op + " on scope svc: scope is exiting"
+		require(t, err != nil && err.Error() == want, "expected %q, got %v", want, err)
+	}
+}
+
+func TestScopeStateErrors(t *testing.T) {
+	s := nls.NewScope()
+	var exiting, acquire error
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			exiting = s.Spawn(context.TODO(), nopSpawner)
+			acquire = s.Acquire()
+			return nil
+		}, nil
+	})
+	s.Exit(context.TODO())
+	require(t, errors.Is(exiting, nls.ErrExiting) && !errors.Is(exiting, nls.ErrScopeDone),
+		"expected spawn during teardown to match ErrExiting, got %v", exiting)
+	require(t, errors.Is(acquire, nls.ErrExiting) && !errors.Is(acquire, nls.ErrDraining),
+		"expected Acquire during teardown to match ErrExiting, got %v", acquire)
+
+	err := s.Spawn(context.TODO(), nopSpawner)
+	require(t, errors.Is(err, nls.ErrScopeDone) && !errors.Is(err, nls.ErrExiting),
+		"expected spawn after exit to match ErrScopeDone, got %v", err)
+	require(t, err.Error() == `cannot spawn in scope with state "done"`, "unexpected message %q", err)
+	var h nls.Handle
+	for _, err := range []error{
+		s.Retain(), s.DelegateExit(nil), s.Configure(), s.Acquire(),
+		s.ReapLabel(context.TODO(), "x"), s.Transfer(&h, nls.NewScope()),
+		s.Replace(context.TODO(), &h, nopSpawner),
+	} {
+		require(t, errors.Is(err, nls.ErrScopeDone), "expected ErrScopeDone, got %v", err)
+	}
+}
+
+func TestDefaultExitOpts(t *testing.T) {
+	var defaulted, explicit []error
+	s := nls.NewScope(nls.WithDefaultExitOpts(nls.WithErrorHandler(func(err error) {
+		defaulted = append(defaulted, err)
+	})))
+	failing := func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return errors.New("boom") }, nil
+	}
+
+	child := s.NewChildScope()
+	nls.MustSpawn(context.TODO(), child, failing)
+	require(t, child.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, len(defaulted) == 1, "expected child to inherit default handler, got %v", defaulted)
+
+	nls.MustSpawn(context.TODO(), s, failing)
+	err := s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) {
+		explicit = append(explicit, err)
+	}))
+	require(t, err == nil, "unexpected exit error %v", err)
+	require(t, len(defaulted) == 1 && len(explicit) == 1,
+		"expected explicit handler to take precedence, got %v and %v", defaulted, explicit)
+}
+
+func TestInheritedOpts(t *testing.T) {
+	obs := &recordingObserver{}
+	root := nls.NewScope(nls.WithName("app"),
+		nls.WithInheritedOpts(nls.WithObserver(obs), nls.WithReaperLimit(1)))
+	defer root.Exit(context.TODO())
+	nls.MustSpawn(context.TODO(), root, nopSpawner)
+	nls.MustSpawn(context.TODO(), root, nopSpawner)
+
+	plugin := root.NewChildScope(nls.WithName("plugin"), nls.WithReaperLimit(5))
+	nls.MustSpawn(context.TODO(), plugin, nopSpawner)
+	err := plugin.Spawn(context.TODO(), nopSpawner)
+	require(t, errors.Is(err, nls.ErrScopeLimit), "expected inherited limit to win, got %v", err)
+	plugin.NewChildScope(nls.WithName("sub"))
+
+	want := []string{"created app/plugin", "spawn app/plugin []", "created app/plugin/sub"}
+	require(t, fmt.Sprint(obs.events) == fmt.Sprint(want),
+		"unexpected events\n got: %q\nwant: %q", obs.events, want)
+}
+
+func TestScopeErrorHandler(t *testing.T) {
+	var mu sync.Mutex
+	var handled []error
+	handler := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, err)
+	}
+	boom, bang, fizz := errors.New("boom"), errors.New("bang"), errors.New("fizz")
+	failing := func(err error) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error { return err }, nil
+		}
+	}
+
+	root := nls.NewScope(nls.WithScopeErrorHandler(handler))
+	child := root.NewChildScope()
+	child.ReportErr(boom)
+	require(t, len(handled) == 1 && handled[0] == boom, "expected reported error to be handled, got %v", handled)
+
+	var spawnErr *nls.SpawnError
+	err := child.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) { return nil, fizz })
+	require(t, errors.As(err, &spawnErr), "expected spawn error to be returned, got %v", err)
+	require(t, len(handled) == 2 && handled[1] == err, "expected spawn error to be handled, got %v", handled)
+
+	nls.MustSpawn(context.TODO(), child, failing(bang))
+	require(t, child.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, len(handled) == 3 && errors.Is(handled[2], bang), "expected reap error to be handled, got %v", handled)
+
+	var overridden []error
+	nls.MustSpawn(context.TODO(), root, failing(fizz))
+	err = root.Exit(context.TODO(), nls.WithErrorHandler(func(err error) { overridden = append(overridden, err) }))
+	require(t, err == nil, "unexpected exit error")
+	require(t, len(overridden) == 1 && len(handled) == 3, "expected exit handler to take precedence")
+
+	own := nls.NewScope(nls.WithScopeErrorHandler(handler)).NewChildScope(nls.WithScopeErrorHandler(func(error) {}))
+	own.ReportErr(boom)
+	require(t, len(handled) == 3, "expected descendant handler to override, got %v", handled)
+
+	s := nls.NewScope()
+	require(t, s.Configure(nls.WithScopeErrorHandler(handler)) == nil, "unexpected configure error")
+	s.ReportErr(fizz)
+	require(t, len(handled) == 4 && handled[3] == fizz, "expected reconfigured handler, got %v", handled)
+}
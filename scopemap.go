@@ -0,0 +1,120 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ScopeMap manages a dynamic set of child Scopes of a common parent keyed by
+// an identifier such as a connection or tenant ID. Entries whose Scope has
+// exited, whether via the ScopeMap, directly or because the parent exited,
+// are discarded lazily. A ScopeMap is safe for concurrent use.
+type ScopeMap[K comparable] struct {
+	mu     sync.Mutex
+	parent *Scope
+	opts   []ScopeOpt
+	scopes map[K]*Scope
+}
+
+// NewScopeMap creates a ScopeMap whose Scopes are children of the supplied
+// parent, each created with the supplied options.
+func NewScopeMap[K comparable](parent *Scope, opts ...ScopeOpt) *ScopeMap[K] {
+	return &ScopeMap[K]{
+		parent: parent,
+		opts:   opts,
+		scopes: make(map[K]*Scope),
+	}
+}
+
+// Get returns the live Scope for the supplied key, if any.
+func (m *ScopeMap[K]) Get(key K) (*Scope, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.live(key)
+}
+
+// GetOrCreate returns the live Scope for the supplied key, creating it if
+// necessary. An error is returned if a Scope must be created and the parent
+// cannot create it (see Scope.NewChildScopeErr).
+func (m *ScopeMap[K]) GetOrCreate(key K) (*Scope, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.live(key); ok {
+		return s, nil
+	}
+	s, err := m.parent.NewChildScopeErr(m.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create scope for key %v: %w", key, err)
+	}
+	m.scopes[key] = s
+	return s, nil
+}
+
+// ExitKey removes the Scope for the supplied key, if any, and exits it with
+// the supplied context and options.
+func (m *ScopeMap[K]) ExitKey(ctx context.Context, key K, opts ...ExitOpt) error {
+	m.mu.Lock()
+	s, ok := m.scopes[key]
+	delete(m.scopes, key)
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return s.Exit(ctx, opts...)
+}
+
+// Exit removes and exits every Scope in this ScopeMap, returning the joined
+// errors of the individual Exits. The parent is left running.
+func (m *ScopeMap[K]) Exit(ctx context.Context, opts ...ExitOpt) error {
+	m.mu.Lock()
+	scopes := m.scopes
+	m.scopes = make(map[K]*Scope)
+	m.mu.Unlock()
+	var errs []error
+	for key, s := range scopes {
+		if err := s.Exit(ctx, opts...); err != nil {
+			errs = append(errs, fmt.Errorf("%v: %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Len returns the number of live Scopes in this ScopeMap.
+func (m *ScopeMap[K]) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.scopes {
+		m.live(key)
+	}
+	return len(m.scopes)
+}
+
+// Keys returns the keys of the live Scopes in this ScopeMap in no particular
+// order.
+func (m *ScopeMap[K]) Keys() []K {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]K, 0, len(m.scopes))
+	for key := range m.scopes {
+		if _, ok := m.live(key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// live returns the Scope for the supplied key, discarding it if it has
+// exited. The caller must hold m.mu.
+func (m *ScopeMap[K]) live(key K) (*Scope, bool) {
+	s, ok := m.scopes[key]
+	if !ok {
+		return nil, false
+	}
+	if s.isDone() {
+		delete(m.scopes, key)
+		return nil, false
+	}
+	return s, true
+}
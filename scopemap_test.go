@@ -0,0 +1,46 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestScopeMap(t *testing.T) {
+	parent := nls.NewScope()
+	m := nls.NewScopeMap[string](parent, nls.WithName("conn"))
+
+	a, err := m.GetOrCreate("a")
+	require(t, err == nil, "unexpected error: %v", err)
+	again, _ := m.GetOrCreate("a")
+	require(t, a == again, "expected the same scope for the same key")
+	require(t, a.Path() == "(unnamed)/conn", "unexpected path %q", a.Path())
+	b, _ := m.GetOrCreate("b")
+	c, _ := m.GetOrCreate("c")
+	require(t, m.Len() == 3, "expected 3 scopes, got %v", m.Len())
+
+	err = m.ExitKey(context.TODO(), "a")
+	require(t, err == nil, "unexpected error: %v", err)
+	_, ok := m.Get("a")
+	require(t, !ok, "expected a to be removed")
+	require(t, m.ExitKey(context.TODO(), "a") == nil, "expected absent key to be ignored")
+
+	b.Exit(context.TODO())
+	require(t, len(m.Keys()) == 1 && m.Keys()[0] == "c", "expected directly exited b to be pruned")
+	b2, _ := m.GetOrCreate("b")
+	require(t, b2 != b, "expected a fresh scope for b")
+
+	err = m.Exit(context.TODO())
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, m.Len() == 0, "expected all scopes removed")
+	err = c.Spawn(context.TODO(), func(context.Context) (nls.Reaper, error) { return nil, nil })
+	require(t, err != nil, "expected c to have exited")
+	_, err = m.GetOrCreate("d")
+	require(t, err == nil, "expected parent to remain running: %v", err)
+
+	parent.Exit(context.TODO())
+	require(t, m.Len() == 0, "expected scopes exited with parent to be pruned")
+	_, err = m.GetOrCreate("e")
+	require(t, err != nil, "expected error creating scope under exited parent")
+}
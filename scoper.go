@@ -0,0 +1,56 @@
+package nls
+
+// ScoperDecorator wraps a Scoper to alter the Scopes it creates. See Chain.
+type ScoperDecorator func(Scoper) Scoper
+
+// WithDefaults returns a Scoper that passes the supplied options to the
+// wrapped Scoper ahead of those supplied by its caller, which therefore take
+// precedence. Use Enforce for options that callers must not override.
+func WithDefaults(scoper Scoper, opts ...ScopeOpt) Scoper {
+	return func(more ...ScopeOpt) *Scope {
+		return scoper(join(opts, more)...)
+	}
+}
+
+// Chain returns a Scoper that applies the supplied decorators to the wrapped
+// Scoper, the first decorator being outermost. It allows a framework handing
+// a Scoper to plugins to control every Scope those plugins create, e.g.
+//
+//	plugin.Init(nls.Chain(root.NewChildScope,
+//		nls.Enforce(nls.WithResourceLimit(nls.ResourceFile, 64)),
+//		nls.Observe(register)))
+func Chain(scoper Scoper, decorators ...ScoperDecorator) Scoper {
+	for i := len(decorators) - 1; i >= 0; i-- {
+		scoper = decorators[i](scoper)
+	}
+	return scoper
+}
+
+// Enforce yields a ScoperDecorator that passes the supplied options to the
+// wrapped Scoper after those supplied by its caller, so that they take
+// precedence.
+func Enforce(opts ...ScopeOpt) ScoperDecorator {
+	return func(scoper Scoper) Scoper {
+		return func(more ...ScopeOpt) *Scope {
+			return scoper(join(more, opts)...)
+		}
+	}
+}
+
+// Observe yields a ScoperDecorator that passes each Scope created by the
+// wrapped Scoper to the supplied func, e.g. to log or register it, before
+// returning it.
+func Observe(fn func(*Scope)) ScoperDecorator {
+	return func(scoper Scoper) Scoper {
+		return func(opts ...ScopeOpt) *Scope {
+			s := scoper(opts...)
+			fn(s)
+			return s
+		}
+	}
+}
+
+// join returns a new slice holding the options of a followed by those of b.
+func join(a, b []ScopeOpt) []ScopeOpt {
+	return append(append(make([]ScopeOpt, 0, len(a)+len(b)), a...), b...)
+}
@@ -0,0 +1,31 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestWithDefaults(t *testing.T) {
+	root := nls.NewScope()
+	defer root.Exit(context.TODO())
+	scoper := nls.WithDefaults(root.NewChildScope, nls.WithName("plugin"))
+	require(t, scoper().Name() == "plugin", "expected default name")
+	require(t, scoper(nls.WithName("mine")).Name() == "mine",
+		"expected caller's name to take precedence")
+}
+
+func TestChain(t *testing.T) {
+	root := nls.NewScope()
+	defer root.Exit(context.TODO())
+	var created []string
+	scoper := nls.Chain(root.NewChildScope,
+		nls.Enforce(nls.WithName("sandboxed")),
+		nls.Observe(func(s *nls.Scope) { created = append(created, s.Path()) }))
+
+	s := scoper(nls.WithName("escape"))
+	require(t, s.Name() == "sandboxed", "expected enforced name, got %q", s.Name())
+	require(t, len(created) == 1 && created[0] == "(unnamed)/sandboxed",
+		"expected observer to see the new scope, got %v", created)
+}
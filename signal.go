@@ -0,0 +1,81 @@
+package nls
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const defaultExitBudget = 30 * time.Second
+
+// shutdownSignals are bound when no signals are supplied. Relaying every
+// signal instead would include those the runtime and terminal send in the
+// ordinary course of things, e.g. SIGURG, SIGCHLD and SIGWINCH.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// BindSignals arranges for the supplied Scope to be exited when the process
+// receives one of the supplied signals, or os.Interrupt or syscall.SIGTERM if
+// none are supplied. The first signal begins a graceful Exit bounded by the
+// Scope's exit timeout (see WithExitTimeout) or 30 seconds if the Scope has
+// none. A second signal received while that Exit is in progress forces it by
+// canceling the Exit context, leaving any unfinished Reapers to their
+// AbandonPolicy. Errors encountered during the Exit are offered on the Scope's
+// error channel but are dropped if nothing is receiving. Signal delivery stops
+// once the Scope has exited, or earlier if the returned func is called; it is
+// safe to call more than once.
+func BindSignals(s *Scope, sigs ...os.Signal) (restore func()) {
+	return s.bindSignals(sigs, nil)
+}
+
+// bindSignals implements BindSignals, additionally calling force, if non-nil,
+// once a second signal has canceled the Exit context.
+func (s *Scope) bindSignals(sigs []os.Signal, force func()) (restore func()) {
+	if len(sigs) == 0 {
+		sigs = shutdownSignals
+	}
+	ch := make(chan os.Signal, 2)
+	signal.Notify(ch, sigs...)
+	stop := make(chan struct{})
+	var once sync.Once
+	restore = func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(stop)
+		})
+	}
+	go func() {
+		select {
+		case <-ch:
+		case <-s.Done():
+			restore()
+			return
+		case <-stop:
+			return
+		}
+		defer restore()
+		budget := s.exitTimeout()
+		if budget <= 0 {
+			budget = defaultExitBudget
+		}
+		ctx, cancel := ClockTimeout(context.Background(), s.clock, budget)
+		defer cancel()
+		go func() {
+			select {
+			case <-ch:
+				cancel()
+				if force != nil {
+					force()
+				}
+			case <-stop:
+			case <-ctx.Done():
+			}
+		}()
+		if err := s.Exit(ctx, WithErrorHandler(s.OfferErr)); err != nil {
+			s.OfferErr(err)
+		}
+	}()
+	return restore
+}
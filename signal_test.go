@@ -0,0 +1,76 @@
+//go:build !windows
+
+package nls_test
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func raise(t *testing.T, sig os.Signal) {
+	t.Helper()
+	p, err := os.FindProcess(os.Getpid())
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, p.Signal(sig) == nil, "failed to raise %v", sig)
+}
+
+func TestBindSignalsForceOnSecondSignal(t *testing.T) {
+	s := nls.NewScope()
+	started := make(chan struct{})
+	reaped := make(chan error, 1)
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			reaped <- ctx.Err()
+			return ctx.Err()
+		}, nil
+	})
+	restore := nls.BindSignals(s, syscall.SIGUSR1)
+	defer restore()
+
+	raise(t, syscall.SIGUSR1)
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected first signal to begin Exit")
+	}
+
+	raise(t, syscall.SIGUSR1)
+	select {
+	case err := <-reaped:
+		require(t, err == context.Canceled,
+			"expected second signal to cancel Exit, got %q", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected second signal to force Exit")
+	}
+}
+
+func TestBindSignalsRestore(t *testing.T) {
+	s := nls.NewScope()
+	restore := nls.BindSignals(s, syscall.SIGUSR2)
+	restore()
+	restore()
+	err := s.Spawn(context.TODO(),
+		func(context.Context) (nls.Reaper, error) { return nilReaper, nil })
+	require(t, err == nil, "expected scope to remain active after restore")
+}
+
+func TestBindSignalsDefault(t *testing.T) {
+	s := nls.NewScope()
+	defer s.Exit(context.TODO())
+	restore := nls.BindSignals(s)
+	defer restore()
+
+	raise(t, syscall.SIGWINCH)
+	select {
+	case <-s.Done():
+		t.Fatal("expected an unrelated signal not to exit the scope")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,208 @@
+package nls
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ExitRecord captures the outcome of a single Scope.Exit.
+type ExitRecord struct {
+	Scope     string        `json:"scope"`
+	Kind      Kind          `json:"kind,omitempty"`
+	Release   string        `json:"release,omitempty"`
+	Start     time.Time     `json:"start"`
+	Duration  time.Duration `json:"duration"`
+	Reapers   int           `json:"reapers"`
+	Errors    int           `json:"errors"`
+	Abandoned int           `json:"abandoned"`
+	TimedOut  bool          `json:"timed_out"`
+	Reason    string        `json:"reason,omitempty"`
+}
+
+// RecordStore persists ExitRecords on behalf of an ExitTracker. Implementations
+// must be safe for concurrent use.
+type RecordStore interface {
+	Append(ExitRecord) error
+	Records() ([]ExitRecord, error)
+}
+
+// MemoryStore is a RecordStore that retains a bounded number of the most
+// recent ExitRecords in memory.
+type MemoryStore struct {
+	mu      sync.Mutex
+	limit   int
+	records []ExitRecord
+}
+
+// NewMemoryStore creates a MemoryStore retaining at most limit records.
+func NewMemoryStore(limit int) *MemoryStore {
+	return &MemoryStore{limit: limit}
+}
+
+// Append implements RecordStore.
+func (m *MemoryStore) Append(r ExitRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, r)
+	if over := len(m.records) - m.limit; over > 0 {
+		m.records = append(m.records[:0], m.records[over:]...)
+	}
+	return nil
+}
+
+// Records implements RecordStore.
+func (m *MemoryStore) Records() ([]ExitRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ExitRecord(nil), m.records...), nil
+}
+
+// ExitSummary aggregates a set of ExitRecords.
+type ExitSummary struct {
+	Release   string        `json:"release,omitempty"`
+	Count     int           `json:"count"`
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	P99       time.Duration `json:"p99"`
+	ErrorRate float64       `json:"error_rate"`
+	TimedOut  int           `json:"timed_out"`
+}
+
+// ExitTracker records the duration and reap error rate of every Exit to which
+// it is supplied (see WithExitTracker) so that the health of shutdowns can be
+// monitored over time and across releases.
+type ExitTracker struct {
+	store   RecordStore
+	release string
+	onError func(err error)
+}
+
+// TrackerOpt is a type for optional parameters to NewExitTracker.
+type TrackerOpt func(*ExitTracker)
+
+// WithRelease yields a TrackerOpt that tags every ExitRecord with the supplied
+// release identifier (e.g. a version string) so that ExitTracker.Trend can
+// compare shutdown health release over release.
+func WithRelease(release string) TrackerOpt {
+	return func(t *ExitTracker) {
+		t.release = release
+	}
+}
+
+// WithStoreErrorHandler yields a TrackerOpt that supplies a func to be
+// notified of errors returned by the RecordStore.
+func WithStoreErrorHandler(eh func(err error)) TrackerOpt {
+	return func(t *ExitTracker) {
+		t.onError = eh
+	}
+}
+
+// NewExitTracker creates an ExitTracker backed by the supplied RecordStore.
+func NewExitTracker(store RecordStore, opts ...TrackerOpt) *ExitTracker {
+	t := &ExitTracker{store: store, onError: func(err error) {}}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// WithExitTracker yields an ExitOpt that records the outcome of the Exit with
+// the supplied ExitTracker.
+func WithExitTracker(t *ExitTracker) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.tracker = t
+	}
+}
+
+func (t *ExitTracker) record(s *Scope, start time.Time, ec *exitCfg, err error) {
+	rec := ExitRecord{
+		Scope:     s.name,
+		Kind:      s.kind,
+		Release:   t.release,
+		Start:     start,
+		Duration:  s.clock.Now().Sub(start),
+		Reapers:   ec.invoked,
+		Errors:    ec.failed,
+		Abandoned: ec.abandoned,
+		TimedOut:  err != nil,
+	}
+	if ec.reason != nil {
+		rec.Reason = ec.reason.Error()
+	}
+	if err := t.store.Append(rec); err != nil {
+		t.onError(err)
+	}
+}
+
+// Summary aggregates every ExitRecord currently held by the tracker's store.
+func (t *ExitTracker) Summary() (ExitSummary, error) {
+	recs, err := t.store.Records()
+	if err != nil {
+		return ExitSummary{}, err
+	}
+	return summarize(recs), nil
+}
+
+// Trend aggregates the ExitRecords currently held by the tracker's store per
+// release, in the order in which each release was first recorded.
+func (t *ExitTracker) Trend() ([]ExitSummary, error) {
+	recs, err := t.store.Records()
+	if err != nil {
+		return nil, err
+	}
+	var order []string
+	byRelease := make(map[string][]ExitRecord)
+	for _, r := range recs {
+		if _, ok := byRelease[r.Release]; !ok {
+			order = append(order, r.Release)
+		}
+		byRelease[r.Release] = append(byRelease[r.Release], r)
+	}
+	trend := make([]ExitSummary, 0, len(order))
+	for _, release := range order {
+		sum := summarize(byRelease[release])
+		sum.Release = release
+		trend = append(trend, sum)
+	}
+	return trend, nil
+}
+
+func summarize(recs []ExitRecord) ExitSummary {
+	sum := ExitSummary{Count: len(recs)}
+	if len(recs) == 0 {
+		return sum
+	}
+	durations := make([]time.Duration, len(recs))
+	var reapers, errs int
+	for i, r := range recs {
+		durations[i] = r.Duration
+		reapers += r.Reapers + r.Abandoned
+		errs += r.Errors + r.Abandoned
+		if r.TimedOut {
+			sum.TimedOut++
+		}
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	sum.P50 = percentile(durations, 0.50)
+	sum.P90 = percentile(durations, 0.90)
+	sum.P99 = percentile(durations, 0.99)
+	if reapers > 0 {
+		sum.ErrorRate = float64(errs) / float64(reapers)
+	}
+	return sum
+}
+
+// percentile returns the nearest-rank percentile of the supplied sorted
+// durations: the smallest that is no less than a fraction p of them.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,72 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestExitTracker(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := nls.NewMemoryStore(10)
+	exit := func(release string, d time.Duration, fail bool) {
+		tracker := nls.NewExitTracker(store, nls.WithRelease(release))
+		s := nls.NewScope(nls.WithClock(clock))
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				clock.Advance(d)
+				if fail {
+					return errors.New("failed")
+				}
+				return nil
+			}, nil
+		})
+		s.Exit(context.TODO(), nls.WithExitTracker(tracker))
+	}
+	exit("v1", time.Second, false)
+	exit("v1", 3*time.Second, false)
+	exit("v2", 5*time.Second, true)
+	exit("v2", 7*time.Second, false)
+
+	tracker := nls.NewExitTracker(store)
+	sum, err := tracker.Summary()
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, sum.Count == 4, "unexpected count %d", sum.Count)
+	require(t, sum.P50 == 3*time.Second, "unexpected p50 %v", sum.P50)
+	require(t, sum.P99 == 7*time.Second, "unexpected p99 %v", sum.P99)
+	require(t, sum.ErrorRate == 0.25, "unexpected error rate %v", sum.ErrorRate)
+
+	trend, err := tracker.Trend()
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, len(trend) == 2 && trend[0].Release == "v1" &&
+		trend[1].Release == "v2", "unexpected trend %+v", trend)
+	require(t, trend[0].ErrorRate == 0 && trend[1].ErrorRate == 0.5,
+		"unexpected trend error rates %+v", trend)
+}
+
+func TestExitSummaryPercentiles(t *testing.T) {
+	store := nls.NewMemoryStore(10)
+	for i := 1; i <= 6; i++ {
+		store.Append(nls.ExitRecord{Duration: time.Duration(i) * time.Second})
+	}
+	sum, err := nls.NewExitTracker(store).Summary()
+	require(t, err == nil, "unexpected error: %q", err)
+	// nearest rank: ceil(0.5*6) = 3, ceil(0.9*6) = ceil(5.4) = 6
+	require(t, sum.P50 == 3*time.Second, "unexpected p50 %v", sum.P50)
+	require(t, sum.P90 == 6*time.Second, "unexpected p90 %v", sum.P90)
+	require(t, sum.P99 == 6*time.Second, "unexpected p99 %v", sum.P99)
+}
+
+func TestMemoryStoreBound(t *testing.T) {
+	store := nls.NewMemoryStore(2)
+	for i := 0; i < 5; i++ {
+		store.Append(nls.ExitRecord{Reapers: i})
+	}
+	recs, _ := store.Records()
+	require(t, len(recs) == 2 && recs[0].Reapers == 3 && recs[1].Reapers == 4,
+		"expected most recent records to be retained: %+v", recs)
+}
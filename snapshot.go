@@ -0,0 +1,227 @@
+package nls
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ScopeSnapshot is a point-in-time description of a Scope and its
+// descendants.
+type ScopeSnapshot struct {
+	Name    string
+	Path    string
+	Kind    Kind
+	State   string
+	Created time.Time
+
+	// Reapers is the number of Reapers currently registered with the Scope.
+	Reapers int
+
+	// Spawned describes each of those Reapers in spawn order.
+	Spawned []ReaperSnapshot
+
+	// Children holds snapshots of the Scope's children in creation order.
+	Children []ScopeSnapshot
+}
+
+// Snapshot returns a ScopeSnapshot of this Scope and its descendants. State
+// is one of "active", "exiting" or "done".
+func (s *Scope) Snapshot() ScopeSnapshot {
+	s.mu.Lock()
+	snap := ScopeSnapshot{
+		Name:    s.name,
+		Kind:    s.kind,
+		State:   string(s.state),
+		Created: s.created,
+		Reapers: len(s.reapers),
+	}
+	for _, r := range s.reapers {
+		snap.Spawned = append(snap.Spawned, r.snapshot())
+	}
+	children := s.childScopes()
+	s.mu.Unlock()
+	if snap.State == string(active) && s.exiting.Load() {
+		snap.State = "exiting"
+	}
+	snap.Path = s.Path()
+	for _, c := range children {
+		snap.Children = append(snap.Children, c.Snapshot())
+	}
+	return snap
+}
+
+// ReaperSnapshot describes a Reaper registered with a Scope by way of the
+// attributes assigned to it at spawn time.
+type ReaperSnapshot struct {
+	Class    Class    `json:"class"`
+	Labels   []string `json:"labels,omitempty"`
+	Resource Resource `json:"resource,omitempty"`
+}
+
+// MarshalJSON encodes the snapshot with a stable schema for consumption by
+// monitoring and support tooling:
+//
+//	{
+//	  "name": "db",
+//	  "path": "app/db",
+//	  "kind": "component",
+//	  "state": "active",
+//	  "created_at": "2024-01-02T15:04:05.999999999Z",
+//	  "reaper_count": 1,
+//	  "reapers": [{"class": "normal", "labels": ["conn"]}],
+//	  "children": []
+//	}
+//
+// The kind is omitted if the Scope has none; reapers and children are always
+// present, if empty.
+func (snap ScopeSnapshot) MarshalJSON() ([]byte, error) {
+	j := snapshotJSON{
+		Name:        snap.Name,
+		Path:        snap.Path,
+		Kind:        snap.Kind,
+		State:       snap.State,
+		CreatedAt:   snap.Created,
+		ReaperCount: snap.Reapers,
+		Reapers:     snap.Spawned,
+		Children:    snap.Children,
+	}
+	if j.Reapers == nil {
+		j.Reapers = []ReaperSnapshot{}
+	}
+	if j.Children == nil {
+		j.Children = []ScopeSnapshot{}
+	}
+	return json.Marshal(j)
+}
+
+type snapshotJSON struct {
+	Name        string           `json:"name"`
+	Path        string           `json:"path"`
+	Kind        Kind             `json:"kind,omitempty"`
+	State       string           `json:"state"`
+	CreatedAt   time.Time        `json:"created_at"`
+	ReaperCount int              `json:"reaper_count"`
+	Reapers     []ReaperSnapshot `json:"reapers"`
+	Children    []ScopeSnapshot  `json:"children"`
+}
+
+func (r reaper) snapshot() ReaperSnapshot {
+	return ReaperSnapshot{
+		Class:    r.class,
+		Labels:   append([]string(nil), r.labels...),
+		Resource: r.resource,
+	}
+}
+
+// TotalReapers returns the number of Reapers registered with the Scope
+// described by this snapshot and all of its descendants.
+func (snap ScopeSnapshot) TotalReapers() int {
+	n := snap.Reapers
+	for _, c := range snap.Children {
+		n += c.TotalReapers()
+	}
+	return n
+}
+
+// SnapshotDiff describes how a Scope tree changed between two
+// ScopeSnapshots; see DiffSnapshots. Scopes are identified by path.
+type SnapshotDiff struct {
+	// Added and Removed hold the paths of the roots of subtrees present in
+	// only the later or earlier snapshot respectively.
+	Added   []string
+	Removed []string
+
+	// Reapers holds, by path, the net change in the number of Reapers
+	// registered with each Scope whose count changed, including those in
+	// added and removed subtrees.
+	Reapers map[string]int
+}
+
+// DiffSnapshots compares two snapshots of the same Scope, typically taken
+// before and after an operation under test, e.g. to assert that a request
+// handler leaked no Reapers or that the tree returned to its prior shape.
+// Children sharing a path are paired in creation order.
+func DiffSnapshots(before, after ScopeSnapshot) SnapshotDiff {
+	d := SnapshotDiff{Reapers: make(map[string]int)}
+	d.diff(before, after)
+	return d
+}
+
+func (d *SnapshotDiff) diff(before, after ScopeSnapshot) {
+	if n := after.Reapers - before.Reapers; n != 0 {
+		d.Reapers[after.Path] += n
+	}
+	prior := make(map[string][]ScopeSnapshot)
+	for _, c := range before.Children {
+		prior[c.Path] = append(prior[c.Path], c)
+	}
+	for _, c := range after.Children {
+		if match := prior[c.Path]; len(match) > 0 {
+			d.diff(match[0], c)
+			prior[c.Path] = match[1:]
+			continue
+		}
+		d.Added = append(d.Added, c.Path)
+		d.count(c, 1)
+	}
+	for _, c := range before.Children {
+		if match := prior[c.Path]; len(match) > 0 {
+			prior[c.Path] = match[1:]
+			d.Removed = append(d.Removed, c.Path)
+			d.count(match[0], -1)
+		}
+	}
+}
+
+// count adds the Reapers of every Scope in the supplied subtree, multiplied
+// by sign, to d.Reapers.
+func (d *SnapshotDiff) count(snap ScopeSnapshot, sign int) {
+	if snap.Reapers != 0 {
+		d.Reapers[snap.Path] += sign * snap.Reapers
+	}
+	for _, c := range snap.Children {
+		d.count(c, sign)
+	}
+}
+
+// Empty reports whether the two snapshots describe the same tree shape with
+// the same number of Reapers registered with each Scope.
+func (d SnapshotDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Reapers) == 0
+}
+
+// LeakedReapers returns the net change in the total number of Reapers
+// registered with the tree: positive if Reapers were left behind.
+func (d SnapshotDiff) LeakedReapers() int {
+	n := 0
+	for _, delta := range d.Reapers {
+		n += delta
+	}
+	return n
+}
+
+// String describes the diff for use in test failure messages.
+func (d SnapshotDiff) String() string {
+	if d.Empty() {
+		return "no change"
+	}
+	var parts []string
+	for _, p := range d.Added {
+		parts = append(parts, "+scope "+p)
+	}
+	for _, p := range d.Removed {
+		parts = append(parts, "-scope "+p)
+	}
+	paths := make([]string, 0, len(d.Reapers))
+	for p := range d.Reapers {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		parts = append(parts, fmt.Sprintf("%+d reapers %s", d.Reapers[p], p))
+	}
+	return strings.Join(parts, "; ")
+}
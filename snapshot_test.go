@@ -0,0 +1,88 @@
+package nls_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestSnapshot(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := nlssim.New(start)
+	root := nls.NewScope(nls.WithName("root"), nls.WithClock(clock))
+	clock.Advance(time.Minute)
+	child := root.NewChildScope(nls.WithName("child"), nls.WithKind(nls.KindSession))
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	})
+
+	snap := root.Snapshot()
+	require(t, snap.Name == "root" && snap.State == "active", "unexpected snapshot %+v", snap)
+	require(t, snap.Created.Equal(start), "unexpected creation time %v", snap.Created)
+	require(t, len(snap.Children) == 1, "expected one child")
+	c := snap.Children[0]
+	require(t, c.Path == "root/child" && c.Kind == nls.KindSession && c.Reapers == 1,
+		"unexpected child snapshot %+v", c)
+	require(t, c.Created.Equal(start.Add(time.Minute)), "unexpected creation time %v", c.Created)
+
+	root.Exit(context.TODO())
+	snap = root.Snapshot()
+	require(t, snap.State == "done" && len(snap.Children) == 0, "unexpected snapshot %+v", snap)
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	root := nls.NewScope(nls.WithName("root"))
+	defer root.Exit(context.TODO())
+	pool := root.NewChildScope(nls.WithName("pool"))
+	nls.MustSpawn(context.TODO(), pool, nopSpawner, nls.WithLabel("conn"))
+	before := root.Snapshot()
+	require(t, before.TotalReapers() == 1, "expected one reaper in total")
+	require(t, len(before.Children[0].Spawned) == 1 &&
+		before.Children[0].Spawned[0].Labels[0] == "conn",
+		"expected reaper metadata in snapshot, got %+v", before.Children[0].Spawned)
+
+	// a well-behaved handler
+	req := root.NewChildScope(nls.WithName("request"))
+	nls.MustSpawn(context.TODO(), req, nopSpawner)
+	req.Exit(context.TODO())
+	d := nls.DiffSnapshots(before, root.Snapshot())
+	require(t, d.Empty(), "expected no change, got %v", d)
+
+	// a leaky one
+	leak := root.NewChildScope(nls.WithName("request"))
+	nls.MustSpawn(context.TODO(), leak, nopSpawner)
+	nls.MustSpawn(context.TODO(), pool, nopSpawner)
+	d = nls.DiffSnapshots(before, root.Snapshot())
+	require(t, d.LeakedReapers() == 2, "expected two leaked reapers, got %v", d)
+	require(t, len(d.Added) == 1 && d.Added[0] == "root/request", "unexpected diff %v", d)
+	require(t, d.String() == "+scope root/request; +1 reapers root/pool; +1 reapers root/request",
+		"unexpected description %q", d.String())
+
+	leak.Exit(context.TODO())
+	pool.Exit(context.TODO())
+	d = nls.DiffSnapshots(before, root.Snapshot())
+	require(t, len(d.Removed) == 1 && d.LeakedReapers() == -1, "unexpected diff %v", d)
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	root := nls.NewScope(nls.WithName("root"), nls.WithClock(nlssim.New(start)))
+	defer root.Exit(context.TODO())
+	child := root.NewChildScope(nls.WithName("child"), nls.WithKind(nls.KindSession))
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return nil }, nil
+	}, nls.WithClass(nls.ClassCritical), nls.WithLabel("conn"))
+
+	got, err := json.Marshal(root.Snapshot())
+	require(t, err == nil, "unexpected error: %v", err)
+	want := `{"name":"root","path":"root","state":"active",` +
+		`"created_at":"2020-01-01T00:00:00Z","reaper_count":0,"reapers":[],"children":[` +
+		`{"name":"child","path":"root/child","kind":"session","state":"active",` +
+		`"created_at":"2020-01-01T00:00:00Z","reaper_count":1,` +
+		`"reapers":[{"class":"critical","labels":["conn"]}],"children":[]}]}`
+	require(t, string(got) == want, "unexpected JSON\n got: %s\nwant: %s", got, want)
+}
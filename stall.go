@@ -0,0 +1,77 @@
+package nls
+
+import (
+	"runtime"
+	"time"
+)
+
+// StallInfo describes a Reaper, or the exit of a child Scope, that has been
+// running for longer than the duration supplied to WithStallWarning.
+type StallInfo struct {
+	// Path is the path of the Scope that owns the stalled Reaper or, for
+	// a child Scope, of the child itself.
+	Path string
+
+	// Subtree is true if the exit of the child Scope at Path, including
+	// its descendants, has stalled rather than a single Reaper.
+	Subtree bool
+
+	// Index is the position of the stalled Reaper, starting at 1, among
+	// those of its Scope in spawn order (adjusted for WithDependsOn) as
+	// reported by Scope.Plan. Class and Labels are those assigned to it.
+	Index  int
+	Class  Class
+	Labels []string
+
+	// Running is how long the Reaper or child had been running.
+	Running time.Duration
+
+	// Stacks holds the stack traces of every goroutine in the process at
+	// the time of the warning, as per runtime.Stack.
+	Stacks string
+}
+
+// WithStallWarning yields an ExitOpt that passes a StallInfo to the supplied
+// func for each Reaper, and each child Scope, whose exit has been running for
+// longer than d, as measured by the Scope's Clock, to help diagnose hung
+// shutdowns. Each is reported at most once. Since a stalled Reaper also
+// stalls the exit of each of its ancestors below the exiting Scope, they are
+// reported too. The func is called on its own goroutine.
+func WithStallWarning(d time.Duration, fn func(StallInfo)) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.stall, cfg.onStall = d, fn
+	}
+}
+
+// watch reports the supplied StallInfo if the returned func, which stops the
+// watch, has not been called within ec.stall.
+func (ec *exitCfg) watch(s *Scope, info StallInfo) (stop func()) {
+	start := s.clock.Now()
+	t := s.clock.NewTimer(ec.stall)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-t.C():
+			info.Running = s.clock.Now().Sub(start)
+			info.Stacks = allStacks()
+			ec.onStall(info)
+		case <-done:
+		}
+	}()
+	return func() {
+		t.Stop()
+		close(done)
+	}
+}
+
+// allStacks returns the stack traces of every goroutine.
+func allStacks() string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,45 @@
+package nls_test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestStallWarning(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	root := nls.NewScope(nls.WithName("app"), nls.WithClock(clock))
+	child := root.NewChildScope(nls.WithName("db"))
+	nls.MustSpawn(context.TODO(), child, nopSpawner)
+	release := make(chan struct{})
+	nls.MustSpawn(context.TODO(), child, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			<-release
+			return nil
+		}, nil
+	}, nls.WithLabel("pool"))
+
+	stalls := make(chan nls.StallInfo, 2)
+	done := root.ExitAsync(context.TODO(), nls.WithStallWarning(time.Second, func(info nls.StallInfo) {
+		stalls <- info
+	}))
+	// one watch for the child subtree and one for its stalled reaper
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	got := []nls.StallInfo{<-stalls, <-stalls}
+	sort.Slice(got, func(i, j int) bool { return got[i].Subtree })
+	require(t, got[0].Subtree && got[0].Path == "app/db", "unexpected subtree stall %+v", got[0])
+	r := got[1]
+	require(t, !r.Subtree && r.Path == "app/db" && r.Index == 2 &&
+		len(r.Labels) == 1 && r.Labels[0] == "pool", "unexpected reaper stall %+v", r)
+	require(t, r.Running == time.Second, "unexpected running time %v", r.Running)
+	require(t, strings.Contains(r.Stacks, "TestStallWarning"), "expected goroutine stacks")
+
+	close(release)
+	require(t, <-done == nil, "unexpected exit error")
+}
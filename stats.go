@@ -0,0 +1,40 @@
+package nls
+
+import "sync/atomic"
+
+// Stats holds aggregate counters for the Scopes to which it is supplied via
+// WithStats. All methods are safe for concurrent use. See the nlsexpvar
+// package to publish them.
+type Stats struct {
+	scopesCreated    atomic.Int64
+	scopesExited     atomic.Int64
+	reapersPending   atomic.Int64
+	reapErrors       atomic.Int64
+	reapersAbandoned atomic.Int64
+}
+
+// WithStats yields a ScopeOpt that causes the new Scope and, unless they are
+// given their own, its descendants to count their activity in the supplied
+// Stats.
+func WithStats(st *Stats) ScopeOpt {
+	return func(s *Scope) {
+		s.stats = st
+	}
+}
+
+// Values returns the current counters by name:
+//
+//   - scopesCreated and scopesExited: the number of Scopes created and
+//     exited.
+//   - reapersPending: the number of Reapers registered with live Scopes.
+//   - reapErrors and reapersAbandoned: the number of Reapers that returned an
+//     error, and that were cut off by the Exit context, during Exits.
+func (st *Stats) Values() map[string]int64 {
+	return map[string]int64{
+		"scopesCreated":    st.scopesCreated.Load(),
+		"scopesExited":     st.scopesExited.Load(),
+		"reapersPending":   st.reapersPending.Load(),
+		"reapErrors":       st.reapErrors.Load(),
+		"reapersAbandoned": st.reapersAbandoned.Load(),
+	}
+}
@@ -0,0 +1,52 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+// TempDir creates a new temporary directory (see os.MkdirTemp) in the default
+// temporary directory and registers a Reaper with the supplied Scope that
+// removes the directory and all of its contents on Exit.
+func TempDir(s Lifetime, pattern string) (string, error) {
+	var dir string
+	err := s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		var err error
+		if dir, err = os.MkdirTemp("", pattern); err != nil {
+			return nil, err
+		}
+		return func(context.Context) error { return os.RemoveAll(dir) }, nil
+	})
+	return dir, err
+}
+
+// TempFile creates a new temporary file (see os.CreateTemp) in the supplied
+// directory and registers a Reaper with the supplied Scope that closes and
+// removes the file on Exit. The caller may close the file earlier; it will
+// still be removed. The file counts as a ResourceFile (see WithResourceLimit)
+// until the Scope exits.
+func TempFile(s Lifetime, dir, pattern string) (*os.File, error) {
+	var f *os.File
+	err := s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		var err error
+		if f, err = os.CreateTemp(dir, pattern); err != nil {
+			return nil, err
+		}
+		return func(context.Context) error {
+			cerr := f.Close()
+			if errors.Is(cerr, os.ErrClosed) {
+				cerr = nil
+			}
+			rerr := os.Remove(f.Name())
+			if errors.Is(rerr, os.ErrNotExist) {
+				rerr = nil
+			}
+			return errors.Join(cerr, rerr)
+		}, nil
+	}, WithResource(ResourceFile))
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
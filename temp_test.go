@@ -0,0 +1,37 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestTempDirAndFile(t *testing.T) {
+	s := nls.NewScope()
+	dir, err := nls.TempDir(s, "nls-test-*")
+	require(t, err == nil, "unexpected error: %q", err)
+	err = os.WriteFile(filepath.Join(dir, "scratch"), []byte("x"), 0o600)
+	require(t, err == nil, "unexpected error: %q", err)
+
+	f, err := nls.TempFile(s, "", "nls-test-*")
+	require(t, err == nil, "unexpected error: %q", err)
+	closed, err := nls.TempFile(s, dir, "nls-closed-*")
+	require(t, err == nil, "unexpected error: %q", err)
+	require(t, closed.Close() == nil, "unexpected close error")
+
+	err = s.Exit(context.TODO(), nls.WithErrorHandler(func(err error) {
+		t.Errorf("unexpected reap error: %q", err)
+	}))
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	_, err = os.Stat(dir)
+	require(t, errors.Is(err, os.ErrNotExist), "expected temp dir removal")
+	_, err = os.Stat(f.Name())
+	require(t, errors.Is(err, os.ErrNotExist), "expected temp file removal")
+
+	_, err = nls.TempDir(s, "nls-test-*")
+	require(t, err != nil, "expected error on exited scope")
+}
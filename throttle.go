@@ -0,0 +1,301 @@
+package nls
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter throttles Reaper invocation during Scope.Exit. Wait blocks until the
+// next Reaper may be invoked or until the supplied context is done, in which
+// case it returns an error. *rate.Limiter from golang.org/x/time/rate
+// satisfies this interface.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// WithReapLimiter yields an ExitOpt that consults the supplied Limiter before
+// invoking each Reaper. The Limiter is shared by every Scope in the exiting
+// subtree. A Reaper whose turn does not come before the Exit context is done
+// is abandoned (see AbandonPolicy).
+func WithReapLimiter(l Limiter) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.limiter = l
+	}
+}
+
+// WithReapRate yields an ExitOpt that limits Reaper invocation to n per the
+// supplied period, as measured by the exiting Scope's Clock. It is a
+// convenience for WithReapLimiter and overrides any previously supplied
+// Limiter.
+func WithReapRate(n int, per time.Duration) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.limiter = nil
+		if n > 0 {
+			cfg.interval = per / time.Duration(n)
+		}
+	}
+}
+
+// WithParallelReaping yields an ExitOpt that allows up to n of a Scope's
+// Reapers to run concurrently. Reapers are still started in the reverse of the
+// order in which they were spawned, and a Scope's children still finish
+// exiting before any of its own Reapers are started, but a Reaper may no
+// longer assume that those spawned after it have completed. Combine with
+// WithReapRate or WithReapLimiter to bound both concurrency and rate.
+func WithParallelReaping(n int) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.parallel = n
+	}
+}
+
+// WithExitConcurrencyAcrossSubtrees yields an ExitOpt that allows up to n of
+// the exiting Scope's child subtrees to exit concurrently, so that a Scope
+// holding many independent subsystems (e.g. tenants) shuts down in roughly
+// the time of the slowest rather than the sum of all. Each subtree still
+// exits in order internally and the exiting Scope's own Reapers are still
+// invoked only once every subtree has finished. Only the children of the
+// Scope on which Exit is called are affected; see WithParallelReaping to run
+// a Scope's own Reapers concurrently.
+func WithExitConcurrencyAcrossSubtrees(n int) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.subtrees = n
+	}
+}
+
+// exitAll exits the supplied child Scopes in reverse order, starting up to
+// ec.subtrees of them at once if this is the first call for the Exit, and
+// waits for them to finish.
+func (ec *exitCfg) exitAll(ctx context.Context, b *budget, children []*Scope) {
+	n := ec.subtrees
+	if n != 0 {
+		// applies to the exiting Scope's children only; cleared before
+		// any child starts so that no further writes race with reads
+		ec.subtrees = 0
+	}
+	if n <= 1 || len(children) <= 1 {
+		for i := len(children) - 1; i >= 0; i-- {
+			cctx, cancel := b.take(ctx, b.child(i))
+			ec.exitChild(cctx, children[i])
+			cancel()
+		}
+		return
+	}
+	w := 0
+	for i := range children {
+		w += b.child(i)
+	}
+	cctx, cancel := b.take(ctx, w)
+	defer cancel()
+	ec.exitConcurrent(cctx, n, children)
+}
+
+// exitConcurrent is exitAll for a concurrency greater than one. It is kept
+// apart so that sequential exits need not allocate.
+func (ec *exitCfg) exitConcurrent(ctx context.Context, n int, children []*Scope) {
+	if n > len(children) {
+		n = len(children)
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, n)
+	for i := len(children) - 1; i >= 0; i-- {
+		c := children[i]
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			ec.exitChild(ctx, c)
+		}()
+	}
+	wg.Wait()
+}
+
+// exitChild exits the supplied child Scope, reporting any error other than
+// that of the supplied context.
+func (ec *exitCfg) exitChild(ctx context.Context, c *Scope) {
+	if ec.stall > 0 {
+		stop := ec.watch(c, StallInfo{Path: c.Path(), Subtree: true})
+		defer stop()
+	}
+	err := c.exit(ctx, ec)
+	if err != nil && err != ctx.Err() {
+		ec.mu.Lock()
+		ec.notify(err)
+		ec.mu.Unlock()
+	}
+}
+
+// reapAll invokes the supplied Reapers of Scope s in reverse order, subject
+// to the configured rate limit, concurrency and budget, and waits for them to
+// return.
+func (ec *exitCfg) reapAll(ctx context.Context, s *Scope, b *budget, reapers []reaper) {
+	if ec.parallel > 1 {
+		w := 0
+		if b != nil {
+			for _, r := range reapers {
+				w += r.cost()
+			}
+		}
+		pctx, cancel := b.take(ctx, w)
+		defer cancel()
+		ec.reapParallel(pctx, s, reapers)
+		return
+	}
+	var async *sync.WaitGroup
+	for i := len(reapers) - 1; i >= 0; i-- {
+		r := reapers[i]
+		if r.async {
+			if async == nil {
+				async = &sync.WaitGroup{}
+			}
+			rctx, cancel := b.take(ctx, r.cost())
+			ec.reapAsync(rctx, s, i, r, async, cancel)
+			continue
+		}
+		rctx, cancel := b.take(ctx, r.cost())
+		rctx, rescued := ec.rescue(rctx, r)
+		if ec.admit(rctx, s, r) {
+			ec.reap(rctx, s, i, r)
+		}
+		rescued()
+		cancel()
+	}
+	ec.awaitAsync(async)
+}
+
+// reapParallel is reapAll for a concurrency greater than one. It is kept
+// apart so that sequential exits need not allocate.
+func (ec *exitCfg) reapParallel(ctx context.Context, s *Scope, reapers []reaper) {
+	n := ec.parallel
+	if n > len(reapers) {
+		n = len(reapers)
+	}
+	var wg sync.WaitGroup
+	var async *sync.WaitGroup
+	sem := make(chan struct{}, n)
+	for i := len(reapers) - 1; i >= 0; i-- {
+		i, r := i, reapers[i]
+		if r.async {
+			if async == nil {
+				async = &sync.WaitGroup{}
+			}
+			ec.reapAsync(ctx, s, i, r, async, func() {})
+			continue
+		}
+		rctx, rescued := ec.rescue(ctx, r)
+		if !ec.admit(rctx, s, r) {
+			rescued()
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-rctx.Done():
+			rescued()
+			s.exitFailed(ec, ec.skip(r, rctx.Err()))
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer rescued()
+			ec.reap(rctx, s, i, r)
+		}()
+	}
+	wg.Wait()
+	ec.awaitAsync(async)
+}
+
+// admit reports whether the supplied reaper of Scope s may be invoked,
+// waiting on the Limiter if one is configured, and abandons it otherwise.
+func (ec *exitCfg) admit(ctx context.Context, s *Scope, r reaper) bool {
+	if ctxerr := ctx.Err(); ctxerr != nil {
+		s.exitFailed(ec, ec.skip(r, ctxerr))
+		return false
+	}
+	if ec.limiter != nil {
+		if err := ec.limiter.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				s.exitFailed(ec, ec.skip(r, err))
+			} else {
+				s.exitFailed(ec, ec.abandon(r, err))
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// reap invokes the supplied reaper, the i'th of those of Scope s.
+func (ec *exitCfg) reap(ctx context.Context, s *Scope, i int, r reaper) {
+	if ec.stall > 0 {
+		info := StallInfo{Path: s.Path(), Index: i + 1, Class: r.class, Labels: r.labels}
+		stop := ec.watch(s, info)
+		defer stop()
+	}
+	if len(s.observers) > 0 {
+		s.observeReap(ctx, ec, r)
+		return
+	}
+	s.exitFailed(ec, ec.finish(ctx, r, ec.invoke(ctx, s, r)))
+}
+
+// finish records the outcome of invoking the supplied reaper, returning the
+// error reported for it, if any.
+func (ec *exitCfg) finish(ctx context.Context, r reaper, err error) error {
+	ec.mu.Lock()
+	ec.invoked++
+	if err != nil && ec.ignored(err) {
+		ec.mu.Unlock()
+		return nil
+	}
+	if err != nil && timedOut(err) {
+		ec.abandoned++
+		ec.leaked++
+		err = abandonedError{r.class, err}
+		ec.notify(err)
+		ec.mu.Unlock()
+		return err
+	}
+	ec.mu.Unlock()
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ec.abandon(r, err)
+	default:
+		return ec.fail(r, err)
+	}
+}
+
+// clockLimiter is a Limiter that admits one caller per interval as measured
+// by a Clock.
+type clockLimiter struct {
+	mu       sync.Mutex
+	clock    Clock
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *clockLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := l.clock.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+	if !slot.After(now) {
+		return ctx.Err()
+	}
+	t := l.clock.NewTimer(slot.Sub(now))
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
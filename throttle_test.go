@@ -0,0 +1,132 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestParallelReaping(t *testing.T) {
+	s := nls.NewScope()
+	var mu sync.Mutex
+	running, peak := 0, 0
+	for i := 0; i < 8; i++ {
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				mu.Lock()
+				running++
+				if running > peak {
+					peak = running
+				}
+				mu.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return nil
+			}, nil
+		})
+	}
+	err := s.Exit(context.TODO(), nls.WithParallelReaping(3))
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, peak > 1 && peak <= 3, "expected between 2 and 3 concurrent reapers, got %v", peak)
+}
+
+func TestReapRate(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := nls.NewScope(nls.WithClock(clock))
+	reaped := make(chan time.Time, 3)
+	for i := 0; i < 3; i++ {
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				reaped <- clock.Now()
+				return nil
+			}, nil
+		})
+	}
+	done := s.ExitAsync(context.TODO(), nls.WithReapRate(2, time.Second))
+	start := <-reaped
+	for i := 1; i < 3; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(500 * time.Millisecond)
+		at := <-reaped
+		require(t, at.Sub(start) == time.Duration(i)*500*time.Millisecond,
+			"unexpected reap time %v", at.Sub(start))
+	}
+	err := <-done
+	require(t, err == nil, "unexpected error: %v", err)
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Wait(context.Context) error { return errors.New("limited") }
+
+func TestReapLimiterAbandons(t *testing.T) {
+	s := nls.NewScope()
+	invoked := false
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			invoked = true
+			return nil
+		}, nil
+	})
+	var errs []error
+	err := s.Exit(context.TODO(),
+		nls.WithReapLimiter(failingLimiter{}),
+		nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, !invoked, "expected reaper not to be invoked")
+	require(t, len(errs) == 1 && errors.Is(errs[0], nls.ErrAbandoned),
+		"expected abandoned error, got %v", errs)
+}
+
+func TestExitConcurrencyAcrossSubtrees(t *testing.T) {
+	s := nls.NewScope()
+	var mu sync.Mutex
+	var order []string
+	record := func(ev string) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error {
+				time.Sleep(20 * time.Millisecond)
+				mu.Lock()
+				order = append(order, ev)
+				mu.Unlock()
+				return nil
+			}, nil
+		}
+	}
+	for i := 0; i < 10; i++ {
+		tenant := s.NewChildScope()
+		nls.MustSpawn(context.TODO(), tenant, record("db"))
+		nls.MustSpawn(context.TODO(), tenant.NewChildScope(), record("conn"))
+	}
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			mu.Lock()
+			order = append(order, "root")
+			mu.Unlock()
+			return nil
+		}, nil
+	})
+
+	start := time.Now()
+	err := s.Exit(context.TODO(), nls.WithExitConcurrencyAcrossSubtrees(10))
+	elapsed := time.Since(start)
+	require(t, err == nil, "unexpected error %v", err)
+	require(t, elapsed < 200*time.Millisecond,
+		"expected tenants to exit concurrently, took %v", elapsed)
+	require(t, len(order) == 21 && order[20] == "root",
+		"expected root reaper last, got %v", order)
+	conns := 0
+	for _, ev := range order[:20] {
+		if ev == "conn" {
+			conns++
+		}
+		require(t, ev != "db" || conns > 0, "db reaped before any conn: %v", order)
+	}
+}
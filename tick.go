@@ -0,0 +1,42 @@
+package nls
+
+import (
+	"context"
+	"time"
+)
+
+// Tick launches a goroutine managed by the supplied Lifetime (see Scope.Go)
+// that invokes fn every period, as measured by its Clock, until it exits. The
+// context passed to fn is canceled when the Lifetime exits and Exit waits for
+// any in-progress invocation to return.
+func Tick(s Lifetime, d time.Duration, fn func(context.Context, time.Time)) error {
+	clock := ClockOf(s)
+	return goIn(s, func(ctx context.Context) {
+		t := clock.NewTicker(d)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-t.C():
+				fn(ctx, now)
+			}
+		}
+	})
+}
+
+// After launches a goroutine managed by the supplied Lifetime (see Scope.Go)
+// that invokes fn once after the supplied duration, as measured by its Clock,
+// unless it exits first.
+func After(s Lifetime, d time.Duration, fn func(context.Context)) error {
+	clock := ClockOf(s)
+	return goIn(s, func(ctx context.Context) {
+		t := clock.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+		case <-t.C():
+			fn(ctx)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestTick(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := nls.NewScope(nls.WithClock(clock))
+	ticks := make(chan time.Time)
+	err := nls.Tick(s, time.Second, func(_ context.Context, now time.Time) {
+		ticks <- now
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+
+	clock.BlockUntil(1)
+	for i := 0; i < 3; i++ {
+		go clock.Advance(time.Second)
+		<-ticks
+	}
+	err = s.Exit(context.TODO())
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	require(t, clock.Pending() == 0, "expected ticker to be stopped")
+}
+
+func TestAfter(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := nls.NewScope(nls.WithClock(clock))
+	fired := make(chan struct{})
+	err := nls.After(s, time.Minute, func(context.Context) { close(fired) })
+	require(t, err == nil, "unexpected error: %q", err)
+	skipped := false
+	err = nls.After(s, time.Hour, func(context.Context) { skipped = true })
+	require(t, err == nil, "unexpected error: %q", err)
+
+	clock.BlockUntil(2)
+	clock.Advance(time.Minute)
+	<-fired
+	err = s.Exit(context.TODO())
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	require(t, !skipped, "expected pending callback to be canceled by Exit")
+}
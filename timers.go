@@ -0,0 +1,156 @@
+package nls
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// TimerKind identifies the feature that scheduled a PendingTimer.
+type TimerKind string
+
+const (
+	// TimerExitAfter is the TimerKind of timers scheduled by
+	// Scope.ExitAfter.
+	TimerExitAfter TimerKind = "exit-after"
+
+	// TimerTTL is the TimerKind of timers scheduled by WithTTL and
+	// WithDeadline.
+	TimerTTL TimerKind = "ttl"
+
+	// TimerIdle is the TimerKind of timers scheduled by WithIdleTTL.
+	TimerIdle TimerKind = "idle"
+)
+
+// PendingTimer describes an automatic action (e.g. an Exit) that has been
+// scheduled against a Scope but has not yet fired.
+type PendingTimer struct {
+	ID       uint64
+	Kind     TimerKind
+	Deadline time.Time
+
+	scope *Scope
+}
+
+// Cancel prevents the timer from firing, returning false if it had already
+// fired or been canceled.
+func (t PendingTimer) Cancel() bool {
+	if t.scope == nil {
+		return false
+	}
+	return t.scope.CancelTimer(t.ID)
+}
+
+type scopeTimer struct {
+	PendingTimer
+	stop chan struct{}
+}
+
+// ExitAfter schedules this Scope to be exited automatically once the supplied
+// duration, as measured by the Scope's Clock, has elapsed. The supplied
+// ExitOpts are passed to that Exit. Unless the ExitOpts include an error
+// handler, errors from the automatic Exit are reported via Scope.OfferErr.
+// The returned PendingTimer can be used to cancel the automatic Exit, which
+// is also canceled if the Scope is exited by other means.
+func (s *Scope) ExitAfter(d time.Duration, opts ...ExitOpt) (PendingTimer, error) {
+	return s.schedule(TimerExitAfter, d, func() { s.autoExit(opts) })
+}
+
+// PendingTimers returns the timers currently scheduled against this Scope,
+// ordered by deadline.
+func (s *Scope) PendingTimers() []PendingTimer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	timers := make([]PendingTimer, 0, len(s.timers))
+	for _, t := range s.timers {
+		timers = append(timers, t.PendingTimer)
+	}
+	sort.Slice(timers, func(i, j int) bool {
+		return timers[i].Deadline.Before(timers[j].Deadline)
+	})
+	return timers
+}
+
+// CancelTimer cancels the pending timer with the supplied ID, returning false
+// if no such timer is pending.
+func (s *Scope) CancelTimer(id uint64) bool {
+	s.mu.Lock()
+	t, ok := s.timers[id]
+	delete(s.timers, id)
+	s.mu.Unlock()
+	if ok {
+		close(t.stop)
+	}
+	return ok
+}
+
+// schedule arranges for action to be invoked on a new goroutine after the
+// supplied duration unless the timer is canceled or the Scope exits first.
+func (s *Scope) schedule(kind TimerKind, d time.Duration, action func()) (PendingTimer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scheduleLocked(kind, d, action)
+}
+
+// scheduleLocked is schedule for callers that hold s.mu.
+func (s *Scope) scheduleLocked(kind TimerKind, d time.Duration, action func()) (PendingTimer, error) {
+	if s.state != active {
+		return PendingTimer{}, stateError{"schedule timer in", s.state}
+	}
+	s.timerSeq++
+	t := &scopeTimer{
+		PendingTimer: PendingTimer{
+			ID:       s.timerSeq,
+			Kind:     kind,
+			Deadline: s.clock.Now().Add(d),
+			scope:    s,
+		},
+		stop: make(chan struct{}),
+	}
+	if s.timers == nil {
+		s.timers = make(map[uint64]*scopeTimer)
+	}
+	s.timers[t.ID] = t
+	s.timersLive++
+	clockTimer := s.clock.NewTimer(d)
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			s.timersLive--
+			s.mu.Unlock()
+		}()
+		defer clockTimer.Stop()
+		select {
+		case <-t.stop:
+			return
+		case <-clockTimer.C():
+		}
+		s.mu.Lock()
+		_, pending := s.timers[t.ID]
+		delete(s.timers, t.ID)
+		s.mu.Unlock()
+		if pending {
+			action()
+		}
+	}()
+	return t.PendingTimer, nil
+}
+
+// stopTimers cancels all pending timers. The caller must hold s.mu.
+func (s *Scope) stopTimers() {
+	for id, t := range s.timers {
+		close(t.stop)
+		delete(s.timers, id)
+	}
+}
+
+// autoExit exits this Scope on behalf of an automatic trigger such as a
+// timer, reporting errors via Scope.OfferErr unless the supplied ExitOpts
+// install their own error handler. Nothing may be receiving the errors of a
+// Scope that exits itself so they must not hold up its teardown.
+func (s *Scope) autoExit(opts []ExitOpt) {
+	opts = append([]ExitOpt{WithErrorHandler(s.OfferErr)}, opts...)
+	if err := s.Exit(context.Background(), opts...); err != nil {
+		s.OfferErr(err)
+	}
+}
@@ -0,0 +1,64 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestExitAfter(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := nls.NewScope(nls.WithClock(clock))
+	reaped := make(chan struct{})
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { close(reaped); return nil }, nil
+	})
+
+	late, err := s.ExitAfter(time.Hour)
+	require(t, err == nil, "unexpected error: %q", err)
+	early, err := s.ExitAfter(time.Minute)
+	require(t, err == nil, "unexpected error: %q", err)
+
+	pending := s.PendingTimers()
+	require(t, len(pending) == 2 && pending[0].ID == early.ID &&
+		pending[0].Kind == nls.TimerExitAfter, "unexpected timers %+v", pending)
+
+	require(t, early.Cancel(), "expected cancel of pending timer")
+	require(t, !early.Cancel(), "expected second cancel to report false")
+	require(t, len(s.PendingTimers()) == 1, "expected one pending timer")
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	<-reaped
+	require(t, !late.Cancel(), "expected fired timer not to be cancelable")
+}
+
+func TestExitAfterReportsErrors(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	errs := make(chan error, 1)
+	s := nls.NewScope(nls.WithClock(clock), nls.WithErrorChan(errs))
+	want := errors.New(t.Name())
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return want }, nil
+	})
+	_, err := s.ExitAfter(time.Second)
+	require(t, err == nil, "unexpected error: %q", err)
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	got := <-errs
+	require(t, errors.Is(got, want), "expected reaper error, got %q", got)
+}
+
+func TestExitCancelsTimers(t *testing.T) {
+	s := nls.NewScope()
+	_, err := s.ExitAfter(time.Hour)
+	require(t, err == nil, "unexpected error: %q", err)
+	s.Exit(context.TODO())
+	require(t, len(s.PendingTimers()) == 0, "expected timers canceled by Exit")
+	_, err = s.ExitAfter(time.Hour)
+	require(t, err != nil, "expected error scheduling on exited scope")
+}
@@ -0,0 +1,102 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Transaction collects several Spawners to be spawned into a Scope as a unit:
+// either every Spawner succeeds and all of their Reapers are registered with
+// the Scope together, or none are registered and any resources already
+// created are reaped before Commit returns. This avoids leaving a subsystem
+// half initialized when one of its parts fails to start. A Transaction is not
+// safe for concurrent use.
+type Transaction struct {
+	scope *Scope
+	steps []txStep
+}
+
+type txStep struct {
+	sp   Spawner
+	opts []SpawnOpt
+}
+
+// Begin starts a Transaction that spawns into this Scope.
+func (s *Scope) Begin() *Transaction {
+	return &Transaction{scope: s}
+}
+
+// Add queues the supplied Spawner and SpawnOpts, as would be passed to
+// Scope.Spawn, for invocation by Commit. It returns the Transaction so that
+// calls may be chained.
+func (t *Transaction) Add(sp Spawner, opts ...SpawnOpt) *Transaction {
+	t.steps = append(t.steps, txStep{sp: sp, opts: opts})
+	return t
+}
+
+// Commit invokes the queued Spawners in the order they were added and, if all
+// of them succeed, registers their Reapers with the Scope in the same order,
+// as though each had been passed to Scope.Spawn. Should a Spawner fail, or the
+// Scope stop accepting Spawns (e.g. because it has begun exiting or would
+// exceed WithReaperLimit), then the Reapers already obtained are invoked
+// immediately in reverse order with a background context and the error is
+// returned joined with any errors from that rollback. Commit empties the
+// Transaction so that it may be reused.
+func (t *Transaction) Commit(ctx context.Context) error {
+	s, steps := t.scope, t.steps
+	t.steps = nil
+	prepared := make([]reaper, 0, len(steps))
+	for _, step := range steps {
+		r, err := s.prepare(ctx, step.sp, step.opts)
+		if err != nil {
+			return errors.Join(err, rollback(prepared))
+		}
+		prepared = append(prepared, r)
+	}
+
+	s.mu.Lock()
+	if err := s.committable(prepared); err != nil {
+		s.mu.Unlock()
+		return s.breached(errors.Join(err, rollback(prepared)))
+	}
+	for _, r := range prepared {
+		s.adopt(r)
+		s.recordOwnership(OwnershipAcquire, nil, len(s.reapers))
+	}
+	s.mu.Unlock()
+	if len(s.observers) > 0 {
+		for _, r := range prepared {
+			s.observeSpawn(ctx, r)
+		}
+	}
+	return nil
+}
+
+// committable returns an error if this Scope cannot adopt all of the supplied
+// reapers. The caller must hold s.mu.
+func (s *Scope) committable(reapers []reaper) error {
+	if err := s.spawnableLocked(); err != nil {
+		return err
+	}
+	if s.reaperLimit > 0 && len(s.reapers)+len(reapers) > s.reaperLimit {
+		return s.misuse("Commit",
+			fmt.Errorf("%w: %d reapers", ErrScopeLimit, s.reaperLimit))
+	}
+	for _, r := range reapers {
+		if r.handle != nil && r.handle.Scope() != nil {
+			return errHandleInUse
+		}
+	}
+	return nil
+}
+
+// rollback invokes the supplied reapers in reverse order with a background
+// context, joining their errors.
+func rollback(reapers []reaper) error {
+	var errs []error
+	for i := len(reapers) - 1; i >= 0; i-- {
+		errs = append(errs, reapers[i].fn(context.Background()))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,63 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestTransactionCommit(t *testing.T) {
+	var log spawnLog
+	s := nls.NewScope()
+	var h nls.Handle
+	err := s.Begin().
+		Add(log.spawner("a", nil)).
+		Add(log.spawner("b", nil), nls.WithHandle(&h)).
+		Commit(context.TODO())
+	require(t, err == nil, "unexpected commit error %v", err)
+	require(t, h.Scope() == s, "expected handle to be bound to scope")
+	require(t, s.Snapshot().Reapers == 2, "expected two reapers")
+
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	want := []string{"spawn a", "spawn b", "reap b", "reap a"}
+	require(t, len(log.events) == len(want), "expected %v, got %v", want, log.events)
+	for i := range want {
+		require(t, log.events[i] == want[i], "expected %v, got %v", want, log.events)
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	var log spawnLog
+	s := nls.NewScope()
+	boom := errors.New("boom")
+	err := s.Begin().
+		Add(log.spawner("a", nil)).
+		Add(log.spawner("b", nil)).
+		Add(log.spawner("c", boom)).
+		Commit(context.TODO())
+	require(t, errors.Is(err, boom), "expected spawn error, got %v", err)
+	require(t, s.Snapshot().Reapers == 0, "expected nothing registered")
+	want := []string{"spawn a", "spawn b", "reap b", "reap a"}
+	require(t, len(log.events) == len(want), "expected %v, got %v", want, log.events)
+	for i := range want {
+		require(t, log.events[i] == want[i], "expected %v, got %v", want, log.events)
+	}
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, len(log.events) == len(want), "expected no further reaps, got %v", log.events)
+}
+
+func TestTransactionLimit(t *testing.T) {
+	var log spawnLog
+	s := nls.NewScope(nls.WithReaperLimit(2))
+	nls.MustSpawn(context.TODO(), s, log.spawner("a", nil))
+	err := s.Begin().
+		Add(log.spawner("b", nil)).
+		Add(log.spawner("c", nil)).
+		Commit(context.TODO())
+	require(t, errors.Is(err, nls.ErrScopeLimit), "expected limit error, got %v", err)
+	require(t, log.count("reap b") == 1 && log.count("reap c") == 1,
+		"expected spawned resources to be rolled back, got %v", log.events)
+	require(t, s.Snapshot().Reapers == 1, "expected only the original reaper")
+}
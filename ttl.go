@@ -0,0 +1,93 @@
+package nls
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errNoIdle  = errors.New("scope has no idle ttl")
+	errExpired = errors.New("scope idle ttl has expired")
+)
+
+// WithTTL yields a ScopeOpt that causes the new Scope to exit itself once the
+// supplied duration, as measured by its Clock, has elapsed since it was
+// created. Errors from the automatic Exit are reported via Scope.OfferErr, as
+// for Scope.ExitAfter. It replaces any deadline supplied via WithDeadline.
+func WithTTL(d time.Duration) ScopeOpt {
+	return func(s *Scope) {
+		s.ttl = d
+		s.deadline = time.Time{}
+	}
+}
+
+// WithDeadline yields a ScopeOpt that causes the new Scope to exit itself at
+// the supplied time, as measured by its Clock. Errors from the automatic Exit
+// are reported via Scope.OfferErr, as for Scope.ExitAfter. It replaces any
+// duration supplied via WithTTL.
+func WithDeadline(t time.Time) ScopeOpt {
+	return func(s *Scope) {
+		s.deadline = t
+		s.ttl = 0
+	}
+}
+
+// WithIdleTTL yields a ScopeOpt that causes the new Scope to exit itself once
+// the supplied duration, as measured by its Clock, has elapsed without a call
+// to Scope.Touch, e.g. to reap session or connection Scopes that have fallen
+// idle. Errors from the automatic Exit are reported via Scope.OfferErr, as
+// for Scope.ExitAfter. It may be combined with WithTTL or WithDeadline, in
+// which case the Scope exits at whichever comes first.
+func WithIdleTTL(d time.Duration) ScopeOpt {
+	return func(s *Scope) {
+		s.idle = d
+	}
+}
+
+// Touch marks this Scope as in use, restarting the idle TTL set with
+// WithIdleTTL. An error is returned if the Scope was not created with
+// WithIdleTTL or if it has already timed out.
+func (s *Scope) Touch() error {
+	if s.idle <= 0 {
+		return s.annotate(errNoIdle)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.timers[s.idleTimer]
+	if !ok {
+		return s.annotate(errExpired)
+	}
+	delete(s.timers, t.ID)
+	close(t.stop)
+	return s.idleLocked()
+}
+
+// expire schedules the automatic Exits configured via WithTTL, WithDeadline
+// and WithIdleTTL, if any.
+func (s *Scope) expire() {
+	if s.ttl <= 0 && s.deadline.IsZero() && s.idle <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ttl > 0 || !s.deadline.IsZero() {
+		d := s.ttl
+		if !s.deadline.IsZero() {
+			d = s.deadline.Sub(s.clock.Now())
+		}
+		s.scheduleLocked(TimerTTL, d, func() { s.autoExit(nil) })
+	}
+	if s.idle > 0 {
+		s.idleLocked()
+	}
+}
+
+// idleLocked schedules the idle TTL. The caller must hold s.mu.
+func (s *Scope) idleLocked() error {
+	t, err := s.scheduleLocked(TimerIdle, s.idle, func() { s.autoExit(nil) })
+	if err != nil {
+		return err
+	}
+	s.idleTimer = t.ID
+	return nil
+}
@@ -0,0 +1,88 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestTTL(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	root := nls.NewScope(nls.WithClock(clock))
+	defer root.Exit(context.TODO())
+	session := root.NewChildScope(nls.WithTTL(time.Minute))
+	reaped := make(chan struct{})
+	nls.MustSpawn(context.TODO(), session, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { close(reaped); return nil }, nil
+	})
+	pending := session.PendingTimers()
+	require(t, len(pending) == 1 && pending[0].Kind == nls.TimerTTL,
+		"expected a pending ttl timer, got %+v", pending)
+	require(t, session.Touch() != nil, "expected touch without idle ttl to fail")
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-reaped
+}
+
+func TestDeadline(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	errs := make(chan error, 1)
+	s := nls.NewScope(nls.WithClock(clock), nls.WithErrorChan(errs),
+		nls.WithDeadline(clock.Now().Add(time.Hour)))
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { return errors.New("boom") }, nil
+	})
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	err := <-errs
+	require(t, err.Error() == "boom", "expected reaper error to be reported, got %v", err)
+}
+
+func TestIdleTTL(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	root := nls.NewScope(nls.WithClock(clock))
+	defer root.Exit(context.TODO())
+	conn := root.NewChildScope(nls.WithIdleTTL(time.Minute))
+	reaped := make(chan struct{})
+	nls.MustSpawn(context.TODO(), conn, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error { close(reaped); return nil }, nil
+	})
+
+	clock.BlockUntil(1)
+	clock.Advance(45 * time.Second)
+	require(t, conn.Touch() == nil, "unexpected touch error")
+	clock.BlockUntil(1)
+	clock.Advance(45 * time.Second)
+	select {
+	case <-reaped:
+		t.Fatal("expected touch to restart the idle ttl")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(15 * time.Second)
+	<-reaped
+	require(t, conn.Touch() != nil, "expected touch after timeout to fail")
+}
+
+func TestTTLUnreadErrors(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := nls.NewScope(nls.WithClock(clock), nls.WithTTL(time.Minute))
+	for i := 0; i < 2; i++ {
+		nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+			return func(context.Context) error { return errors.New("boom") }, nil
+		})
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case <-s.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected unread errors not to hold up the automatic exit")
+	}
+}
@@ -0,0 +1,70 @@
+package nls
+
+import "context"
+
+// WithSpawnValues yields a SpawnOpt that makes values of the context supplied
+// to Spawn visible to the spawned object's Reaper, e.g. so that the logs and
+// traces of its teardown correlate with the operation that created it. If keys
+// are supplied, the values of only those keys are captured when Spawn is
+// called and they take precedence over any values for the same keys in the
+// context passed to the Reaper. Otherwise the whole value chain of the Spawn
+// context is retained and consulted for any key that the Reaper's context
+// does not hold. Only values are inherited: the Reaper's context is never
+// canceled by the Spawn context.
+func WithSpawnValues(keys ...interface{}) SpawnOpt {
+	return func(r *reaper) {
+		r.values = &spawnValues{keys: keys}
+	}
+}
+
+// spawnValues holds the values inherited by a reaper from its Spawn context.
+type spawnValues struct {
+	keys []interface{}
+	vals []interface{}
+	ctx  context.Context // set when no keys were selected
+}
+
+// capture returns a copy of these spawnValues holding the values of the
+// supplied context.
+func (v *spawnValues) capture(ctx context.Context) *spawnValues {
+	c := &spawnValues{keys: v.keys}
+	if len(v.keys) == 0 {
+		c.ctx = ctx
+		return c
+	}
+	c.vals = make([]interface{}, len(v.keys))
+	for i, k := range v.keys {
+		c.vals[i] = ctx.Value(k)
+	}
+	return c
+}
+
+// bind returns a Reaper that invokes the supplied Reaper with a context that
+// also holds these values. A nil spawnValues returns the Reaper unchanged.
+func (v *spawnValues) bind(fn Reaper) Reaper {
+	if v == nil {
+		return fn
+	}
+	return func(ctx context.Context) error {
+		return fn(valuesCtx{ctx, v})
+	}
+}
+
+// valuesCtx is a context that also holds the values inherited from a Spawn
+// context.
+type valuesCtx struct {
+	context.Context
+	v *spawnValues
+}
+
+func (c valuesCtx) Value(key interface{}) interface{} {
+	for i, k := range c.v.keys {
+		if k == key {
+			return c.v.vals[i]
+		}
+	}
+	if val := c.Context.Value(key); val != nil || c.v.ctx == nil {
+		return val
+	}
+	return c.v.ctx.Value(key)
+}
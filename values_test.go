@@ -0,0 +1,64 @@
+package nls_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mmcshane/nls"
+)
+
+type traceKey struct{}
+
+type userKey struct{}
+
+func TestSpawnValues(t *testing.T) {
+	s := nls.NewScope()
+	spawnCtx := context.WithValue(context.Background(), traceKey{}, "spawn-trace")
+	spawnCtx = context.WithValue(spawnCtx, userKey{}, "alice")
+
+	var selected, whole, plain []interface{}
+	record := func(dst *[]interface{}) nls.Spawner {
+		return func(context.Context) (nls.Reaper, error) {
+			return func(ctx context.Context) error {
+				*dst = append(*dst, ctx.Value(traceKey{}), ctx.Value(userKey{}),
+					nls.ReasonFromContext(ctx))
+				return nil
+			}, nil
+		}
+	}
+	nls.MustSpawn(spawnCtx, s, record(&selected), nls.WithSpawnValues(traceKey{}))
+	nls.MustSpawn(spawnCtx, s, record(&whole), nls.WithSpawnValues())
+	nls.MustSpawn(spawnCtx, s, record(&plain))
+
+	exitCtx := context.WithValue(context.Background(), traceKey{}, "exit-trace")
+	err := s.Exit(exitCtx, nls.WithReason(nls.ErrCanceled))
+	require(t, err == nil, "unexpected exit error %v", err)
+
+	require(t, selected[0] == "spawn-trace" && selected[1] == nil,
+		"expected only the selected spawn value, got %v", selected)
+	require(t, whole[0] == "exit-trace" && whole[1] == "alice",
+		"expected exit values to shadow the spawn chain, got %v", whole)
+	require(t, plain[0] == "exit-trace" && plain[1] == nil,
+		"expected no spawn values without the option, got %v", plain)
+	for _, vals := range [][]interface{}{selected, whole, plain} {
+		require(t, vals[2] == nls.ErrCanceled, "expected exit reason to be visible, got %v", vals)
+	}
+}
+
+func TestSpawnValuesNotCanceled(t *testing.T) {
+	s := nls.NewScope()
+	spawnCtx, cancel := context.WithCancel(context.WithValue(context.Background(), traceKey{}, 1))
+	var errAtExit error
+	var trace interface{}
+	nls.MustSpawn(spawnCtx, s, func(context.Context) (nls.Reaper, error) {
+		return func(ctx context.Context) error {
+			errAtExit, trace = ctx.Err(), ctx.Value(traceKey{})
+			return nil
+		}, nil
+	}, nls.WithSpawnValues())
+	cancel()
+	err := s.Exit(context.TODO())
+	require(t, err == nil, "unexpected exit error %v", err)
+	require(t, errAtExit == nil && trace == 1,
+		"expected spawn values without spawn cancelation, got %v %v", errAtExit, trace)
+}
@@ -0,0 +1,320 @@
+package nls
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// tracker counts units of in-flight work (e.g. managed goroutines or acquired
+// requests) for a Scope and, transitively, for all of its ancestors.
+type tracker struct {
+	mu     sync.Mutex
+	parent *tracker
+	n      int
+	idle   chan struct{}
+}
+
+// goroutineLog records the goroutines launched by the Scopes that share it
+// (see WithGoroutineTracking), each once, against the Scope that launched it.
+type goroutineLog struct {
+	mu   sync.Mutex
+	live map[*goroutine]struct{}
+}
+
+// goroutine records a goroutine launched by a Scope for leak reporting.
+type goroutine struct {
+	id      atomic.Uint64
+	scope   *Scope
+	created []uintptr
+}
+
+func (t *tracker) add(delta int) {
+	for t != nil {
+		t.mu.Lock()
+		t.addLocked(delta)
+		next := t.parent
+		t.mu.Unlock()
+		t = next
+	}
+}
+
+func (t *tracker) addLocked(delta int) {
+	if delta == 0 {
+		return
+	}
+	if t.n == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.n += delta
+	if t.n == 0 {
+		close(t.idle)
+	}
+}
+
+// move reattaches this tracker beneath the supplied parent, transferring its
+// outstanding count from its former ancestors to its new ones.
+func (t *tracker) move(parent *tracker) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	parent.add(t.n)
+	t.parent.add(-t.n)
+	t.parent = parent
+}
+
+func (l *goroutineLog) track(g *goroutine, live bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if live {
+		if l.live == nil {
+			l.live = make(map[*goroutine]struct{})
+		}
+		l.live[g] = struct{}{}
+	} else {
+		delete(l.live, g)
+	}
+}
+
+// WithGoroutineTracking yields a ScopeOpt that causes the goroutines launched
+// by the new Scope and its descendants, via Scope.Go or by a Pool, to be
+// recorded so that those still running can be reported by
+// Scope.LiveGoroutines. Recording captures the launching call's stack, so it
+// is off by default; Scopes created by package nlstest enable it.
+func WithGoroutineTracking() ScopeOpt {
+	return func(s *Scope) {
+		s.goroutines = &goroutineLog{}
+	}
+}
+
+// launch runs fn on a new goroutine that is counted by this Scope's tracker
+// until fn returns and, if goroutine tracking is enabled, is reported by
+// Scope.LiveGoroutines in the meantime. If this Scope applies profiler labels
+// (see WithProfileLabels) the goroutine carries them.
+func (s *Scope) launch(fn func()) {
+	s.launchIn(s.labeled(), fn)
+}
+
+// launchIn is launch for a caller that has already computed labels, the
+// context returned by labeled.
+func (s *Scope) launchIn(labels context.Context, fn func()) {
+	var g *goroutine
+	if s.goroutines != nil {
+		g = &goroutine{scope: s, created: make([]uintptr, 32)}
+		g.created = g.created[:runtime.Callers(3, g.created)]
+		s.goroutines.track(g, true)
+	}
+	s.tracker.add(1)
+	go func() {
+		defer s.tracker.add(-1)
+		if g != nil {
+			defer s.goroutines.track(g, false)
+			g.id.Store(goid())
+		}
+		if s.profiled {
+			pprof.SetGoroutineLabels(labels)
+		}
+		fn()
+	}()
+}
+
+// WithProfileLabels yields a ScopeOpt that causes the goroutines launched by
+// the new Scope and its descendants, via Scope.Go or by a Pool, to carry
+// their Scope's ProfileLabels. Labels are off by default as computing them
+// costs an allocation of the Scope's path per goroutine.
+func WithProfileLabels() ScopeOpt {
+	return func(s *Scope) {
+		s.profiled = true
+	}
+}
+
+// labeled returns a context carrying this Scope's ProfileLabels if it applies
+// them (see WithProfileLabels), or else context.Background().
+func (s *Scope) labeled() context.Context {
+	if !s.profiled {
+		return context.Background()
+	}
+	return pprof.WithLabels(context.Background(), s.ProfileLabels())
+}
+
+// Profiler label keys set on goroutines launched by a Scope.
+const (
+	ProfileLabelScope = "nls.scope"
+	ProfileLabelPath  = "nls.path"
+)
+
+// ProfileLabels returns the runtime/pprof labels that identify this Scope,
+// its name under ProfileLabelScope and its path under ProfileLabelPath, so
+// that CPU and goroutine profiles can group work by Scope. If the Scope was
+// created with WithProfileLabels they are set on every goroutine launched via
+// Scope.Go or by a Pool, and carried by the context passed to the function
+// run by Scope.Go so that goroutines it starts with pprof.Do inherit them. Use
+// pprof.Do with these labels to attribute other work to this Scope.
+func (s *Scope) ProfileLabels() pprof.LabelSet {
+	return pprof.Labels(ProfileLabelScope, s.Name(), ProfileLabelPath, s.Path())
+}
+
+// goid returns the ID of the calling goroutine, as it appears in stack
+// traces.
+func goid() uint64 {
+	var buf [64]byte
+	f := bytes.Fields(buf[:runtime.Stack(buf[:], false)])
+	if len(f) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(string(f[1]), 10, 64)
+	return id
+}
+
+// LiveGoroutine describes a goroutine launched via Scope.Go (or by a Pool)
+// that has not yet returned.
+type LiveGoroutine struct {
+	// ID is the goroutine's ID as it appears in stack traces, or zero if
+	// the goroutine has not yet started running.
+	ID uint64
+
+	// Scope is the path of the Scope that launched the goroutine.
+	Scope string
+
+	// Created is the stack trace of the call that launched the goroutine.
+	Created string
+}
+
+// LiveGoroutines returns the goroutines launched via Scope.Go, or by a Pool,
+// on this Scope or any of its descendants that have not yet returned. It is
+// typically used after Scope.Exit to report leaks; see also Scope.Wait. It
+// returns nothing unless goroutine tracking is enabled for this Scope (see
+// WithGoroutineTracking).
+func (s *Scope) LiveGoroutines() []LiveGoroutine {
+	if s.goroutines == nil {
+		return nil
+	}
+	s.goroutines.mu.Lock()
+	live := make([]*goroutine, 0, len(s.goroutines.live))
+	for g := range s.goroutines.live {
+		live = append(live, g)
+	}
+	s.goroutines.mu.Unlock()
+	gs := make([]LiveGoroutine, 0, len(live))
+	for _, g := range live {
+		if !g.scope.within(s) {
+			continue
+		}
+		gs = append(gs, LiveGoroutine{
+			ID:      g.id.Load(),
+			Scope:   g.scope.Path(),
+			Created: trace(g.created),
+		})
+	}
+	sort.Slice(gs, func(i, j int) bool { return gs[i].ID < gs[j].ID })
+	return gs
+}
+
+func trace(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			return b.String()
+		}
+	}
+}
+
+func (t *tracker) busy() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n > 0
+}
+
+func (t *tracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	if t.n == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	idle := t.idle
+	t.mu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Go launches fn on a new goroutine managed by this Scope. The context passed
+// to fn is canceled when the Scope exits and Scope.Exit then waits, subject to
+// its own context, for fn to return. A goroutine still running when Exit gives
+// up remains tracked and can be awaited with Scope.Wait. If the Scope applies
+// profiler labels (see WithProfileLabels) the goroutine, and the context
+// passed to fn, carry them. An error is returned if this Scope has already
+// exited.
+func (s *Scope) Go(fn func(context.Context)) error {
+	return s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		return goReaper(s.labeled(), s.launchIn, fn), nil
+	})
+}
+
+// goReaper launches fn with a context derived from ctx, returning a Reaper
+// that cancels that context and waits for fn to return. The derived context
+// is also passed to launch.
+func goReaper(ctx context.Context, launch func(context.Context, func()), fn func(context.Context)) Reaper {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	launch(ctx, func() {
+		defer close(done)
+		fn(ctx)
+	})
+	return func(ctx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Wait blocks until every goroutine launched via Scope.Go on this Scope or any
+// of its descendants has returned, or until the supplied context is done, in
+// which case the context's error is returned. Wait is typically called after
+// Scope.Exit has returned to ensure that no managed work is still in flight,
+// e.g. before returning from main.
+func (s *Scope) Wait(ctx context.Context) error {
+	return s.tracker.wait(ctx)
+}
+
+// WaitFor registers the supplied WaitGroup with the supplied Scope so that,
+// when the Scope exits, its Reaper waits for the WaitGroup's counter to reach
+// zero, bounded by the Exit's context. This lets goroutines managed with a
+// WaitGroup by existing code take their place in the Scope's teardown order,
+// e.g. to stop accepting work (reaped earlier, having been spawned later)
+// before waiting for the workers. The Reaper returns the context's error if
+// the context is done first, leaving a goroutine blocked in wg.Wait until the
+// counter reaches zero. An error is returned if the Scope has already exited.
+func WaitFor(s Lifetime, wg *sync.WaitGroup) error {
+	return s.Spawn(context.Background(), func(context.Context) (Reaper, error) {
+		return func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}, nil
+	})
+}
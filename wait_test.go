@@ -0,0 +1,149 @@
+package nls_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+)
+
+func TestGoCanceledOnExit(t *testing.T) {
+	s := nls.NewScope()
+	stopped := make(chan struct{})
+	err := s.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+
+	err = s.Exit(context.TODO())
+	require(t, err == nil, "unexpected error from Scope.Exit: %q", err)
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("expected Scope.Exit to have waited for goroutine")
+	}
+	require(t, s.Wait(context.TODO()) == nil, "expected no in-flight work")
+}
+
+func TestWaitAfterAbandonedExit(t *testing.T) {
+	root := nls.NewScope()
+	child := root.NewChildScope()
+	release := make(chan struct{})
+	finished := make(chan struct{})
+	err := child.Go(func(context.Context) {
+		<-release
+		close(finished)
+	})
+	require(t, err == nil, "unexpected error: %q", err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = root.Exit(ctx)
+	require(t, err == context.DeadlineExceeded, "expected deadline, got %q", err)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err = root.Wait(ctx)
+	require(t, err == context.DeadlineExceeded,
+		"expected Wait to block on abandoned goroutine, got %q", err)
+
+	close(release)
+	err = root.Wait(context.TODO())
+	require(t, err == nil, "unexpected error from Scope.Wait: %q", err)
+	<-finished
+
+	err = root.Go(func(context.Context) {})
+	require(t, err != nil, "expected error launching goroutine on done scope")
+}
+
+func TestLiveGoroutines(t *testing.T) {
+	root := nls.NewScope(nls.WithName("root"), nls.WithGoroutineTracking())
+	a := root.NewChildScope(nls.WithName("a"))
+	b := root.NewChildScope(nls.WithName("b"))
+	release := make(chan struct{})
+	err := a.Go(func(context.Context) { <-release })
+	require(t, err == nil, "unexpected error %v", err)
+
+	live := root.LiveGoroutines()
+	require(t, len(live) == 1 && live[0].Scope == "root/a" &&
+		strings.Contains(live[0].Created, "TestLiveGoroutines"),
+		"unexpected live goroutines %+v", live)
+	require(t, a.Reparent(b) == nil, "unexpected reparent error")
+	require(t, len(b.LiveGoroutines()) == 1, "expected goroutine to move with its scope")
+
+	close(release)
+	require(t, root.Wait(context.TODO()) == nil, "unexpected wait error")
+	require(t, len(root.LiveGoroutines()) == 0, "expected no live goroutines")
+	root.Exit(context.TODO())
+
+	untracked := nls.NewScope()
+	defer untracked.Exit(context.TODO())
+	untracked.Go(func(ctx context.Context) { <-ctx.Done() })
+	require(t, untracked.LiveGoroutines() == nil, "expected goroutines to be untracked by default")
+}
+
+func TestGoProfileLabels(t *testing.T) {
+	root := nls.NewScope(nls.WithName("app"), nls.WithProfileLabels())
+	defer root.Exit(context.TODO())
+	s := root.NewChildScope(nls.WithName("worker"))
+	labeled := make(chan string, 1)
+	release := make(chan struct{})
+	err := s.Go(func(ctx context.Context) {
+		path, _ := pprof.Label(ctx, nls.ProfileLabelPath)
+		labeled <- path
+		<-release
+	})
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, <-labeled == s.Path(), "expected scope path label on context")
+
+	var buf bytes.Buffer
+	err = pprof.Lookup("goroutine").WriteTo(&buf, 1)
+	require(t, err == nil, "unexpected error: %v", err)
+	want := `"nls.path":"` + s.Path() + `"`
+	require(t, strings.Contains(buf.String(), want),
+		"expected goroutine profile to contain %s", want)
+	close(release)
+
+	plain := nls.NewScope(nls.WithName("plain"))
+	defer plain.Exit(context.TODO())
+	err = plain.Go(func(ctx context.Context) {
+		_, ok := pprof.Label(ctx, nls.ProfileLabelPath)
+		labeled <- fmt.Sprint(ok)
+	})
+	require(t, err == nil, "unexpected error: %v", err)
+	require(t, <-labeled == "false", "expected no labels by default")
+}
+
+func TestWaitFor(t *testing.T) {
+	s := nls.NewScope()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var finished atomic.Bool
+	release := make(chan struct{})
+	go func() {
+		defer wg.Done()
+		<-release
+		finished.Store(true)
+	}()
+	require(t, nls.WaitFor(s, &wg) == nil, "unexpected spawn error")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	child := s.NewChildScope()
+	require(t, nls.WaitFor(child, &wg) == nil, "unexpected spawn error")
+	require(t, errors.Is(child.Exit(ctx), context.Canceled), "expected exit bounded by context")
+
+	close(release)
+	require(t, s.Exit(context.TODO()) == nil, "unexpected exit error")
+	require(t, finished.Load(), "expected exit to wait for the WaitGroup")
+	require(t, nls.WaitFor(s, &wg) != nil, "expected error from exited scope")
+}
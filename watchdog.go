@@ -0,0 +1,116 @@
+package nls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrReaperTimeout is matched (via errors.Is) by errors reported for Reapers
+// left running by WithReaperTimeout.
+var ErrReaperTimeout = errors.New("reaper timed out")
+
+// TimeoutError is the error reported for a Reaper that was given up on and
+// left running, whether by WithReaperTimeout, Detached or WithTimeoutReaper.
+// It matches ErrReaperTimeout via errors.Is.
+type TimeoutError struct {
+	Timeout time.Duration // the limit that was exceeded, if any
+	Err     error         // the context error when abandoned by Detached, else nil
+}
+
+func (e *TimeoutError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v: left running", ErrReaperTimeout, e.Err)
+	}
+	return fmt.Sprintf("%s after %v: left running", ErrReaperTimeout, e.Timeout)
+}
+
+func (e *TimeoutError) Is(target error) bool { return target == ErrReaperTimeout }
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// timedOut reports whether the supplied error is or wraps a *TimeoutError.
+func timedOut(err error) bool {
+	var te *TimeoutError
+	return errors.As(err, &te)
+}
+
+// WithReaperTimeout yields an ExitOpt that stops the Exit waiting for any
+// single Reaper that runs for longer than d, as measured by the Scope's
+// Clock, so that the rest of the teardown can proceed. Such a Reaper is left
+// running on a detached goroutine, which is reported as leaked by
+// Scope.LiveGoroutines and awaited by Scope.Wait, and is abandoned with an
+// error matching both ErrAbandoned and ErrReaperTimeout regardless of any
+// AbandonPolicy, since it cannot safely be retried. The ExitReport counts
+// such Reapers as Leaked. Each Reaper runs on its own goroutine while the
+// option is in effect.
+func WithReaperTimeout(d time.Duration) ExitOpt {
+	return func(cfg *exitCfg) {
+		cfg.watchdog = d
+	}
+}
+
+// invoke runs the supplied reaper of Scope s, subject to ec.watchdog,
+// returning a *TimeoutError if it was left running and wrapping any error
+// it returned in a *ReapError.
+func (ec *exitCfg) invoke(ctx context.Context, s *Scope, r reaper) error {
+	fn := ec.reaperFor(r)
+	if ec.watchdog <= 0 {
+		return s.reapError(r, fn(ctx))
+	}
+	done := make(chan error, 1)
+	s.launch(func() {
+		done <- fn(ctx)
+	})
+	t := s.clock.NewTimer(ec.watchdog)
+	defer t.Stop()
+	select {
+	case err := <-done:
+		return s.reapError(r, err)
+	case <-t.C():
+		return s.reapError(r, &TimeoutError{Timeout: ec.watchdog})
+	}
+}
+
+// Detached wraps a Reaper whose cleanup may ignore its context, e.g. a
+// third-party Close or Stop method that can block forever, so that it runs on
+// its own goroutine and the returned Reaper returns as soon as its context is
+// done even if the cleanup has not. The cleanup is then left running and a
+// *TimeoutError matching both ErrReaperTimeout and the context's error is
+// returned; when invoked by a Scope the Reaper is reported as abandoned and
+// counted as Leaked in the ExitReport, as for WithReaperTimeout.
+func Detached(r Reaper) Reaper {
+	return func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() {
+			done <- r(ctx)
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return &TimeoutError{Err: ctx.Err()}
+		}
+	}
+}
+
+// WithTimeoutReaper wraps the supplied Reaper as per Detached and further
+// bounds it to d, as measured by the Clock of the Scope invoking it, giving
+// up on the cleanup once d has elapsed. Unlike the ExitOpt WithReaperTimeout
+// this applies to a single Reaper wherever it is registered.
+func WithTimeoutReaper(r Reaper, d time.Duration) Reaper {
+	return func(ctx context.Context) error {
+		clock := Clock(RealClock{})
+		if ec, _ := ctx.Value(teardownKey{}).(*exitCfg); ec != nil {
+			clock = ec.clock
+		}
+		tctx, cancel := ClockTimeout(ctx, clock, d)
+		defer cancel()
+		err := Detached(r)(tctx)
+		if err != nil && timedOut(err) && ctx.Err() == nil {
+			return &TimeoutError{Timeout: d}
+		}
+		return err
+	}
+}
@@ -0,0 +1,93 @@
+package nls_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mmcshane/nls"
+	"github.com/mmcshane/nls/nlssim"
+)
+
+func TestReaperTimeout(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	root := nls.NewScope(nls.WithClock(clock), nls.WithExitedChildHistory(1),
+		nls.WithGoroutineTracking())
+	defer root.Exit(context.TODO())
+	s := root.NewChildScope(nls.WithName("svc"))
+	var reaped bool
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			reaped = true
+			return nil
+		}, nil
+	})
+	release := make(chan struct{})
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return func(context.Context) error {
+			<-release
+			return nil
+		}, nil
+	})
+
+	var errs []error
+	done := s.ExitAsync(context.TODO(), nls.WithReaperTimeout(time.Second),
+		nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	require(t, <-done == nil, "unexpected exit error")
+	require(t, reaped, "expected teardown to proceed past the stuck reaper")
+	require(t, len(errs) == 1 && errors.Is(errs[0], nls.ErrReaperTimeout) &&
+		errors.Is(errs[0], nls.ErrAbandoned), "unexpected errors %v", errs)
+	report := root.ExitedChildren()[0].Report
+	require(t, report.Abandoned == 1 && report.Leaked == 1, "unexpected report %+v", report)
+	require(t, len(s.LiveGoroutines()) == 1, "expected the stuck reaper to be reported as leaked")
+
+	close(release)
+	require(t, s.Wait(context.TODO()) == nil, "unexpected wait error")
+}
+
+func TestDetached(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	fn := nls.Detached(func(context.Context) error {
+		<-release
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := fn(ctx)
+	require(t, errors.Is(err, nls.ErrReaperTimeout) && errors.Is(err, context.Canceled),
+		"expected leak error carrying the context error, got %v", err)
+
+	boom := errors.New("boom")
+	err = nls.Detached(func(context.Context) error { return boom })(context.TODO())
+	require(t, errors.Is(err, boom), "expected reaper error, got %v", err)
+}
+
+func TestWithTimeoutReaper(t *testing.T) {
+	clock := nlssim.New(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	root := nls.NewScope(nls.WithClock(clock), nls.WithExitedChildHistory(1))
+	defer root.Exit(context.TODO())
+	s := root.NewChildScope(nls.WithName("svc"))
+	release := make(chan struct{})
+	defer close(release)
+	nls.MustSpawn(context.TODO(), s, func(context.Context) (nls.Reaper, error) {
+		return nls.WithTimeoutReaper(nls.ReaperFromFunc(func() error {
+			<-release
+			return nil
+		}), time.Second), nil
+	})
+
+	var errs []error
+	done := s.ExitAsync(context.TODO(),
+		nls.WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	require(t, <-done == nil, "unexpected exit error")
+	require(t, len(errs) == 1 && errors.Is(errs[0], nls.ErrReaperTimeout) &&
+		errors.Is(errs[0], nls.ErrAbandoned), "unexpected errors %v", errs)
+	report := root.ExitedChildren()[0].Report
+	require(t, report.Abandoned == 1 && report.Leaked == 1, "unexpected report %+v", report)
+}